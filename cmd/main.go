@@ -2,15 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/label-platform-backend/internal/application/usecase"
+	"github.com/label-platform-backend/internal/application/watchdog"
 	"github.com/label-platform-backend/internal/domain/entity"
 	"github.com/label-platform-backend/internal/infrastructure/database"
 	"github.com/label-platform-backend/internal/infrastructure/redis"
@@ -20,6 +24,12 @@ import (
 	"github.com/label-platform-backend/internal/interfaces/http/router"
 )
 
+// batchDispatchInterval is how often running prediction batches are topped up
+const batchDispatchInterval = 2 * time.Second
+
+// watchdogInterval is how often active prediction jobs are checked for deadlines
+const watchdogInterval = 15 * time.Second
+
 func main() {
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
@@ -39,7 +49,7 @@ func main() {
 	}
 
 	// Auto migrate database schema
-	if err := db.AutoMigrate(&entity.Image{}); err != nil {
+	if err := db.AutoMigrate(&entity.Project{}, &entity.Image{}, &entity.Model{}, &entity.PromptTemplate{}, &entity.PredictionCacheEntry{}, &entity.EvaluationRun{}, &entity.RunResult{}, &entity.PredictionBatch{}, &entity.AnnotationTask{}, &entity.TaskEvent{}, &entity.AnnotationSession{}); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
@@ -51,15 +61,45 @@ func main() {
 
 	// Initialize repositories
 	imageRepo := repository.NewPostgresImageRepository(db)
+	projectRepo := repository.NewPostgresProjectRepository(db)
+	modelRepo := repository.NewPostgresModelRepository(db)
+	promptRepo := repository.NewPostgresPromptRepository(db)
+	cacheRepo := repository.NewPostgresPredictionCacheRepository(db)
+	cacheStatsRepo := repository.NewRedisPredictionCacheStatsRepository()
+	runRepo := repository.NewPostgresEvaluationRunRepository(db)
+	batchRepo := repository.NewPostgresPredictionBatchRepository(db)
+	taskRepo := repository.NewPostgresAnnotationTaskRepository(db)
 
 	// Initialize use cases
-	imageUseCase := usecase.NewImageUseCase(imageRepo, minioClient)
+	cacheRenders, err := renderCache()
+	if err != nil {
+		log.Fatalf("Invalid render configuration: %v", err)
+	}
+	imageUseCase := usecase.NewImageUseCase(imageRepo, projectRepo, cacheRepo, runRepo, taskRepo, minioClient, cacheRenders)
+	projectUseCase := usecase.NewProjectUseCase(projectRepo, imageRepo)
+	modelUseCase := usecase.NewModelUseCase(modelRepo)
+	promptUseCase := usecase.NewPromptUseCase(promptRepo)
+	predictionUseCase := usecase.NewPredictionUseCase(imageRepo, modelRepo, cacheRepo, cacheStatsRepo, runRepo, imageUseCase, promptUseCase, minioClient)
+	runUseCase := usecase.NewRunUseCase(runRepo, imageRepo, projectRepo, modelRepo, promptUseCase, predictionUseCase)
+	batchUseCase := usecase.NewBatchUseCase(batchRepo, runRepo, projectRepo, modelRepo, runUseCase, predictionUseCase)
+	taskUseCase := usecase.NewTaskUseCase(taskRepo, imageRepo, projectRepo, imageUseCase)
 
 	// Initialize handlers
-	imageHandler := handler.NewImageHandler(imageUseCase)
+	imageHandler := handler.NewImageHandler(imageUseCase, predictionUseCase, taskUseCase)
+	projectHandler := handler.NewProjectHandler(projectUseCase)
+	modelHandler := handler.NewModelHandler(modelUseCase)
+	promptHandler := handler.NewPromptHandler(promptUseCase)
+	runHandler := handler.NewRunHandler(runUseCase)
+	batchHandler := handler.NewBatchHandler(batchUseCase)
+	taskHandler := handler.NewTaskHandler(taskUseCase)
 
 	// Setup router
-	router := router.SetupRouter(imageHandler)
+	// Admin routes are disabled without a token
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if adminToken == "" {
+		log.Println("ADMIN_TOKEN is not set, admin routes are disabled")
+	}
+	router := router.SetupRouter(imageHandler, projectHandler, modelHandler, promptHandler, runHandler, batchHandler, taskHandler, adminToken)
 
 	// Get port from environment
 	port := os.Getenv("PORT")
@@ -73,6 +113,24 @@ func main() {
 		Handler: router,
 	}
 
+	// Dispatch prediction batches in the background
+	dispatchCtx, stopDispatch := context.WithCancel(ctx)
+	defer stopDispatch()
+	go batchUseCase.RunDispatcher(dispatchCtx, batchDispatchInterval)
+
+	// Time out stuck prediction jobs in the background
+	policy, err := watchdogPolicy()
+	if err != nil {
+		log.Fatalf("Invalid watchdog configuration: %v", err)
+	}
+	watchdogCtx, stopWatchdog := context.WithCancel(ctx)
+	defer stopWatchdog()
+	watchdogDone := make(chan struct{})
+	go func() {
+		watchdog.New(predictionUseCase, policy, watchdogInterval).Run(watchdogCtx)
+		close(watchdogDone)
+	}()
+
 	// Start server in a goroutine
 	go func() {
 		log.Printf("Server starting on port %s", port)
@@ -86,6 +144,9 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	stopDispatch()
+	stopWatchdog()
+	<-watchdogDone
 
 	// Give outstanding requests a deadline for completion
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -97,3 +158,44 @@ func main() {
 
 	log.Println("Server exited")
 }
+
+// watchdogPolicy reads the job deadlines and retries from the environment:
+// PREDICT_JOB_TIMEOUT, PREDICT_JOB_TIMEOUTS (e.g. "gpt=3m,claude=90s"),
+// PREDICT_QUEUE_TIMEOUT and PREDICT_JOB_RETRIES
+func watchdogPolicy() (watchdog.Policy, error) {
+	policy := watchdog.DefaultPolicy()
+	var err error
+	if v := os.Getenv("PREDICT_JOB_TIMEOUT"); v != "" {
+		if policy.Timeout, err = time.ParseDuration(v); err != nil {
+			return policy, fmt.Errorf("PREDICT_JOB_TIMEOUT: %w", err)
+		}
+	}
+	if policy.ModelTimeouts, err = watchdog.ParseTimeouts(os.Getenv("PREDICT_JOB_TIMEOUTS")); err != nil {
+		return policy, fmt.Errorf("PREDICT_JOB_TIMEOUTS: %w", err)
+	}
+	if v := os.Getenv("PREDICT_QUEUE_TIMEOUT"); v != "" {
+		if policy.QueueTimeout, err = time.ParseDuration(v); err != nil {
+			return policy, fmt.Errorf("PREDICT_QUEUE_TIMEOUT: %w", err)
+		}
+	}
+	if v := os.Getenv("PREDICT_JOB_RETRIES"); v != "" {
+		if policy.MaxRetries, err = strconv.Atoi(v); err != nil || policy.MaxRetries < 0 {
+			return policy, errors.New("PREDICT_JOB_RETRIES must be a non-negative integer")
+		}
+	}
+	return policy, nil
+}
+
+// renderCache reads RENDER_CACHE, whether renders are kept in MinIO. It is
+// off when unset.
+func renderCache() (bool, error) {
+	v := os.Getenv("RENDER_CACHE")
+	if v == "" {
+		return false, nil
+	}
+	cache, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("RENDER_CACHE: %w", err)
+	}
+	return cache, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/label-platform-backend/internal/infrastructure/redis"
+	"github.com/label-platform-backend/pkg/worker"
+)
+
+var elementTypes = []string{"button", "input", "link", "text", "image", "checkbox", "icon"}
+
+var elementTexts = map[string][]string{
+	"button":   {"Submit", "Cancel", "Save", "Next"},
+	"input":    {"Enter text", "Email", "Password"},
+	"link":     {"Learn more", "Sign in", "Forgot password?"},
+	"text":     {"Welcome back", "Settings", "Profile"},
+	"checkbox": {"Remember me", "I agree"},
+}
+
+// mockPredictor emits predictions derived only from the image content, so the
+// same screenshot always yields the same elements
+type mockPredictor struct{}
+
+func (mockPredictor) Predict(ctx context.Context, req *worker.Request) (*worker.Result, error) {
+	sum := sha256.Sum256(req.Image)
+	rng := rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(sum[:8]))))
+
+	width, height := 1280.0, 800.0
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(req.Image)); err == nil {
+		width, height = float64(cfg.Width), float64(cfg.Height)
+	}
+
+	count := 3 + rng.Intn(6)
+	elements := make([]worker.Element, 0, count)
+	rowHeight := height / float64(count)
+	for i := 0; i < count; i++ {
+		typ := elementTypes[rng.Intn(len(elementTypes))]
+		w := width * (0.1 + 0.3*rng.Float64())
+		h := rowHeight * (0.3 + 0.5*rng.Float64())
+		el := worker.Element{
+			Type: typ,
+			BBox: worker.BBox{
+				X:      math.Round(rng.Float64() * (width - w)),
+				Y:      math.Round(float64(i)*rowHeight + (rowHeight-h)/2),
+				Width:  math.Round(w),
+				Height: math.Round(h),
+			},
+			Confidence: math.Round((0.5+0.5*rng.Float64())*100) / 100,
+		}
+		if texts := elementTexts[typ]; len(texts) > 0 {
+			el.Text = texts[rng.Intn(len(texts))]
+		}
+		elements = append(elements, el)
+	}
+	return &worker.Result{Elements: elements}, nil
+}
+
+func main() {
+	model := flag.String("model", "gpt", "model queue to consume: gpt, claude or gemini")
+	notifyURL := flag.String("notify-url", "", "backend notify endpoint (defaults to $BACKEND_URL/api/v1/predict/notify)")
+	concurrency := flag.Int("concurrency", 1, "number of jobs processed in parallel")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	queue, ok := redis.ModelQueues[*model]
+	if !ok {
+		log.Fatalf("Unknown model %q", *model)
+	}
+
+	if *notifyURL == "" {
+		backendURL := os.Getenv("BACKEND_URL")
+		if backendURL == "" {
+			backendURL = "http://localhost:8080"
+		}
+		*notifyURL = fmt.Sprintf("%s/api/v1/predict/notify", backendURL)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	redisHost := os.Getenv("REDIS_HOST")
+	if redisHost == "" {
+		redisHost = "localhost:6379"
+	}
+	client := goredis.NewClient(&goredis.Options{
+		Addr:     redisHost,
+		Password: os.Getenv("REDIS_PASSWORD"),
+	})
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+
+	w := worker.New(
+		worker.Config{Model: *model, Concurrency: *concurrency, MaxRetries: 3},
+		worker.NewRedisQueue(client, queue),
+		worker.NewHTTPReporter(*notifyURL),
+		mockPredictor{},
+	).WithJobStore(worker.NewRedisJobStore(client))
+
+	log.Printf("Mock worker consuming %s, reporting to %s", queue, *notifyURL)
+	if err := w.Run(ctx); err != nil && err != context.Canceled {
+		log.Fatalf("Worker stopped: %v", err)
+	}
+	log.Println("Mock worker exited")
+}
@@ -0,0 +1,150 @@
+// Package ensemble combines the elements predicted by several models into a
+// consensus prediction. Elements are clustered by IoU, one element per model
+// and cluster, their boxes merged with weighted box fusion and their class
+// and text decided by a vote weighted by confidence.
+package ensemble
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/label-platform-backend/internal/application/evaluator"
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// Options configures Combine
+type Options struct {
+	// IoUThreshold is the minimum IoU of an element with the fused box of a
+	// cluster to join it. Zero uses evaluator.DefaultIoUThreshold.
+	IoUThreshold float64
+	// MinVotes is the number of models that must have found an element for
+	// it to be kept. Zero means a majority of the models.
+	MinVotes int
+}
+
+type member struct {
+	model  int
+	el     entity.Element
+	weight float64
+}
+
+type cluster struct {
+	members []member
+	box     entity.BBox
+}
+
+func (c *cluster) has(model int) bool {
+	for _, m := range c.members {
+		if m.model == model {
+			return true
+		}
+	}
+	return false
+}
+
+// Combine fuses the elements of several models, given as one slice per
+// model. Elements are visited by decreasing confidence and join the cluster
+// whose fused box overlaps them most, unless it already holds an element of
+// the same model. Elements without confidence weigh 1. The confidence of a
+// fused element is the summed weight of its cluster divided by the number of
+// models, so elements few models found score low.
+func Combine(predictions [][]entity.Element, opts Options) []entity.Element {
+	if opts.IoUThreshold == 0 {
+		opts.IoUThreshold = evaluator.DefaultIoUThreshold
+	}
+	if opts.MinVotes == 0 {
+		opts.MinVotes = len(predictions)/2 + 1
+	}
+
+	var members []member
+	for model, elements := range predictions {
+		for _, el := range elements {
+			members = append(members, member{model: model, el: el, weight: weight(el)})
+		}
+	}
+	sort.SliceStable(members, func(i, j int) bool { return members[i].weight > members[j].weight })
+
+	var clusters []*cluster
+	for _, m := range members {
+		var best *cluster
+		bestIoU := 0.0
+		for _, c := range clusters {
+			if c.has(m.model) {
+				continue
+			}
+			if iou := c.box.IoU(m.el.BBox); iou >= opts.IoUThreshold && iou > bestIoU {
+				best, bestIoU = c, iou
+			}
+		}
+		if best == nil {
+			best = &cluster{}
+			clusters = append(clusters, best)
+		}
+		best.members = append(best.members, m)
+		best.box = fuseBoxes(best.members)
+	}
+
+	combined := make([]entity.Element, 0, len(clusters))
+	for _, c := range clusters {
+		if len(c.members) < opts.MinVotes {
+			continue
+		}
+		total := 0.0
+		for _, m := range c.members {
+			total += m.weight
+		}
+		combined = append(combined, entity.Element{
+			Type:       vote(c.members, func(el entity.Element) string { return el.Type }),
+			Text:       vote(c.members, func(el entity.Element) string { return strings.TrimSpace(el.Text) }),
+			BBox:       c.box,
+			Confidence: round(math.Min(total/float64(len(predictions)), 1)),
+			Source:     entity.EnsembleModel,
+		})
+	}
+	return combined
+}
+
+// weight is the confidence of an element, or 1 when it has none
+func weight(el entity.Element) float64 {
+	if el.Confidence > 0 {
+		return el.Confidence
+	}
+	return 1
+}
+
+// fuseBoxes averages the corners of the members' boxes weighted by their
+// confidence
+func fuseBoxes(members []member) entity.BBox {
+	var x1, y1, x2, y2, total float64
+	for _, m := range members {
+		b := m.el.BBox
+		x1 += m.weight * b.X
+		y1 += m.weight * b.Y
+		x2 += m.weight * (b.X + b.Width)
+		y2 += m.weight * (b.Y + b.Height)
+		total += m.weight
+	}
+	x1, y1, x2, y2 = x1/total, y1/total, x2/total, y2/total
+	return entity.BBox{X: round(x1), Y: round(y1), Width: round(x2 - x1), Height: round(y2 - y1)}
+}
+
+// vote returns the value of key with the highest summed weight among the
+// members, ties going to the smallest value
+func vote(members []member, key func(entity.Element) string) string {
+	weights := map[string]float64{}
+	for _, m := range members {
+		weights[key(m.el)] += m.weight
+	}
+	winner, best := "", -1.0
+	for value, w := range weights {
+		if w > best || (w == best && value < winner) {
+			winner, best = value, w
+		}
+	}
+	return winner
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
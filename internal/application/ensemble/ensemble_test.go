@@ -0,0 +1,53 @@
+package ensemble
+
+import (
+	"testing"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func el(typ, text string, x, y, w, h, confidence float64) entity.Element {
+	return entity.Element{Type: typ, Text: text, BBox: entity.BBox{X: x, Y: y, Width: w, Height: h}, Confidence: confidence}
+}
+
+func TestCombine(t *testing.T) {
+	gpt := []entity.Element{
+		el("button", "Submit", 100, 100, 80, 40, 0.9),
+		el("text", "Only GPT", 500, 500, 50, 20, 0.8),
+	}
+	claude := []entity.Element{
+		el("button", "Submit", 110, 100, 80, 40, 0.6),
+		el("input", "", 0, 300, 200, 40, 0.7),
+	}
+	gemini := []entity.Element{
+		el("link", "Submit!", 100, 110, 80, 40, 0.5),
+		el("input", "Email", 10, 300, 200, 40, 0.5),
+	}
+
+	combined := Combine([][]entity.Element{gpt, claude, gemini}, Options{})
+	require.Len(t, combined, 2)
+
+	button := combined[0]
+	assert.Equal(t, "button", button.Type)
+	assert.Equal(t, "Submit", button.Text)
+	assert.Equal(t, entity.EnsembleModel, button.Source)
+	// Corners weighted by confidence: x = (0.9*100 + 0.6*110 + 0.5*100) / 2
+	assert.Equal(t, entity.BBox{X: 103, Y: 102.5, Width: 80, Height: 40}, button.BBox)
+	assert.InDelta(t, 2.0/3, button.Confidence, 0.01)
+
+	input := combined[1]
+	assert.Equal(t, "input", input.Type)
+	assert.Equal(t, "", input.Text, "0.7 for no text outweighs 0.5 for Email")
+	assert.InDelta(t, 0.4, input.Confidence, 0.01)
+}
+
+func TestCombine_OneElementPerModel(t *testing.T) {
+	// Two overlapping elements of one model never fuse together
+	gpt := []entity.Element{el("button", "", 0, 0, 100, 40, 0.9), el("button", "", 5, 0, 100, 40, 0.8)}
+
+	assert.Len(t, Combine([][]entity.Element{gpt}, Options{}), 2)
+	assert.Empty(t, Combine([][]entity.Element{gpt, nil}, Options{}))
+	assert.Len(t, Combine([][]entity.Element{gpt, nil}, Options{MinVotes: 1}), 2)
+}
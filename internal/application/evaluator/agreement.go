@@ -0,0 +1,209 @@
+package evaluator
+
+import (
+	"sort"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// AnnotatorPair measures the agreement of two annotations of the same image.
+// The elements of a are matched to those of b as ground truth to
+// predictions.
+func AnnotatorPair(a, b []entity.Element, threshold float64) entity.PairAgreement {
+	m := Match(a, b, threshold)
+	p := entity.PairAgreement{
+		Images:    1,
+		ElementsA: len(a),
+		ElementsB: len(b),
+		Matched:   len(m.Pairs),
+		Table:     Confusion(a, b, m),
+	}
+	for _, pair := range m.Pairs {
+		p.IoUSum += pair.IoU
+		if a[pair.GT].Type == b[pair.Pred].Type {
+			p.SameClass++
+		}
+	}
+	summarizePair(&p)
+	return p
+}
+
+// MergePair adds the counts of src to dst and recomputes its metrics, so the
+// agreement of a pair can be pooled over images
+func MergePair(dst *entity.PairAgreement, src entity.PairAgreement) {
+	dst.Images += src.Images
+	dst.ElementsA += src.ElementsA
+	dst.ElementsB += src.ElementsB
+	dst.Matched += src.Matched
+	dst.SameClass += src.SameClass
+	dst.IoUSum += src.IoUSum
+	if dst.Table == nil {
+		dst.Table = entity.ConfusionMatrix{}
+	}
+	for a, row := range src.Table {
+		for b, n := range row {
+			dst.Table.Add(a, b, n)
+		}
+	}
+	summarizePair(dst)
+}
+
+func summarizePair(p *entity.PairAgreement) {
+	p.MeanIoU = nil
+	p.ClassAgreement = 0
+	if p.Matched > 0 {
+		miou := p.IoUSum / float64(p.Matched)
+		p.MeanIoU = &miou
+		p.ClassAgreement = float64(p.SameClass) / float64(p.Matched)
+	}
+	// Two empty annotations agree
+	p.F1 = 1
+	if total := p.ElementsA + p.ElementsB; total > 0 {
+		p.F1 = 2 * float64(p.SameClass) / float64(total)
+	}
+	p.Kappa = CohenKappa(p.Table)
+}
+
+// CohenKappa computes Cohen's kappa of a table counting the units by the
+// class given by each of two raters. It is nil for an empty table and 1 when
+// both raters always give the same single class.
+func CohenKappa(table entity.ConfusionMatrix) *float64 {
+	rows, cols := map[string]int{}, map[string]int{}
+	n, agreed := 0, 0
+	for a, row := range table {
+		for b, count := range row {
+			rows[a] += count
+			cols[b] += count
+			n += count
+			if a == b {
+				agreed += count
+			}
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+
+	observed := float64(agreed) / float64(n)
+	expected := 0.0
+	for class, count := range rows {
+		expected += float64(count) * float64(cols[class])
+	}
+	expected /= float64(n) * float64(n)
+
+	kappa := 1.0
+	if expected < 1 {
+		kappa = (observed - expected) / (1 - expected)
+	}
+	return &kappa
+}
+
+// AgreementUnits groups the elements several annotators drew on an image
+// into units, one per object. The elements of each annotator are matched by
+// IoU to the first element of the units found so far; unmatched elements
+// start new units. A unit holds the class given by each annotator, in the
+// order of annotations, and Background for annotators who did not draw it.
+func AgreementUnits(annotations [][]entity.Element, threshold float64) [][]string {
+	var units [][]string
+	var firsts []entity.Element
+	for i, elements := range annotations {
+		m := Match(firsts, elements, threshold)
+		for _, p := range m.Pairs {
+			units[p.GT][i] = elements[p.Pred].Type
+		}
+		for _, j := range m.UnmatchedPred {
+			unit := make([]string, len(annotations))
+			for k := range unit {
+				unit[k] = entity.Background
+			}
+			unit[i] = elements[j].Type
+			units = append(units, unit)
+			firsts = append(firsts, elements[j])
+		}
+	}
+	return units
+}
+
+// KrippendorffAlpha computes Krippendorff's alpha for nominal values. Each
+// unit lists the values given by its raters; units with fewer than two
+// values are not pairable and ignored. It is nil when the units hold a
+// single value, as the expected disagreement is then zero.
+func KrippendorffAlpha(units [][]string) *float64 {
+	// Coincidences of the values within units, each unit weighing its
+	// number of values
+	coincidences := map[[2]string]float64{}
+	for _, unit := range units {
+		m := len(unit)
+		if m < 2 {
+			continue
+		}
+		for i := range unit {
+			for j := range unit {
+				if i != j {
+					coincidences[[2]string{unit[i], unit[j]}] += 1 / float64(m-1)
+				}
+			}
+		}
+	}
+
+	totals := map[string]float64{}
+	n, observed := 0.0, 0.0
+	for values, count := range coincidences {
+		totals[values[0]] += count
+		n += count
+		if values[0] != values[1] {
+			observed += count
+		}
+	}
+	expected := 0.0
+	for c, nc := range totals {
+		for k, nk := range totals {
+			if c != k {
+				expected += nc * nk
+			}
+		}
+	}
+	if expected == 0 {
+		return nil
+	}
+	alpha := 1 - (n-1)*observed/expected
+	return &alpha
+}
+
+// ImageAgreement computes the agreement of the annotations of an image by
+// annotator: every pair of annotators, in sorted order, and Krippendorff's
+// alpha over the units of AgreementUnits, which are returned so alpha can be
+// pooled over images.
+func ImageAgreement(annotations map[string][]entity.Element, threshold float64) (entity.ImageAgreement, [][]string) {
+	annotators := make([]string, 0, len(annotations))
+	for annotator := range annotations {
+		annotators = append(annotators, annotator)
+	}
+	sort.Strings(annotators)
+
+	result := entity.ImageAgreement{Annotators: annotators}
+	elements := make([][]entity.Element, len(annotators))
+	matched, iouSum, f1Sum := 0, 0.0, 0.0
+	for i, a := range annotators {
+		elements[i] = annotations[a]
+		for _, b := range annotators[i+1:] {
+			pair := AnnotatorPair(annotations[a], annotations[b], threshold)
+			pair.A, pair.B = a, b
+			result.Pairs = append(result.Pairs, pair)
+			matched += pair.Matched
+			iouSum += pair.IoUSum
+			f1Sum += pair.F1
+		}
+	}
+	if len(result.Pairs) > 0 {
+		result.Agreement = f1Sum / float64(len(result.Pairs))
+	}
+	if matched > 0 {
+		miou := iouSum / float64(matched)
+		result.MeanIoU = &miou
+	}
+
+	units := AgreementUnits(elements, threshold)
+	result.Alpha = KrippendorffAlpha(units)
+	return result, units
+}
@@ -0,0 +1,81 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCohenKappa(t *testing.T) {
+	table := entity.ConfusionMatrix{}
+	table.Add("a", "a", 2)
+	table.Add("a", "b", 1)
+	table.Add("b", "b", 2)
+
+	// po = 0.8, pe = (3*2 + 2*3) / 25
+	kappa := CohenKappa(table)
+	require.NotNil(t, kappa)
+	assert.InDelta(t, (0.8-0.48)/0.52, *kappa, 1e-9)
+
+	same := entity.ConfusionMatrix{}
+	same.Add("a", "a", 3)
+	require.NotNil(t, CohenKappa(same))
+	assert.Equal(t, 1.0, *CohenKappa(same))
+	assert.Nil(t, CohenKappa(entity.ConfusionMatrix{}))
+}
+
+func TestKrippendorffAlpha(t *testing.T) {
+	alpha := KrippendorffAlpha([][]string{{"a", "a"}, {"a", "b"}, {"b", "b"}, {"c"}})
+	require.NotNil(t, alpha)
+	assert.InDelta(t, 1-5.0*2/18, *alpha, 1e-9)
+
+	assert.Nil(t, KrippendorffAlpha([][]string{{"a", "a"}, {"a", "a"}}))
+}
+
+func TestAnnotatorPair(t *testing.T) {
+	a := []entity.Element{el("button", 0, 0, 100, 40), el("input", 0, 100, 200, 40)}
+	b := []entity.Element{el("button", 0, 0, 100, 40), el("input", 0, 100, 200, 40), el("text", 500, 500, 10, 10)}
+
+	pair := AnnotatorPair(a, b, DefaultIoUThreshold)
+	assert.Equal(t, 2, pair.Matched)
+	assert.Equal(t, 2, pair.SameClass)
+	assert.InDelta(t, 0.8, pair.F1, 1e-9)
+	assert.Equal(t, 1.0, pair.ClassAgreement)
+	require.NotNil(t, pair.Kappa)
+	assert.InDelta(t, 4.0/7, *pair.Kappa, 1e-9)
+
+	MergePair(&pair, AnnotatorPair(nil, nil, DefaultIoUThreshold))
+	assert.Equal(t, 2, pair.Images)
+	assert.InDelta(t, 0.8, pair.F1, 1e-9, "empty annotations add nothing to the pooled counts")
+	assert.Equal(t, 1.0, AnnotatorPair(nil, nil, DefaultIoUThreshold).F1)
+}
+
+func TestImageAgreement(t *testing.T) {
+	result, units := ImageAgreement(map[string][]entity.Element{
+		"carol": {el("button", 0, 0, 100, 40), el("input", 0, 100, 200, 40), el("text", 500, 500, 10, 10)},
+		"alice": {el("button", 0, 0, 100, 40), el("input", 0, 100, 200, 40)},
+		"bob":   {el("button", 0, 0, 100, 40), el("link", 0, 100, 200, 40)},
+	}, DefaultIoUThreshold)
+
+	assert.Equal(t, []string{"alice", "bob", "carol"}, result.Annotators)
+	assert.Equal(t, [][]string{
+		{"button", "button", "button"},
+		{"input", "link", "input"},
+		{entity.Background, entity.Background, "text"},
+	}, units)
+
+	require.Len(t, result.Pairs, 3)
+	assert.Equal(t, "alice", result.Pairs[0].A)
+	assert.Equal(t, "bob", result.Pairs[0].B)
+	assert.InDelta(t, 0.5, result.Pairs[0].F1, 1e-9)
+	assert.InDelta(t, (0.5+0.8+0.4)/3, result.Agreement, 1e-9)
+	require.NotNil(t, result.MeanIoU)
+	assert.InDelta(t, 1.0, *result.MeanIoU, 1e-9)
+
+	// Coincidences: button 3, input 2, link 1, background 2, text 1 with 4
+	// disagreeing
+	require.NotNil(t, result.Alpha)
+	assert.InDelta(t, 1-8.0*4/62, *result.Alpha, 1e-9)
+}
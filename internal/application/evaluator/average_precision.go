@@ -0,0 +1,197 @@
+package evaluator
+
+import (
+	"sort"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// COCOThresholds are the IoU thresholds of mAP@[.5:.95]
+var COCOThresholds = []float64{0.5, 0.55, 0.6, 0.65, 0.7, 0.75, 0.8, 0.85, 0.9, 0.95}
+
+// threshold indexes of AP50 and AP75 in COCOThresholds
+const (
+	iou50 = 0
+	iou75 = 5
+)
+
+// Detections scores every predicted element COCO-style: at each IoU
+// threshold, predictions are matched per class in decreasing confidence
+// order to the unmatched ground truth element of the same class with the
+// highest IoU.
+func Detections(gt, pred []entity.Element) []entity.Detection {
+	if len(pred) == 0 {
+		return nil
+	}
+
+	order := make([]int, len(pred))
+	for j := range order {
+		order[j] = j
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return pred[order[a]].Confidence > pred[order[b]].Confidence
+	})
+
+	detections := make([]entity.Detection, len(pred))
+	for j := range pred {
+		detections[j] = entity.Detection{Class: pred[j].Type, Confidence: pred[j].Confidence}
+	}
+	for t, threshold := range COCOThresholds {
+		gtUsed := make([]bool, len(gt))
+		for _, j := range order {
+			best, bestIoU := -1, threshold
+			for i := range gt {
+				if gtUsed[i] || gt[i].Type != pred[j].Type {
+					continue
+				}
+				if iou := gt[i].BBox.IoU(pred[j].BBox); iou >= bestIoU && iou > 0 {
+					best, bestIoU = i, iou
+				}
+			}
+			if best >= 0 {
+				gtUsed[best] = true
+				detections[j].TPMask |= 1 << t
+			}
+		}
+	}
+	return detections
+}
+
+// PrecisionRecall computes the PR curves and average precisions of a
+// dataset from its detections and the number of ground truth elements per
+// class. Classes without ground truth elements are left out of the means, as
+// in COCO.
+func PrecisionRecall(detections []entity.Detection, groundTruth map[string]int) (mAP, mAP50, mAP75 float64, classes []entity.ClassAP) {
+	byClass := map[string][]entity.Detection{}
+	for _, d := range detections {
+		byClass[d.Class] = append(byClass[d.Class], d)
+	}
+
+	names := make([]string, 0, len(groundTruth))
+	for class, n := range groundTruth {
+		if n > 0 {
+			names = append(names, class)
+		}
+	}
+	sort.Strings(names)
+
+	for _, class := range names {
+		dets := sortDetections(byClass[class])
+		n := groundTruth[class]
+		c := entity.ClassAP{
+			Class:       class,
+			GroundTruth: n,
+			Detections:  len(dets),
+			Curve:       PRCurve(dets, n, iou50),
+		}
+		for t := range COCOThresholds {
+			ap := AveragePrecision(dets, n, t)
+			c.AP += ap / float64(len(COCOThresholds))
+			switch t {
+			case iou50:
+				c.AP50 = ap
+			case iou75:
+				c.AP75 = ap
+			}
+		}
+		mAP += c.AP
+		mAP50 += c.AP50
+		mAP75 += c.AP75
+		classes = append(classes, c)
+	}
+	if len(classes) > 0 {
+		n := float64(len(classes))
+		mAP, mAP50, mAP75 = mAP/n, mAP50/n, mAP75/n
+	}
+	return mAP, mAP50, mAP75, classes
+}
+
+// PRCurve sweeps the confidence threshold over detections sorted by
+// decreasing confidence and returns one point per distinct confidence, for
+// the IoU threshold at index t of COCOThresholds
+func PRCurve(sorted []entity.Detection, groundTruth int, t int) []entity.PRPoint {
+	var curve []entity.PRPoint
+	tp, fp := 0, 0
+	for k, d := range sorted {
+		if d.TPMask&(1<<t) != 0 {
+			tp++
+		} else {
+			fp++
+		}
+		// Predictions with equal confidence are kept or dropped together
+		if k+1 < len(sorted) && sorted[k+1].Confidence == d.Confidence {
+			continue
+		}
+		precision, recall, f1 := PRF(tp, fp, groundTruth-tp)
+		curve = append(curve, entity.PRPoint{Threshold: d.Confidence, Precision: precision, Recall: recall, F1: f1})
+	}
+	return curve
+}
+
+// BestF1 returns the point of a PR curve with the highest F1, preferring the
+// higher threshold on ties
+func BestF1(curve []entity.PRPoint) *entity.PRPoint {
+	var best *entity.PRPoint
+	for k := range curve {
+		if best == nil || curve[k].F1 > best.F1 {
+			best = &curve[k]
+		}
+	}
+	return best
+}
+
+// AveragePrecision is the COCO 101-point interpolated average precision of
+// detections sorted by decreasing confidence, at the IoU threshold at index t
+// of COCOThresholds
+func AveragePrecision(sorted []entity.Detection, groundTruth int, t int) float64 {
+	if groundTruth == 0 {
+		return 0
+	}
+
+	precision := make([]float64, len(sorted))
+	recall := make([]float64, len(sorted))
+	tp := 0
+	for k, d := range sorted {
+		if d.TPMask&(1<<t) != 0 {
+			tp++
+		}
+		precision[k] = float64(tp) / float64(k+1)
+		recall[k] = float64(tp) / float64(groundTruth)
+	}
+	// Interpolate: precision at recall r is the best precision at any recall >= r
+	for k := len(precision) - 2; k >= 0; k-- {
+		precision[k] = max(precision[k], precision[k+1])
+	}
+
+	var sum float64
+	k := 0
+	for step := 0; step <= 100; step++ {
+		r := float64(step) / 100
+		for k < len(recall) && recall[k] < r-1e-12 {
+			k++
+		}
+		if k == len(recall) {
+			break
+		}
+		sum += precision[k]
+	}
+	return sum / 101
+}
+
+// sortDetections sorts detections by decreasing confidence
+func sortDetections(detections []entity.Detection) []entity.Detection {
+	sorted := append([]entity.Detection(nil), detections...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return sorted[a].Confidence > sorted[b].Confidence
+	})
+	return sorted
+}
+
+// PooledCurve is the PR curve of all classes together at IoU 0.5
+func PooledCurve(detections []entity.Detection, groundTruth map[string]int) []entity.PRPoint {
+	total := 0
+	for _, n := range groundTruth {
+		total += n
+	}
+	return PRCurve(sortDetections(detections), total, iou50)
+}
@@ -0,0 +1,84 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scored(typ string, confidence, x, y, w, h float64) entity.Element {
+	e := el(typ, x, y, w, h)
+	e.Confidence = confidence
+	return e
+}
+
+func TestDetections_TPMask(t *testing.T) {
+	gt := []entity.Element{el("button", 0, 0, 100, 100)}
+	pred := []entity.Element{
+		scored("button", 0.6, 0, 0, 100, 75),  // IoU 0.75
+		scored("button", 0.9, 0, 0, 100, 100), // IoU 1, matched first
+		scored("link", 0.8, 0, 0, 100, 100),   // wrong class
+	}
+
+	dets := Detections(gt, pred)
+	require.Len(t, dets, 3)
+	assert.Equal(t, 0, dets[0].TPMask, "duplicate of a matched element")
+	assert.Equal(t, 1<<len(COCOThresholds)-1, dets[1].TPMask)
+	assert.Equal(t, 0, dets[2].TPMask)
+
+	// Without the better prediction, the 0.75 box is a TP up to IoU 0.75
+	dets = Detections(gt, pred[:1])
+	assert.Equal(t, 0b111111, dets[0].TPMask)
+}
+
+func TestAveragePrecision(t *testing.T) {
+	all := 1<<len(COCOThresholds) - 1
+	sorted := []entity.Detection{
+		{Class: "button", Confidence: 0.9, TPMask: all},
+		{Class: "button", Confidence: 0.8},
+		{Class: "button", Confidence: 0.7, TPMask: all},
+	}
+
+	// Recall 0..0.5 at precision 1, recall 0.51..1 at interpolated precision 2/3
+	assert.InDelta(t, (51+50*2.0/3)/101, AveragePrecision(sorted, 2, iou50), 1e-9)
+	assert.InDelta(t, 1.0, AveragePrecision(sorted[:1], 1, iou50), 1e-9)
+	assert.Equal(t, 0.0, AveragePrecision(nil, 3, iou50))
+
+	curve := PRCurve(sorted, 2, iou50)
+	require.Len(t, curve, 3)
+	assert.Equal(t, entity.PRPoint{Threshold: 0.9, Precision: 1, Recall: 0.5, F1: 2.0 / 3}, curve[0])
+	best := BestF1(curve)
+	require.NotNil(t, best)
+	assert.Equal(t, 0.7, best.Threshold)
+	assert.InDelta(t, 0.8, best.F1, 1e-9)
+}
+
+func TestPRCurve_TiedConfidences(t *testing.T) {
+	sorted := []entity.Detection{
+		{Class: "button", Confidence: 1, TPMask: 1},
+		{Class: "button", Confidence: 1},
+	}
+	curve := PRCurve(sorted, 1, iou50)
+	require.Len(t, curve, 1)
+	assert.Equal(t, 0.5, curve[0].Precision)
+	assert.Equal(t, 1.0, curve[0].Recall)
+}
+
+func TestPrecisionRecall(t *testing.T) {
+	dets := []entity.Detection{
+		{Class: "button", Confidence: 0.9, TPMask: 1<<len(COCOThresholds) - 1},
+		{Class: "link", Confidence: 0.5, TPMask: 0b1}, // TP at IoU 0.5 only
+		{Class: "text", Confidence: 0.4},              // no ground truth
+	}
+
+	mAP, mAP50, mAP75, classes := PrecisionRecall(dets, map[string]int{"button": 1, "link": 1})
+	require.Len(t, classes, 2)
+	assert.Equal(t, "button", classes[0].Class)
+	assert.InDelta(t, 1.0, classes[0].AP, 1e-9)
+	assert.InDelta(t, 0.1, classes[1].AP, 1e-9)
+	assert.InDelta(t, 1.0, mAP50, 1e-9)
+	assert.InDelta(t, 0.5, mAP75, 1e-9)
+	assert.InDelta(t, 0.55, mAP, 1e-9)
+}
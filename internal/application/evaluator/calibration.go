@@ -0,0 +1,70 @@
+package evaluator
+
+import (
+	"math"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// DefaultCalibrationBins is the number of reliability diagram bins
+const DefaultCalibrationBins = 10
+
+// Calibrate computes the reliability diagram, expected and maximum
+// calibration errors and Brier score of detections, using equal-width
+// confidence bins. A detection is correct when it is a true positive at
+// IoU 0.5. Detections without a confidence are left out.
+func Calibrate(detections []entity.Detection, bins int) entity.CalibrationReport {
+	if bins <= 0 {
+		bins = DefaultCalibrationBins
+	}
+
+	report := entity.CalibrationReport{Bins: make([]entity.ReliabilityBin, bins)}
+	correct := make([]int, bins)
+	confidence := make([]float64, bins)
+	for k := range report.Bins {
+		report.Bins[k].Lower = float64(k) / float64(bins)
+		report.Bins[k].Upper = float64(k+1) / float64(bins)
+	}
+
+	for _, d := range detections {
+		if d.Confidence <= 0 {
+			report.WithoutConfidence++
+			continue
+		}
+		conf := math.Min(d.Confidence, 1)
+		outcome := 0.0
+		if d.TPMask&(1<<iou50) != 0 {
+			outcome = 1
+		}
+
+		k := min(int(conf*float64(bins)), bins-1)
+		report.Bins[k].Count++
+		confidence[k] += conf
+		correct[k] += int(outcome)
+
+		report.Samples++
+		report.MeanConfidence += conf
+		report.Accuracy += outcome
+		report.Brier += (conf - outcome) * (conf - outcome)
+	}
+	if report.Samples == 0 {
+		return report
+	}
+
+	n := float64(report.Samples)
+	report.MeanConfidence /= n
+	report.Accuracy /= n
+	report.Brier /= n
+	for k := range report.Bins {
+		b := &report.Bins[k]
+		if b.Count == 0 {
+			continue
+		}
+		b.MeanConfidence = confidence[k] / float64(b.Count)
+		b.Accuracy = float64(correct[k]) / float64(b.Count)
+		gap := math.Abs(b.Accuracy - b.MeanConfidence)
+		report.ECE += float64(b.Count) / n * gap
+		report.MCE = math.Max(report.MCE, gap)
+	}
+	return report
+}
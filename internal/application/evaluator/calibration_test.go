@@ -0,0 +1,49 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalibrate(t *testing.T) {
+	dets := []entity.Detection{
+		{Confidence: 0.9, TPMask: 1},
+		{Confidence: 0.9, TPMask: 1},
+		{Confidence: 0.9},
+		{Confidence: 0.9, TPMask: 1},
+		{Confidence: 0.2},
+		{Confidence: 1, TPMask: 1}, // falls in the last bin
+		{Confidence: 0},            // no confidence
+	}
+
+	report := Calibrate(dets, 5)
+	assert.Equal(t, 6, report.Samples)
+	assert.Equal(t, 1, report.WithoutConfidence)
+	require.Len(t, report.Bins, 5)
+
+	low := report.Bins[1]
+	assert.Equal(t, 1, low.Count)
+	assert.InDelta(t, 0.2, low.MeanConfidence, 1e-9)
+	assert.Equal(t, 0.0, low.Accuracy)
+
+	high := report.Bins[4]
+	assert.Equal(t, 5, high.Count)
+	assert.InDelta(t, 0.92, high.MeanConfidence, 1e-9)
+	assert.InDelta(t, 0.8, high.Accuracy, 1e-9)
+
+	// ECE = 1/6 * 0.2 + 5/6 * 0.12
+	assert.InDelta(t, 0.2/6+0.6/6, report.ECE, 1e-9)
+	assert.InDelta(t, 0.2, report.MCE, 1e-9)
+	// Brier = (3*0.01 + 0.81 + 0.04 + 0) / 6
+	assert.InDelta(t, 0.88/6, report.Brier, 1e-9)
+}
+
+func TestCalibrate_Empty(t *testing.T) {
+	report := Calibrate(nil, 0)
+	assert.Len(t, report.Bins, DefaultCalibrationBins)
+	assert.Equal(t, 0, report.Samples)
+	assert.Equal(t, 0.0, report.ECE)
+}
@@ -0,0 +1,50 @@
+package evaluator
+
+import "github.com/label-platform-backend/internal/domain/entity"
+
+// Diff compares a prediction with the ground truth element by element. The
+// score is that of Evaluate, without confusion matrix and detections, and
+// texts are compared like TextAccuracy does.
+func Diff(gt, pred []entity.Element, threshold float64) entity.PredictionDiff {
+	m := Match(gt, pred, threshold)
+	diff := entity.PredictionDiff{
+		Score:           Evaluate(gt, pred, threshold),
+		Matched:         []entity.DiffEntry{},
+		ClassMismatches: []entity.DiffEntry{},
+		TextMismatches:  []entity.DiffEntry{},
+		FalsePositives:  make([]entity.DiffEntry, 0, len(m.UnmatchedPred)),
+		FalseNegatives:  make([]entity.DiffEntry, 0, len(m.UnmatchedGT)),
+	}
+	diff.Score.Confusion, diff.Score.Detections = nil, nil
+
+	for _, p := range m.Pairs {
+		p := p
+		entry := entity.DiffEntry{
+			GTIndex:   &p.GT,
+			GT:        &gt[p.GT],
+			PredIndex: &p.Pred,
+			Pred:      &pred[p.Pred],
+			IoU:       &p.IoU,
+		}
+		if gt[p.GT].Type == pred[p.Pred].Type {
+			diff.Matched = append(diff.Matched, entry)
+		} else {
+			diff.ClassMismatches = append(diff.ClassMismatches, entry)
+		}
+
+		want, got := []rune(normalizeText(gt[p.GT].Text)), []rune(normalizeText(pred[p.Pred].Text))
+		if dist := EditDistance(want, got); dist > 0 {
+			entry.TextDistance = &dist
+			diff.TextMismatches = append(diff.TextMismatches, entry)
+		}
+	}
+	for _, i := range m.UnmatchedGT {
+		i := i
+		diff.FalseNegatives = append(diff.FalseNegatives, entity.DiffEntry{GTIndex: &i, GT: &gt[i]})
+	}
+	for _, j := range m.UnmatchedPred {
+		j := j
+		diff.FalsePositives = append(diff.FalsePositives, entity.DiffEntry{PredIndex: &j, Pred: &pred[j]})
+	}
+	return diff
+}
@@ -0,0 +1,42 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	submit := el("button", 0, 0, 100, 40)
+	submit.Text = "Submit"
+	gt := []entity.Element{submit, el("input", 0, 100, 200, 40), el("text", 500, 500, 10, 10)}
+
+	read := el("button", 0, 0, 100, 40)
+	read.Text = "Submt"
+	pred := []entity.Element{el("icon", 300, 300, 20, 20), el("link", 0, 100, 200, 40), read}
+
+	diff := Diff(gt, pred, DefaultIoUThreshold)
+
+	require.Len(t, diff.Matched, 1)
+	assert.Equal(t, 0, *diff.Matched[0].GTIndex)
+	assert.Equal(t, 2, *diff.Matched[0].PredIndex)
+	assert.Equal(t, 1.0, *diff.Matched[0].IoU)
+
+	require.Len(t, diff.ClassMismatches, 1)
+	assert.Equal(t, "input", diff.ClassMismatches[0].GT.Type)
+	assert.Equal(t, "link", diff.ClassMismatches[0].Pred.Type)
+
+	require.Len(t, diff.TextMismatches, 1)
+	assert.Equal(t, 1, *diff.TextMismatches[0].TextDistance)
+
+	require.Len(t, diff.FalseNegatives, 1)
+	assert.Equal(t, 2, *diff.FalseNegatives[0].GTIndex)
+	assert.Nil(t, diff.FalseNegatives[0].Pred)
+	require.Len(t, diff.FalsePositives, 1)
+	assert.Equal(t, 0, *diff.FalsePositives[0].PredIndex)
+
+	assert.Equal(t, 1, diff.Score.TP)
+	assert.Nil(t, diff.Score.Confusion)
+}
@@ -0,0 +1,184 @@
+// Package evaluator compares predicted elements with ground truth elements.
+// Elements are matched one-to-one by IoU, preferring pairs of the same type;
+// remaining overlapping pairs of different types are matched afterwards so
+// that class confusions can be reported.
+package evaluator
+
+import (
+	"sort"
+	"time"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// DefaultIoUThreshold is the minimum IoU for two elements to match
+const DefaultIoUThreshold = 0.5
+
+// Pair is a matched ground truth and predicted element, by index
+type Pair struct {
+	GT   int     `json:"gt"`
+	Pred int     `json:"pred"`
+	IoU  float64 `json:"iou"`
+}
+
+// Matching is the one-to-one assignment of predicted to ground truth elements
+type Matching struct {
+	Pairs         []Pair
+	UnmatchedGT   []int
+	UnmatchedPred []int
+}
+
+// Match assigns predicted elements to ground truth elements. Same-type pairs
+// are matched first in decreasing IoU order, then the remaining pairs of
+// different types.
+func Match(gt, pred []entity.Element, threshold float64) *Matching {
+	var sameType, otherType []Pair
+	for i := range gt {
+		for j := range pred {
+			iou := gt[i].BBox.IoU(pred[j].BBox)
+			if iou < threshold || iou == 0 {
+				continue
+			}
+			p := Pair{GT: i, Pred: j, IoU: iou}
+			if gt[i].Type == pred[j].Type {
+				sameType = append(sameType, p)
+			} else {
+				otherType = append(otherType, p)
+			}
+		}
+	}
+
+	gtUsed := make([]bool, len(gt))
+	predUsed := make([]bool, len(pred))
+	m := &Matching{}
+	for _, candidates := range [][]Pair{sameType, otherType} {
+		sort.SliceStable(candidates, func(a, b int) bool {
+			ca, cb := candidates[a], candidates[b]
+			if ca.IoU != cb.IoU {
+				return ca.IoU > cb.IoU
+			}
+			return pred[ca.Pred].Confidence > pred[cb.Pred].Confidence
+		})
+		for _, p := range candidates {
+			if gtUsed[p.GT] || predUsed[p.Pred] {
+				continue
+			}
+			gtUsed[p.GT], predUsed[p.Pred] = true, true
+			m.Pairs = append(m.Pairs, p)
+		}
+	}
+
+	for i, used := range gtUsed {
+		if !used {
+			m.UnmatchedGT = append(m.UnmatchedGT, i)
+		}
+	}
+	for j, used := range predUsed {
+		if !used {
+			m.UnmatchedPred = append(m.UnmatchedPred, j)
+		}
+	}
+	return m
+}
+
+// Evaluate scores a prediction against the ground truth. A matched pair of
+// different types counts as both a false positive and a false negative.
+// mIoU is the mean IoU of all matched pairs, regardless of type.
+func Evaluate(gt, pred []entity.Element, threshold float64) entity.EvaluationScore {
+	m := Match(gt, pred, threshold)
+
+	score := entity.EvaluationScore{
+		Matched:      len(m.Pairs),
+		IoUThreshold: threshold,
+		EvaluatedAt:  time.Now(),
+	}
+	for _, p := range m.Pairs {
+		score.IoUSum += p.IoU
+		if gt[p.GT].Type == pred[p.Pred].Type {
+			score.TP++
+		}
+	}
+	score.Confusion = Confusion(gt, pred, m)
+	score.Detections = Detections(gt, pred)
+	score.Text = TextAccuracy(gt, pred, m.Pairs)
+	score.FP = len(pred) - score.TP
+	score.FN = len(gt) - score.TP
+	score.Precision, score.Recall, score.F1 = PRF(score.TP, score.FP, score.FN)
+	if score.Matched > 0 {
+		miou := score.IoUSum / float64(score.Matched)
+		score.MeanIoU = &miou
+	}
+	return score
+}
+
+// Confusion builds the confusion matrix of a matching. Unmatched ground truth
+// elements are counted as predicted Background, unmatched predictions as
+// Background ground truth.
+func Confusion(gt, pred []entity.Element, m *Matching) entity.ConfusionMatrix {
+	cm := entity.ConfusionMatrix{}
+	for _, p := range m.Pairs {
+		cm.Add(gt[p.GT].Type, pred[p.Pred].Type, 1)
+	}
+	for _, i := range m.UnmatchedGT {
+		cm.Add(gt[i].Type, entity.Background, 1)
+	}
+	for _, j := range m.UnmatchedPred {
+		cm.Add(entity.Background, pred[j].Type, 1)
+	}
+	return cm
+}
+
+// ClassBreakdown computes precision, recall and the error breakdown of every
+// class of a confusion matrix, in the order of cm.Classes()
+func ClassBreakdown(cm entity.ConfusionMatrix) []entity.ClassMetrics {
+	predicted := map[string]int{}
+	for _, row := range cm {
+		for class, n := range row {
+			predicted[class] += n
+		}
+	}
+
+	var metrics []entity.ClassMetrics
+	for _, class := range cm.Classes() {
+		if class == entity.Background {
+			continue
+		}
+		c := entity.ClassMetrics{
+			Class:     class,
+			Predicted: predicted[class],
+			TP:        cm[class][class],
+			Missed:    cm[class][entity.Background],
+			Spurious:  cm[entity.Background][class],
+		}
+		for _, n := range cm[class] {
+			c.Support += n
+		}
+		c.FP = c.Predicted - c.TP
+		c.FN = c.Support - c.TP
+		c.Mislabeled = c.FN - c.Missed
+		c.Precision, c.Recall, c.F1 = PRF(c.TP, c.FP, c.FN)
+		metrics = append(metrics, c)
+	}
+	return metrics
+}
+
+// PRF computes precision, recall and F1 from counts. An empty prediction on
+// an empty ground truth is perfect; otherwise undefined ratios are zero.
+func PRF(tp, fp, fn int) (precision, recall, f1 float64) {
+	switch {
+	case tp+fp > 0:
+		precision = float64(tp) / float64(tp+fp)
+	case fn == 0:
+		precision = 1
+	}
+	switch {
+	case tp+fn > 0:
+		recall = float64(tp) / float64(tp+fn)
+	case fp == 0:
+		recall = 1
+	}
+	if precision+recall > 0 {
+		f1 = 2 * precision * recall / (precision + recall)
+	}
+	return precision, recall, f1
+}
@@ -0,0 +1,113 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func el(typ string, x, y, w, h float64) entity.Element {
+	return entity.Element{Type: typ, BBox: entity.BBox{X: x, Y: y, Width: w, Height: h}}
+}
+
+func TestMatch_PrefersSameType(t *testing.T) {
+	gt := []entity.Element{el("button", 0, 0, 100, 40)}
+	pred := []entity.Element{
+		el("link", 0, 0, 100, 40),   // perfect box, wrong type
+		el("button", 0, 0, 100, 30), // IoU 0.75, right type
+	}
+
+	m := Match(gt, pred, DefaultIoUThreshold)
+	require.Len(t, m.Pairs, 1)
+	assert.Equal(t, 1, m.Pairs[0].Pred)
+	assert.InDelta(t, 0.75, m.Pairs[0].IoU, 1e-9)
+	assert.Equal(t, []int{0}, m.UnmatchedPred)
+	assert.Empty(t, m.UnmatchedGT)
+}
+
+func TestEvaluate(t *testing.T) {
+	gt := []entity.Element{
+		el("button", 0, 0, 100, 40),
+		el("input", 0, 100, 200, 40),
+		el("link", 0, 200, 50, 20),
+	}
+	pred := []entity.Element{
+		el("button", 0, 0, 100, 40),   // TP, IoU 1
+		el("button", 0, 100, 200, 40), // matched, wrong type
+		el("text", 500, 500, 10, 10),  // spurious
+	}
+
+	score := Evaluate(gt, pred, DefaultIoUThreshold)
+	assert.Equal(t, 1, score.TP)
+	assert.Equal(t, 2, score.FP)
+	assert.Equal(t, 2, score.FN)
+	assert.Equal(t, 2, score.Matched)
+	assert.InDelta(t, 1.0/3, score.Precision, 1e-9)
+	assert.InDelta(t, 1.0/3, score.Recall, 1e-9)
+	assert.InDelta(t, 1.0/3, score.F1, 1e-9)
+	require.NotNil(t, score.MeanIoU)
+	assert.InDelta(t, 1.0, *score.MeanIoU, 1e-9)
+}
+
+func TestConfusion(t *testing.T) {
+	gt := []entity.Element{
+		el("button", 0, 0, 100, 40),
+		el("button", 0, 100, 100, 40),
+		el("link", 0, 200, 50, 20),
+	}
+	pred := []entity.Element{
+		el("button", 0, 0, 100, 40),  // correct
+		el("link", 0, 100, 100, 40),  // button predicted as link
+		el("text", 500, 500, 10, 10), // spurious
+	}
+
+	score := Evaluate(gt, pred, DefaultIoUThreshold)
+	assert.Equal(t, entity.ConfusionMatrix{
+		"button":          {"button": 1, "link": 1},
+		"link":            {entity.Background: 1},
+		entity.Background: {"text": 1},
+	}, score.Confusion)
+	assert.Equal(t, []string{"button", "link", "text", entity.Background}, score.Confusion.Classes())
+
+	classes := ClassBreakdown(score.Confusion)
+	require.Len(t, classes, 3)
+
+	button := classes[0]
+	assert.Equal(t, "button", button.Class)
+	assert.Equal(t, 2, button.Support)
+	assert.Equal(t, 1, button.TP)
+	assert.Equal(t, 0, button.FP)
+	assert.Equal(t, 1, button.FN)
+	assert.Equal(t, 1, button.Mislabeled)
+	assert.Equal(t, 0, button.Missed)
+	assert.InDelta(t, 1.0, button.Precision, 1e-9)
+	assert.InDelta(t, 0.5, button.Recall, 1e-9)
+
+	link := classes[1]
+	assert.Equal(t, 1, link.Support)
+	assert.Equal(t, 1, link.Predicted)
+	assert.Equal(t, 0, link.TP)
+	assert.Equal(t, 1, link.FP)
+	assert.Equal(t, 1, link.Missed)
+	assert.Equal(t, 0.0, link.Recall)
+
+	text := classes[2]
+	assert.Equal(t, 0, text.Support)
+	assert.Equal(t, 1, text.Spurious)
+	assert.Equal(t, 0.0, text.Precision)
+}
+
+func TestEvaluate_Empty(t *testing.T) {
+	score := Evaluate(nil, nil, DefaultIoUThreshold)
+	assert.Equal(t, 1.0, score.Precision)
+	assert.Equal(t, 1.0, score.Recall)
+	assert.Equal(t, 1.0, score.F1)
+	assert.Nil(t, score.MeanIoU)
+
+	score = Evaluate([]entity.Element{el("button", 0, 0, 10, 10)}, nil, DefaultIoUThreshold)
+	assert.Equal(t, 0.0, score.Precision)
+	assert.Equal(t, 0.0, score.Recall)
+	assert.Equal(t, 1, score.FN)
+}
@@ -0,0 +1,229 @@
+package evaluator
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// PairedScore holds the scores of two systems on the same image
+type PairedScore struct {
+	A entity.EvaluationScore
+	B entity.EvaluationScore
+}
+
+// CompareOptions configures a paired comparison
+type CompareOptions struct {
+	Samples    int
+	Confidence float64
+	Seed       int64
+}
+
+// scoreSums sums the counts of per-image scores so that metrics can be
+// computed micro-averaged over any set of images
+type scoreSums struct {
+	tp, fp, fn, matched   int
+	iouSum                float64
+	textPairs, textExact  int
+	editDistance, gtChars int
+}
+
+func (s *scoreSums) add(score *entity.EvaluationScore) {
+	s.tp += score.TP
+	s.fp += score.FP
+	s.fn += score.FN
+	s.matched += score.Matched
+	s.iouSum += score.IoUSum
+	if score.Text != nil {
+		s.textPairs += score.Text.Pairs
+		s.textExact += score.Text.ExactMatches
+		s.editDistance += score.Text.EditDistance
+		s.gtChars += score.Text.GTChars
+	}
+}
+
+// comparedMetric computes a metric from summed counts; ok is false when the
+// metric is undefined
+type comparedMetric struct {
+	name  string
+	value func(s *scoreSums) (float64, bool)
+}
+
+var comparedMetrics = []comparedMetric{
+	{"precision", func(s *scoreSums) (float64, bool) {
+		p, _, _ := PRF(s.tp, s.fp, s.fn)
+		return p, true
+	}},
+	{"recall", func(s *scoreSums) (float64, bool) {
+		_, r, _ := PRF(s.tp, s.fp, s.fn)
+		return r, true
+	}},
+	{"f1", func(s *scoreSums) (float64, bool) {
+		_, _, f1 := PRF(s.tp, s.fp, s.fn)
+		return f1, true
+	}},
+	{"miou", func(s *scoreSums) (float64, bool) {
+		if s.matched == 0 {
+			return 0, false
+		}
+		return s.iouSum / float64(s.matched), true
+	}},
+	{"text_exact_match", func(s *scoreSums) (float64, bool) {
+		if s.textPairs == 0 {
+			return 0, false
+		}
+		return float64(s.textExact) / float64(s.textPairs), true
+	}},
+	{"text_cer", func(s *scoreSums) (float64, bool) {
+		if s.gtChars == 0 {
+			return 0, false
+		}
+		return float64(s.editDistance) / float64(s.gtChars), true
+	}},
+}
+
+// Compare runs a paired bootstrap and a sign test on every metric. Each
+// bootstrap sample draws images with replacement and recomputes the
+// micro-averaged metrics of both systems on it. Metrics undefined for either
+// system over all images are left out.
+func Compare(pairs []PairedScore, opts CompareOptions) []entity.MetricComparison {
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	var totalA, totalB scoreSums
+	for k := range pairs {
+		totalA.add(&pairs[k].A)
+		totalB.add(&pairs[k].B)
+	}
+
+	var metrics []comparedMetric
+	var comparisons []entity.MetricComparison
+	for _, m := range comparedMetrics {
+		a, okA := m.value(&totalA)
+		b, okB := m.value(&totalB)
+		if !okA || !okB {
+			continue
+		}
+		metrics = append(metrics, m)
+		comparisons = append(comparisons, entity.MetricComparison{
+			Metric:         m.name,
+			A:              a,
+			B:              b,
+			Difference:     difference(a, b),
+			SignTest:       signTest(pairs, m),
+			ComparedImages: len(pairs),
+		})
+	}
+
+	// Resample the images once per sample and score every metric on it
+	rng := rand.New(rand.NewSource(opts.Seed))
+	diffs := make([][]float64, len(metrics))
+	for i := 0; i < opts.Samples; i++ {
+		var sa, sb scoreSums
+		for range pairs {
+			k := rng.Intn(len(pairs))
+			sa.add(&pairs[k].A)
+			sb.add(&pairs[k].B)
+		}
+		for j, m := range metrics {
+			a, okA := m.value(&sa)
+			b, okB := m.value(&sb)
+			if okA && okB {
+				diffs[j] = append(diffs[j], difference(a, b))
+			}
+		}
+	}
+
+	alpha := 1 - opts.Confidence
+	for j := range comparisons {
+		c := &comparisons[j]
+		d := diffs[j]
+		if len(d) == 0 {
+			c.CILower, c.CIUpper, c.BootstrapP = c.Difference, c.Difference, 1
+			continue
+		}
+		sort.Float64s(d)
+		c.CILower = percentile(d, alpha/2)
+		c.CIUpper = percentile(d, 1-alpha/2)
+		c.BootstrapP = bootstrapP(d)
+		c.Significant = c.CILower > 0 || c.CIUpper < 0
+	}
+	return comparisons
+}
+
+// difference returns a - b, treating rounding noise as no difference
+func difference(a, b float64) float64 {
+	if d := a - b; math.Abs(d) > 1e-12 {
+		return d
+	}
+	return 0
+}
+
+// signTest compares a metric image by image, skipping images where it is
+// undefined for either system
+func signTest(pairs []PairedScore, m comparedMetric) entity.SignTest {
+	var t entity.SignTest
+	for k := range pairs {
+		var sa, sb scoreSums
+		sa.add(&pairs[k].A)
+		sb.add(&pairs[k].B)
+		a, okA := m.value(&sa)
+		b, okB := m.value(&sb)
+		switch {
+		case !okA || !okB:
+			continue
+		case difference(a, b) > 0:
+			t.Wins++
+		case difference(a, b) < 0:
+			t.Losses++
+		default:
+			t.Ties++
+		}
+	}
+	t.P = SignTestP(t.Wins, t.Losses)
+	return t
+}
+
+// SignTestP is the two-sided exact binomial p-value of observing wins and
+// losses under the hypothesis that both are equally likely
+func SignTestP(wins, losses int) float64 {
+	n := wins + losses
+	if n == 0 {
+		return 1
+	}
+	k := min(wins, losses)
+	lgN, _ := math.Lgamma(float64(n + 1))
+	var tail float64
+	for i := 0; i <= k; i++ {
+		lgI, _ := math.Lgamma(float64(i + 1))
+		lgNI, _ := math.Lgamma(float64(n - i + 1))
+		tail += math.Exp(lgN - lgI - lgNI - float64(n)*math.Ln2)
+	}
+	return math.Min(1, 2*tail)
+}
+
+// percentile returns the q-quantile of sorted values, interpolating linearly
+func percentile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := min(lo+1, len(sorted)-1)
+	return sorted[lo] + (pos-float64(lo))*(sorted[hi]-sorted[lo])
+}
+
+// bootstrapP is the two-sided p-value of a zero difference: twice the share
+// of bootstrap differences on the other side of zero than most of them
+func bootstrapP(sorted []float64) float64 {
+	var below, above int
+	for _, d := range sorted {
+		if d <= 0 {
+			below++
+		}
+		if d >= 0 {
+			above++
+		}
+	}
+	return math.Min(1, 2*float64(min(below, above))/float64(len(sorted)))
+}
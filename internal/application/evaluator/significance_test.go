@@ -0,0 +1,51 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignTestP(t *testing.T) {
+	assert.InDelta(t, 0.0625, SignTestP(5, 0), 1e-12)
+	assert.InDelta(t, 0.0625, SignTestP(0, 5), 1e-12)
+	assert.Equal(t, 1.0, SignTestP(3, 3))
+	assert.Equal(t, 1.0, SignTestP(0, 0))
+	// P(X <= 1) for n = 10 is 11/1024
+	assert.InDelta(t, 22.0/1024, SignTestP(9, 1), 1e-12)
+}
+
+func TestCompare(t *testing.T) {
+	var pairs []PairedScore
+	for i := 0; i < 40; i++ {
+		a := entity.EvaluationScore{TP: 9, FP: 1, FN: 1, Matched: 10, IoUSum: 8}
+		b := entity.EvaluationScore{TP: 6 + i%3, FP: 3, FN: 3, Matched: 9, IoUSum: 7.2}
+		pairs = append(pairs, PairedScore{A: a, B: b})
+	}
+
+	opts := CompareOptions{Samples: 500, Confidence: 0.95, Seed: 1}
+	metrics := Compare(pairs, opts)
+	require.Len(t, metrics, 4, "text metrics are undefined")
+
+	f1 := metrics[2]
+	assert.Equal(t, "f1", f1.Metric)
+	assert.InDelta(t, 0.9, f1.A, 1e-9)
+	assert.Greater(t, f1.Difference, 0.0)
+	assert.Greater(t, f1.CILower, 0.0)
+	assert.True(t, f1.Significant)
+	assert.Less(t, f1.BootstrapP, 0.05)
+	assert.Equal(t, 40, f1.SignTest.Wins)
+	assert.Less(t, f1.SignTest.P, 1e-6)
+
+	miou := metrics[3]
+	assert.Equal(t, "miou", miou.Metric)
+	assert.InDelta(t, 0, miou.Difference, 1e-9)
+	assert.Equal(t, 40, miou.SignTest.Ties)
+	assert.False(t, miou.Significant)
+
+	// The same seed gives the same intervals
+	again := Compare(pairs, opts)
+	assert.Equal(t, metrics, again)
+}
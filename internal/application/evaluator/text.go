@@ -0,0 +1,76 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// TextAccuracy compares the text of matched element pairs. Pairs where
+// neither element has text are skipped; texts are compared after trimming
+// and collapsing whitespace. It returns nil when no pair has text.
+func TextAccuracy(gt, pred []entity.Element, pairs []Pair) *entity.TextScore {
+	score := &entity.TextScore{}
+	for _, p := range pairs {
+		want, got := normalizeText(gt[p.GT].Text), normalizeText(pred[p.Pred].Text)
+		if want == "" && got == "" {
+			continue
+		}
+
+		wantRunes, gotRunes := []rune(want), []rune(got)
+		dist := EditDistance(wantRunes, gotRunes)
+		score.Pairs++
+		score.EditDistance += dist
+		score.GTChars += len(wantRunes)
+		score.NEDSum += float64(dist) / float64(max(len(wantRunes), len(gotRunes)))
+		if dist == 0 {
+			score.ExactMatches++
+		}
+	}
+	if score.Pairs == 0 {
+		return nil
+	}
+
+	score.TextMetrics = TextMetrics(score.Pairs, score.ExactMatches, score.EditDistance, score.GTChars, score.NEDSum)
+	return score
+}
+
+// TextMetrics computes the exact-match rate, mean normalized edit distance
+// and character error rate from summed counts
+func TextMetrics(pairs, exactMatches, editDistance, gtChars int, nedSum float64) entity.TextMetrics {
+	m := entity.TextMetrics{Pairs: pairs}
+	if pairs > 0 {
+		m.ExactMatch = float64(exactMatches) / float64(pairs)
+		m.NED = nedSum / float64(pairs)
+	}
+	if gtChars > 0 {
+		cer := float64(editDistance) / float64(gtChars)
+		m.CER = &cer
+	}
+	return m
+}
+
+// EditDistance is the Levenshtein distance between two strings
+func EditDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func normalizeText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,45 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, EditDistance([]rune("Submit"), []rune("Submit")))
+	assert.Equal(t, 1, EditDistance([]rune("Submit"), []rune("Submlt")))
+	assert.Equal(t, 3, EditDistance([]rune("kitten"), []rune("sitting")))
+	assert.Equal(t, 4, EditDistance(nil, []rune("Save")))
+	assert.Equal(t, 1, EditDistance([]rune("Đăng"), []rune("Dăng")))
+}
+
+func TestTextAccuracy(t *testing.T) {
+	withText := func(e entity.Element, text string) entity.Element {
+		e.Text = text
+		return e
+	}
+	gt := []entity.Element{
+		withText(el("button", 0, 0, 100, 40), "Submit"),
+		withText(el("input", 0, 100, 100, 40), "Email"),
+		el("icon", 0, 200, 20, 20),
+	}
+	pred := []entity.Element{
+		withText(el("button", 0, 0, 100, 40), " Submit "),
+		withText(el("input", 0, 100, 100, 40), "Emall"),
+		el("icon", 0, 200, 20, 20), // no text on either side
+	}
+
+	score := TextAccuracy(gt, pred, Match(gt, pred, DefaultIoUThreshold).Pairs)
+	require.NotNil(t, score)
+	assert.Equal(t, 2, score.Pairs)
+	assert.Equal(t, 1, score.ExactMatches)
+	assert.Equal(t, 0.5, score.ExactMatch)
+	assert.InDelta(t, 0.1, score.NED, 1e-9)
+	require.NotNil(t, score.CER)
+	assert.InDelta(t, 1.0/11, *score.CER, 1e-9)
+
+	assert.Nil(t, TextAccuracy(gt[2:], pred[2:], []Pair{{GT: 0, Pred: 0, IoU: 1}}))
+}
@@ -0,0 +1,71 @@
+package evaluator
+
+import (
+	"sort"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// Weights of the signals in the uncertainty score
+const (
+	DisagreementWeight = 0.5
+	ConfidenceWeight   = 0.3
+	DensityWeight      = 0.2
+)
+
+// Uncertainty measures the disagreement and confidence of the predictions of
+// an image by model. The models are compared pairwise like annotators.
+func Uncertainty(predictions map[string][]entity.Element, threshold float64) entity.ImageUncertainty {
+	u := entity.ImageUncertainty{Models: []string{}}
+	elements, confident, confidenceSum := 0, 0, 0.0
+	for model, pred := range predictions {
+		u.Models = append(u.Models, model)
+		elements += len(pred)
+		for _, el := range pred {
+			if el.Confidence > 0 {
+				confident++
+				confidenceSum += el.Confidence
+			}
+		}
+	}
+	sort.Strings(u.Models)
+
+	if len(predictions) > 0 {
+		u.Elements = float64(elements) / float64(len(predictions))
+	}
+	if confident > 0 {
+		mean := confidenceSum / float64(confident)
+		u.MeanConfidence = &mean
+	}
+	if len(predictions) > 1 {
+		agreement, _ := ImageAgreement(predictions, threshold)
+		disagreement := 1 - agreement.Agreement
+		u.Disagreement = &disagreement
+	}
+	return u
+}
+
+// RankUncertainty scores images by uncertainty and sorts them most uncertain
+// first. Density is relative to the image with the most elements; unknown
+// disagreement or confidence count as 0.5.
+func RankUncertainty(images []*entity.ImageUncertainty) {
+	densest := 0.0
+	for _, u := range images {
+		densest = max(densest, u.Elements)
+	}
+	for _, u := range images {
+		u.Density = 0
+		if densest > 0 {
+			u.Density = u.Elements / densest
+		}
+		disagreement, lowConfidence := 0.5, 0.5
+		if u.Disagreement != nil {
+			disagreement = *u.Disagreement
+		}
+		if u.MeanConfidence != nil {
+			lowConfidence = 1 - *u.MeanConfidence
+		}
+		u.Score = DisagreementWeight*disagreement + ConfidenceWeight*lowConfidence + DensityWeight*u.Density
+	}
+	sort.SliceStable(images, func(i, j int) bool { return images[i].Score > images[j].Score })
+}
@@ -0,0 +1,45 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUncertainty(t *testing.T) {
+	button := el("button", 0, 0, 100, 40)
+	button.Confidence = 0.9
+	link := el("link", 0, 0, 100, 40)
+	link.Confidence = 0.5
+
+	u := Uncertainty(map[string][]entity.Element{
+		"gpt":    {button, el("text", 0, 100, 100, 20)},
+		"claude": {link},
+	}, DefaultIoUThreshold)
+	assert.Equal(t, []string{"claude", "gpt"}, u.Models)
+	assert.Equal(t, 1.5, u.Elements)
+	require.NotNil(t, u.Disagreement)
+	assert.Equal(t, 1.0, *u.Disagreement, "no element with the same class")
+	require.NotNil(t, u.MeanConfidence)
+	assert.InDelta(t, 0.7, *u.MeanConfidence, 1e-9)
+
+	single := Uncertainty(map[string][]entity.Element{"gpt": {el("text", 0, 0, 10, 10)}}, DefaultIoUThreshold)
+	assert.Nil(t, single.Disagreement)
+	assert.Nil(t, single.MeanConfidence)
+}
+
+func TestRankUncertainty(t *testing.T) {
+	agree, disagree, sure := 0.0, 1.0, 1.0
+	calm := &entity.ImageUncertainty{Elements: 10, Disagreement: &agree, MeanConfidence: &sure}
+	contested := &entity.ImageUncertainty{Elements: 5, Disagreement: &disagree, MeanConfidence: &sure}
+	unknown := &entity.ImageUncertainty{}
+
+	images := []*entity.ImageUncertainty{calm, unknown, contested}
+	RankUncertainty(images)
+	assert.Equal(t, []*entity.ImageUncertainty{contested, unknown, calm}, images)
+	assert.InDelta(t, 0.5+0.2*0.5, contested.Score, 1e-9)
+	assert.InDelta(t, 0.5*0.5+0.3*0.5, unknown.Score, 1e-9)
+	assert.InDelta(t, 0.2, calm.Score, 1e-9)
+}
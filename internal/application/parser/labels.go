@@ -0,0 +1,104 @@
+package parser
+
+import "strings"
+
+// Taxonomy lists the element types of our schema. Labels that cannot be
+// mapped onto one of them become "other".
+var Taxonomy = []string{
+	"button", "input", "link", "text", "image", "icon", "checkbox", "radio",
+	"select", "toggle", "slider", "tab", "menu", "card", "container", "other",
+}
+
+// labelAliases maps the labels models commonly use onto the taxonomy
+var labelAliases = map[string]string{
+	"btn":            "button",
+	"icon_button":    "button",
+	"submit":         "button",
+	"cta":            "button",
+	"textbox":        "input",
+	"text_box":       "input",
+	"textfield":      "input",
+	"text_field":     "input",
+	"text_input":     "input",
+	"input_field":    "input",
+	"textarea":       "input",
+	"text_area":      "input",
+	"search":         "input",
+	"search_bar":     "input",
+	"searchbox":      "input",
+	"edit_text":      "input",
+	"hyperlink":      "link",
+	"a":              "link",
+	"anchor":         "link",
+	"label":          "text",
+	"paragraph":      "text",
+	"heading":        "text",
+	"title":          "text",
+	"static_text":    "text",
+	"span":           "text",
+	"h1":             "text",
+	"h2":             "text",
+	"h3":             "text",
+	"h4":             "text",
+	"h5":             "text",
+	"h6":             "text",
+	"img":            "image",
+	"picture":        "image",
+	"photo":          "image",
+	"logo":           "image",
+	"avatar":         "image",
+	"check_box":      "checkbox",
+	"radio_button":   "radio",
+	"radiobutton":    "radio",
+	"dropdown":       "select",
+	"drop_down":      "select",
+	"combobox":       "select",
+	"combo_box":      "select",
+	"picker":         "select",
+	"switch":         "toggle",
+	"range":          "slider",
+	"seekbar":        "slider",
+	"tabs":           "tab",
+	"tab_bar":        "tab",
+	"navbar":         "menu",
+	"nav":            "menu",
+	"navigation":     "menu",
+	"navigation_bar": "menu",
+	"menu_item":      "menu",
+	"div":            "container",
+	"section":        "container",
+	"panel":          "container",
+	"modal":          "container",
+	"dialog":         "container",
+}
+
+// NormalizeLabel maps a model label onto the taxonomy, consulting the
+// per-model aliases first
+func NormalizeLabel(label string, aliases map[string]string) string {
+	key := strings.ToLower(strings.TrimSpace(label))
+	key = strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+
+	// Try the full label first, then its words from last to first, so that
+	// "submit_button" maps onto "button"
+	candidates := []string{key, strings.ReplaceAll(key, "_", "")}
+	if words := strings.Split(key, "_"); len(words) > 1 {
+		for i := len(words) - 1; i >= 0; i-- {
+			candidates = append(candidates, words[i])
+		}
+	}
+
+	for _, candidate := range candidates {
+		if v, ok := aliases[candidate]; ok {
+			return v
+		}
+		if v, ok := labelAliases[candidate]; ok {
+			return v
+		}
+		for _, t := range Taxonomy {
+			if candidate == t {
+				return t
+			}
+		}
+	}
+	return "other"
+}
@@ -0,0 +1,411 @@
+// Package parser normalizes raw model outputs into the element schema. Each
+// model gets a Parser configured for its coordinate conventions and label
+// vocabulary; all parsers share the same JSON extraction and repair steps.
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// BoxFormat describes how a 4-number coordinate array is laid out
+type BoxFormat string
+
+const (
+	// FormatXYWH is [x, y, width, height]
+	FormatXYWH BoxFormat = "xywh"
+	// FormatXYXY is [x_min, y_min, x_max, y_max]
+	FormatXYXY BoxFormat = "xyxy"
+	// FormatYXYX is [y_min, x_min, y_max, x_max], as returned by Gemini
+	FormatYXYX BoxFormat = "yxyx"
+)
+
+// Config holds the conventions of a single model
+type Config struct {
+	// ArrayFormat is how 4-number coordinate arrays are interpreted
+	ArrayFormat BoxFormat
+	// Scale is the coordinate range of normalized outputs, e.g. 1 or 1000.
+	// Zero auto-detects: coordinates all within [0, 1] are treated as normalized.
+	Scale float64
+	// Labels are model-specific label aliases applied before the defaults
+	Labels map[string]string
+}
+
+// Parser turns the raw output of one model into elements
+type Parser struct {
+	Name string
+	cfg  Config
+}
+
+// Result is the outcome of a successful parse. Warnings explain why invalid
+// elements were skipped.
+type Result struct {
+	Elements []entity.Element
+	Repairs  []string
+	Warnings []string
+}
+
+var (
+	mu      sync.RWMutex
+	parsers = map[string]*Parser{
+		"gpt":    {Name: "gpt", cfg: Config{ArrayFormat: FormatXYWH}},
+		"claude": {Name: "claude", cfg: Config{ArrayFormat: FormatXYWH}},
+		"gemini": {Name: "gemini", cfg: Config{ArrayFormat: FormatYXYX, Scale: 1000}},
+	}
+	defaultParser = &Parser{Name: "default", cfg: Config{ArrayFormat: FormatXYWH}}
+)
+
+// Register installs the parser configuration used for a model
+func Register(model string, cfg Config) {
+	if cfg.ArrayFormat == "" {
+		cfg.ArrayFormat = FormatXYWH
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	parsers[model] = &Parser{Name: model, cfg: cfg}
+}
+
+// ForModel returns the parser registered for a model, or the default parser
+func ForModel(model string) *Parser {
+	mu.RLock()
+	defer mu.RUnlock()
+	if p, ok := parsers[model]; ok {
+		return p
+	}
+	return defaultParser
+}
+
+var (
+	listKeys       = []string{"elements", "ui_elements", "components", "objects", "detections", "items", "annotations", "predictions", "results"}
+	typeKeys       = []string{"type", "element_type", "elementType", "class", "category", "kind", "role"}
+	textKeys       = []string{"text", "content", "label_text", "value", "placeholder", "caption"}
+	confidenceKeys = []string{"confidence", "score", "conf", "probability"}
+	boxKeys        = []string{"bbox", "box", "bounding_box", "boundingBox", "box_2d", "rect", "bounds", "position", "coordinates"}
+)
+
+// Parse extracts, repairs and normalizes the elements of a raw model output.
+// width and height are the image dimensions used to scale normalized
+// coordinates; pass zero when unknown. Invalid elements are skipped with a
+// warning; the parse only fails when no element is valid.
+func (p *Parser) Parse(raw string, width, height int) (*Result, error) {
+	doc, repairs, err := extractJSON(raw)
+	if err != nil {
+		return nil, fmt.Errorf("no JSON found: %w", err)
+	}
+
+	var v any
+	if err := json.Unmarshal([]byte(doc), &v); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	items, err := elementList(v)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Elements: make([]entity.Element, 0, len(items)), Repairs: repairs}
+	unscaled := false
+	for i, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("element %d is not an object", i))
+			continue
+		}
+		el, scaled, err := p.element(obj, width, height)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("element %d: %v", i, err))
+			continue
+		}
+		unscaled = unscaled || !scaled
+		result.Elements = append(result.Elements, el)
+	}
+	if unscaled {
+		result.Repairs = append(result.Repairs, RepairUnscaled)
+	}
+	if len(result.Warnings) > 0 {
+		if len(result.Elements) == 0 {
+			return nil, errors.New(result.Warnings[0])
+		}
+		result.Repairs = append(result.Repairs, RepairSkippedElements)
+	}
+	return result, nil
+}
+
+// elementList finds the list of elements in a decoded document
+func elementList(v any) ([]any, error) {
+	switch doc := v.(type) {
+	case []any:
+		return doc, nil
+	case map[string]any:
+		for _, k := range listKeys {
+			if list, ok := doc[k].([]any); ok {
+				return list, nil
+			}
+		}
+		// Fall back to the only array in the object, if any
+		var found []any
+		arrays := 0
+		for _, val := range doc {
+			if list, ok := val.([]any); ok {
+				found = list
+				arrays++
+			}
+		}
+		if arrays == 1 {
+			return found, nil
+		}
+		// A single element object
+		if firstString(doc, typeKeys) != "" {
+			return []any{doc}, nil
+		}
+	}
+	return nil, errors.New("no element list found")
+}
+
+// element normalizes one element. scaled is false when its coordinates are
+// left in the model's normalized range because the image size is unknown.
+func (p *Parser) element(obj map[string]any, width, height int) (el entity.Element, scaled bool, err error) {
+	typeLabel := firstString(obj, typeKeys)
+	text := firstString(obj, textKeys)
+	if typeLabel == "" {
+		typeLabel = firstString(obj, []string{"label", "name"})
+	} else if text == "" {
+		// With an explicit type, "label" is the element's text
+		text = firstString(obj, []string{"label"})
+	}
+	if typeLabel == "" {
+		return entity.Element{}, false, errors.New("missing element type")
+	}
+
+	box, scaled, err := p.box(obj, width, height)
+	if err != nil {
+		return entity.Element{}, false, err
+	}
+
+	el = entity.Element{
+		Type:   NormalizeLabel(typeLabel, p.cfg.Labels),
+		Text:   text,
+		BBox:   box,
+		Source: firstString(obj, []string{"source"}),
+	}
+	for _, k := range confidenceKeys {
+		if c, ok := toFloat(obj[k]); ok {
+			// Percentages are converted to [0, 1]
+			if c > 1 && c <= 100 {
+				c /= 100
+			}
+			el.Confidence = c
+			break
+		}
+	}
+	return el, scaled, nil
+}
+
+// box extracts the bounding box of an element and converts it to pixels.
+// Coordinates of a fixed normalized range, e.g. Gemini's 0-1000, are kept
+// as is when the image size is unknown, with scaled false.
+func (p *Parser) box(obj map[string]any, width, height int) (entity.BBox, bool, error) {
+	var (
+		raw   any = obj
+		key   string
+		xyxy  bool
+		coord [4]float64
+		ok    bool
+	)
+	for _, k := range boxKeys {
+		if v, found := obj[k]; found {
+			raw, key = v, k
+			break
+		}
+	}
+
+	format, scale := p.cfg.ArrayFormat, p.cfg.Scale
+	if key == "box_2d" {
+		format, scale = FormatYXYX, 1000
+	}
+
+	switch b := raw.(type) {
+	case []any:
+		coord, ok = arrayCoords(b)
+		if !ok {
+			return entity.BBox{}, false, fmt.Errorf("invalid %s coordinates", key)
+		}
+		switch format {
+		case FormatXYXY:
+			xyxy = true
+		case FormatYXYX:
+			coord = [4]float64{coord[1], coord[0], coord[3], coord[2]}
+			xyxy = true
+		}
+		if len(b) == 2 {
+			// [[x1, y1], [x2, y2]]
+			xyxy = true
+		}
+	case map[string]any:
+		coord, xyxy, ok = objectCoords(b)
+		if !ok {
+			return entity.BBox{}, false, errors.New("missing bounding box")
+		}
+	default:
+		return entity.BBox{}, false, errors.New("missing bounding box")
+	}
+
+	scaled := true
+	if scale == 0 && isNormalized(coord) {
+		scale = 1
+	} else if scale == 1 && !isNormalized(coord) {
+		scale = 0
+	}
+	if scale > 0 && (width <= 0 || height <= 0) {
+		// Coordinates within [0, 1] mean nothing without the image size
+		if scale == 1 {
+			return entity.BBox{}, false, errors.New("normalized coordinates require the image size")
+		}
+		scale, scaled = 0, false
+	}
+	if scale > 0 {
+		coord[0] = coord[0] / scale * float64(width)
+		coord[1] = coord[1] / scale * float64(height)
+		coord[2] = coord[2] / scale * float64(width)
+		coord[3] = coord[3] / scale * float64(height)
+	}
+
+	var x1, y1, x2, y2 float64
+	if xyxy {
+		x1, y1, x2, y2 = coord[0], coord[1], coord[2], coord[3]
+	} else {
+		x1, y1, x2, y2 = coord[0], coord[1], coord[0]+coord[2], coord[1]+coord[3]
+	}
+	// Swapped corners or negative sizes
+	x1, x2 = math.Min(x1, x2), math.Max(x1, x2)
+	y1, y2 = math.Min(y1, y2), math.Max(y1, y2)
+	if width > 0 && height > 0 {
+		x1, x2 = clamp(x1, 0, float64(width)), clamp(x2, 0, float64(width))
+		y1, y2 = clamp(y1, 0, float64(height)), clamp(y2, 0, float64(height))
+	}
+
+	return entity.BBox{X: round(x1), Y: round(y1), Width: round(x2 - x1), Height: round(y2 - y1)}, scaled, nil
+}
+
+func arrayCoords(b []any) ([4]float64, bool) {
+	var coord [4]float64
+	switch len(b) {
+	case 4:
+		for i, v := range b {
+			f, ok := toFloat(v)
+			if !ok {
+				return coord, false
+			}
+			coord[i] = f
+		}
+		return coord, true
+	case 2:
+		for i, v := range b {
+			point, ok := v.([]any)
+			if !ok || len(point) != 2 {
+				return coord, false
+			}
+			x, okX := toFloat(point[0])
+			y, okY := toFloat(point[1])
+			if !okX || !okY {
+				return coord, false
+			}
+			coord[2*i], coord[2*i+1] = x, y
+		}
+		return coord, true
+	}
+	return coord, false
+}
+
+// objectCoords reads the coordinate keys models use for boxes. xyxy reports
+// whether the coordinates are two corners rather than origin and size.
+func objectCoords(b map[string]any) (coord [4]float64, xyxy bool, ok bool) {
+	corners := [][4]string{
+		{"x1", "y1", "x2", "y2"},
+		{"xmin", "ymin", "xmax", "ymax"},
+		{"x_min", "y_min", "x_max", "y_max"},
+		{"left", "top", "right", "bottom"},
+	}
+	for _, keys := range corners {
+		if c, found := floats(b, keys); found {
+			return c, true, true
+		}
+	}
+
+	sizes := [][4]string{
+		{"x", "y", "width", "height"},
+		{"x", "y", "w", "h"},
+		{"left", "top", "width", "height"},
+	}
+	for _, keys := range sizes {
+		if c, found := floats(b, keys); found {
+			return c, false, true
+		}
+	}
+
+	// A bare position without size, as in legacy ground truth
+	if c, found := floats(b, [4]string{"x", "y", "x", "y"}); found {
+		return [4]float64{c[0], c[1], 0, 0}, false, true
+	}
+	return coord, false, false
+}
+
+func floats(b map[string]any, keys [4]string) ([4]float64, bool) {
+	var c [4]float64
+	for i, k := range keys {
+		f, ok := toFloat(b[k])
+		if !ok {
+			return c, false
+		}
+		c[i] = f
+	}
+	return c, true
+}
+
+func isNormalized(c [4]float64) bool {
+	fractional := false
+	for _, v := range c {
+		if v < 0 || v > 1 {
+			return false
+		}
+		if v > 0 && v < 1 {
+			fractional = true
+		}
+	}
+	return fractional
+}
+
+func firstString(obj map[string]any, keys []string) string {
+	for _, k := range keys {
+		if s, ok := obj[k].(string); ok && strings.TrimSpace(s) != "" {
+			return strings.TrimSpace(s)
+		}
+	}
+	return ""
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(n), "px"), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,194 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParser_Parse(t *testing.T) {
+	button := entity.Element{Type: "button", Text: "Submit", BBox: entity.BBox{X: 100, Y: 200, Width: 80, Height: 40}, Confidence: 0.95}
+
+	tests := []struct {
+		name    string
+		model   string
+		raw     string
+		want    []entity.Element
+		repairs []string
+	}{
+		{
+			name:  "plain xywh object",
+			model: "gpt",
+			raw:   `{"elements":[{"type":"button","text":"Submit","bbox":{"x":100,"y":200,"width":80,"height":40},"confidence":0.95}]}`,
+			want:  []entity.Element{button},
+		},
+		{
+			name:    "markdown fence with prose",
+			model:   "claude",
+			raw:     "Here are the elements:\n```json\n[{\"label\":\"Button\",\"text\":\"Submit\",\"bbox\":[100,200,80,40],\"score\":95}]\n```\nLet me know!",
+			want:    []entity.Element{button},
+			repairs: []string{RepairCodeFence},
+		},
+		{
+			name:    "trailing commas and python literals",
+			model:   "gpt",
+			raw:     `{'elements': [{'type': 'btn', 'text': 'Submit', 'x1': 100, 'y1': 200, 'x2': 180, 'y2': 240, 'confidence': 0.95, 'visible': True,},],}`,
+			want:    []entity.Element{button},
+			repairs: []string{RepairSingleQuotes, RepairPythonLiterals, RepairTrailingCommas},
+		},
+		{
+			name:    "truncated output keeps complete elements",
+			model:   "gpt",
+			raw:     `{"elements":[{"type":"button","text":"Submit","bbox":{"x":100,"y":200,"width":80,"height":40},"confidence":0.95},{"type":"inp`,
+			want:    []entity.Element{button},
+			repairs: []string{RepairTruncated},
+		},
+		{
+			name:  "normalized coordinates",
+			model: "gpt",
+			raw:   `[{"type":"button","text":"Submit","bbox":{"x":0.1,"y":0.25,"w":0.08,"h":0.05},"confidence":0.95}]`,
+			want:  []entity.Element{button},
+		},
+		{
+			name:  "gemini box_2d",
+			model: "gemini",
+			raw:   `[{"label":"submit button","box_2d":[250,100,300,180]}]`,
+			want:  []entity.Element{{Type: "button", BBox: entity.BBox{X: 100, Y: 200, Width: 80, Height: 40}}},
+		},
+		{
+			name:  "legacy position without size",
+			model: "unknown",
+			raw:   `{"elements":[{"type":"Text Field","placeholder":"Enter text","position":{"x":100,"y":150}}]}`,
+			want:  []entity.Element{{Type: "input", Text: "Enter text", BBox: entity.BBox{X: 100, Y: 150}}},
+		},
+		{
+			name:  "pre-labeled ground truth keeps its source",
+			model: "",
+			raw:   `{"elements":[{"type":"button","text":"Submit","bbox":{"x":100,"y":200,"width":80,"height":40},"confidence":0.95,"source":"claude"}]}`,
+			want:  []entity.Element{{Type: "button", Text: "Submit", BBox: button.BBox, Confidence: 0.95, Source: "claude"}},
+		},
+		{
+			name:  "draft document",
+			model: "",
+			raw: `{"models":["gpt","claude"],"min_votes":2,"created_at":"2024-01-01T00:00:00Z","elements":[
+				{"type":"button","text":"Submit","bbox":{"x":100,"y":200,"width":80,"height":40},"confidence":0.95,"source":"ensemble"},
+				{"type":"link","bbox":{"x":10,"y":10,"width":40,"height":20},"source":"gpt"},
+				{"type":"icon","bbox":{"x":50,"y":50,"width":10,"height":10}}
+			]}`,
+			want: []entity.Element{
+				{Type: "button", Text: "Submit", BBox: button.BBox, Confidence: 0.95, Source: "ensemble"},
+				{Type: "link", BBox: entity.BBox{X: 10, Y: 10, Width: 40, Height: 20}, Source: "gpt"},
+				{Type: "icon", BBox: entity.BBox{X: 50, Y: 50, Width: 10, Height: 10}},
+			},
+		},
+		{
+			name:  "source with a model parser",
+			model: "gemini",
+			raw:   `[{"label":"submit button","box_2d":[250,100,300,180],"source":" gemini "}]`,
+			want:  []entity.Element{{Type: "button", BBox: entity.BBox{X: 100, Y: 200, Width: 80, Height: 40}, Source: "gemini"}},
+		},
+		{
+			name:  "non-string and blank sources are ignored",
+			model: "",
+			raw:   `[{"type":"button","bbox":[10,10,20,20],"source":42},{"type":"link","bbox":[50,50,10,10],"source":"  "},{"type":"icon","bbox":[70,70,5,5],"source":null}]`,
+			want: []entity.Element{
+				{Type: "button", BBox: entity.BBox{X: 10, Y: 10, Width: 20, Height: 20}},
+				{Type: "link", BBox: entity.BBox{X: 50, Y: 50, Width: 10, Height: 10}},
+				{Type: "icon", BBox: entity.BBox{X: 70, Y: 70, Width: 5, Height: 5}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ForModel(tt.model).Parse(tt.raw, 1000, 800)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result.Elements)
+			for _, r := range tt.repairs {
+				assert.Contains(t, result.Repairs, r)
+			}
+		})
+	}
+}
+
+func TestParser_SkipsInvalidElements(t *testing.T) {
+	raw := `[{"type":"button","bbox":[10,10,20,20]},{"type":"link"},"oops",{"type":"icon","bbox":[50,50,10,10]}]`
+
+	result, err := ForModel("gpt").Parse(raw, 100, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Element{
+		{Type: "button", BBox: entity.BBox{X: 10, Y: 10, Width: 20, Height: 20}},
+		{Type: "icon", BBox: entity.BBox{X: 50, Y: 50, Width: 10, Height: 10}},
+	}, result.Elements)
+	assert.Contains(t, result.Repairs, RepairSkippedElements)
+	assert.Equal(t, []string{"element 1: missing bounding box", "element 2 is not an object"}, result.Warnings)
+}
+
+func TestParser_DraftWithInvalidElements(t *testing.T) {
+	raw := `{"models":["gpt"],"elements":[{"type":"button","source":"gpt"},{"bbox":[10,10,20,20],"source":"gpt"},{"type":"link","bbox":[50,50,10,10],"source":"gpt"}]}`
+
+	result, err := ForModel("").Parse(raw, 100, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Element{{Type: "link", BBox: entity.BBox{X: 50, Y: 50, Width: 10, Height: 10}, Source: "gpt"}}, result.Elements)
+	assert.Equal(t, []string{RepairSkippedElements}, result.Repairs)
+	assert.Equal(t, []string{"element 0: missing bounding box", "element 1: missing element type"}, result.Warnings)
+
+	_, err = ForModel("").Parse(`{"models":["gpt"],"elements":[{"type":"button","source":"gpt"}]}`, 100, 100)
+	assert.EqualError(t, err, "element 0: missing bounding box")
+	_, err = ForModel("").Parse(`{"models":["gpt"],"min_votes":0}`, 100, 100)
+	assert.Error(t, err, "a draft without elements")
+}
+
+func TestParser_UnknownImageSize(t *testing.T) {
+	raw := `[{"label":"button","box_2d":[100,200,300,600]}]`
+
+	result, err := ForModel("gemini").Parse(raw, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Element{{Type: "button", BBox: entity.BBox{X: 200, Y: 100, Width: 400, Height: 200}}}, result.Elements)
+	assert.Equal(t, []string{RepairUnscaled}, result.Repairs)
+	assert.Empty(t, result.Warnings)
+
+	result, err = ForModel("gemini").Parse(raw, 2000, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, entity.BBox{X: 400, Y: 100, Width: 800, Height: 200}, result.Elements[0].BBox)
+	assert.Empty(t, result.Repairs)
+}
+
+func TestParser_ParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		width  int
+		height int
+	}{
+		{name: "empty", raw: "  "},
+		{name: "no json", raw: "I cannot label this image."},
+		{name: "missing box", raw: `[{"type":"button"}]`, width: 100, height: 100},
+		{name: "normalized without image size", raw: `[{"type":"button","bbox":[0.1,0.1,0.2,0.2]}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ForModel("gpt").Parse(tt.raw, tt.width, tt.height)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("custom", Config{ArrayFormat: FormatXYXY, Labels: map[string]string{"cta": "link"}})
+	result, err := ForModel("custom").Parse(`[{"type":"cta","bbox":[10,10,30,20]}]`, 100, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Element{{Type: "link", BBox: entity.BBox{X: 10, Y: 10, Width: 20, Height: 10}}}, result.Elements)
+}
+
+func TestNormalizeLabel(t *testing.T) {
+	assert.Equal(t, "button", NormalizeLabel("Button", nil))
+	assert.Equal(t, "input", NormalizeLabel("text-field", nil))
+	assert.Equal(t, "input", NormalizeLabel("TextField", nil))
+	assert.Equal(t, "select", NormalizeLabel("Drop Down", nil))
+	assert.Equal(t, "button", NormalizeLabel("primary submit button", nil))
+	assert.Equal(t, "other", NormalizeLabel("spaceship", nil))
+}
@@ -0,0 +1,247 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Repairs applied to model outputs, reported alongside the parsed elements
+const (
+	RepairCodeFence      = "stripped_code_fence"
+	RepairSurroundedText = "stripped_surrounding_text"
+	RepairSmartQuotes    = "replaced_smart_quotes"
+	RepairSingleQuotes   = "replaced_single_quotes"
+	RepairPythonLiterals = "replaced_python_literals"
+	RepairTrailingCommas = "removed_trailing_commas"
+	RepairComments       = "removed_comments"
+	RepairTruncated      = "closed_truncated_json"
+	// RepairSkippedElements reports invalid elements left out of the result,
+	// detailed in Result.Warnings
+	RepairSkippedElements = "skipped_invalid_elements"
+	// RepairUnscaled reports coordinates left in the model's normalized range,
+	// e.g. Gemini's 0-1000, because the image size was unknown
+	RepairUnscaled = "left_coordinates_unscaled"
+)
+
+var codeFence = regexp.MustCompile("(?s)```[a-zA-Z]*[ \t]*\n?(.*?)(?:```|$)")
+
+var smartQuotes = strings.NewReplacer("“", `"`, "”", `"`, "‘", "'", "’", "'")
+
+// maxStartCandidates bounds how many '{' / '[' positions are tried as the
+// start of the JSON document
+const maxStartCandidates = 8
+
+// extractJSON locates the JSON document in a model output and repairs the
+// common defects of LLM answers. It returns the repaired document and the
+// list of repairs applied.
+func extractJSON(raw string) (string, []string, error) {
+	s := strings.TrimSpace(strings.TrimPrefix(raw, "\ufeff"))
+	if s == "" {
+		return "", nil, errors.New("empty output")
+	}
+
+	var repairs []string
+	if m := codeFence.FindStringSubmatch(s); m != nil && strings.ContainsAny(m[1], "{[") {
+		s = strings.TrimSpace(m[1])
+		repairs = append(repairs, RepairCodeFence)
+	}
+
+	if replaced := smartQuotes.Replace(s); replaced != s {
+		s = replaced
+		repairs = append(repairs, RepairSmartQuotes)
+	}
+
+	var lastErr error = errors.New("no JSON object or array found")
+	tried := 0
+	for i := 0; i < len(s) && tried < maxStartCandidates; i++ {
+		if s[i] != '{' && s[i] != '[' {
+			continue
+		}
+		tried++
+
+		doc, docRepairs, trailing, err := repairFrom(s[i:])
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if i > 0 || trailing {
+			docRepairs = append([]string{RepairSurroundedText}, docRepairs...)
+		}
+		return doc, append(repairs, docRepairs...), nil
+	}
+	return "", nil, lastErr
+}
+
+// repairFrom repairs the JSON document starting at s[0]. trailing reports
+// whether non-JSON text followed the document.
+func repairFrom(s string) (doc string, repairs []string, trailing bool, err error) {
+	normalized, repairs := normalizeTokens(s)
+
+	end, closers, lastSafe := scanDocument(normalized)
+	switch {
+	case end >= 0:
+		doc = normalized[:end]
+		trailing = strings.TrimSpace(normalized[end:]) != ""
+	case lastSafe > 0:
+		doc = strings.TrimRight(strings.TrimSpace(normalized[:lastSafe]), ",") + closers
+		repairs = append(repairs, RepairTruncated)
+	default:
+		return "", nil, false, errors.New("truncated JSON without any complete element")
+	}
+
+	// Closing a truncated document can leave a dangling comma
+	doc, _ = normalizeTokens(doc)
+	if !json.Valid([]byte(doc)) {
+		var v any
+		err := json.Unmarshal([]byte(doc), &v)
+		return "", nil, false, err
+	}
+	return doc, repairs, trailing, nil
+}
+
+// normalizeTokens rewrites the non-standard tokens LLMs commonly emit outside
+// of strings: single-quoted strings, Python literals, trailing commas and
+// comments
+func normalizeTokens(s string) (string, []string) {
+	var b strings.Builder
+	b.Grow(len(s))
+	applied := map[string]bool{}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			j, _ := stringEnd(s, i, '"')
+			b.WriteString(s[i:j])
+			i = j - 1
+		case c == '\'':
+			j, ok := stringEnd(s, i, '\'')
+			if !ok {
+				b.WriteByte(c)
+				continue
+			}
+			inner := strings.ReplaceAll(s[i+1:j-1], `\'`, "'")
+			inner = strings.ReplaceAll(inner, `"`, `\"`)
+			b.WriteString(`"` + inner + `"`)
+			applied[RepairSingleQuotes] = true
+			i = j - 1
+		case c == ',':
+			k := skipSpace(s, i+1)
+			if k < len(s) && (s[k] == '}' || s[k] == ']') {
+				applied[RepairTrailingCommas] = true
+				continue
+			}
+			b.WriteByte(c)
+		case c == '/' && i+1 < len(s) && s[i+1] == '/':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+			applied[RepairComments] = true
+		case isIdentStart(c):
+			j := i
+			for j < len(s) && isIdentStart(s[j]) {
+				j++
+			}
+			word := s[i:j]
+			switch word {
+			case "True":
+				word = "true"
+			case "False":
+				word = "false"
+			case "None":
+				word = "null"
+			}
+			if word != s[i:j] {
+				applied[RepairPythonLiterals] = true
+			}
+			b.WriteString(word)
+			i = j - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	var repairs []string
+	for _, r := range []string{RepairSingleQuotes, RepairPythonLiterals, RepairTrailingCommas, RepairComments} {
+		if applied[r] {
+			repairs = append(repairs, r)
+		}
+	}
+	return b.String(), repairs
+}
+
+// scanDocument finds the end of the JSON value starting at s[0]. When the
+// value is unterminated it returns end = -1, the position right after the
+// last complete nested value, and the closers needed at that position.
+func scanDocument(s string) (end int, closers string, lastSafe int) {
+	var stack []byte
+	end = -1
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			j, ok := stringEnd(s, i, '"')
+			if !ok {
+				// Unterminated string: everything from here is lost
+				return -1, closers, lastSafe
+			}
+			i = j - 1
+		case '{', '[':
+			stack = append(stack, c)
+		case '}', ']':
+			if len(stack) == 0 {
+				return -1, closers, lastSafe
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return i + 1, "", lastSafe
+			}
+			// Only cut inside arrays, so that no object is left with a
+			// dangling key
+			if stack[len(stack)-1] == '[' {
+				lastSafe = i + 1
+				closers = closersFor(stack)
+			}
+		}
+	}
+	return -1, closers, lastSafe
+}
+
+func closersFor(stack []byte) string {
+	var b strings.Builder
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i] == '{' {
+			b.WriteByte('}')
+		} else {
+			b.WriteByte(']')
+		}
+	}
+	return b.String()
+}
+
+// stringEnd returns the index right after the closing quote of the string
+// starting at s[start]. ok is false when the string is unterminated, in which
+// case len(s) is returned.
+func stringEnd(s string, start int, quote byte) (end int, ok bool) {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1, true
+		}
+	}
+	return len(s), false
+}
+
+func skipSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n' || s[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+func isIdentStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
@@ -0,0 +1,147 @@
+// Package render draws annotations and predictions over screenshots. Boxes
+// are outlined in the color of their element type and labeled with it, so
+// overlays of the same type look the same across reports.
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Layer is a set of elements drawn over the screenshot. Dashed layers are
+// told apart from solid ones, e.g. predictions from ground truth.
+type Layer struct {
+	Name     string
+	Elements []entity.Element
+	Dashed   bool
+}
+
+// palette gives each type of the taxonomy a color dark enough for white
+// label text
+var palette = map[string]color.RGBA{
+	"button":    {R: 0xe6, G: 0x19, B: 0x4b, A: 0xff},
+	"input":     {R: 0x3c, G: 0x8d, B: 0x2f, A: 0xff},
+	"link":      {R: 0x43, G: 0x63, B: 0xd8, A: 0xff},
+	"text":      {R: 0xc2, G: 0x5e, B: 0x00, A: 0xff},
+	"image":     {R: 0x91, G: 0x1e, B: 0xb4, A: 0xff},
+	"icon":      {R: 0x00, G: 0x80, B: 0x80, A: 0xff},
+	"checkbox":  {R: 0xb0, G: 0x30, B: 0x90, A: 0xff},
+	"radio":     {R: 0x6b, G: 0x8e, B: 0x23, A: 0xff},
+	"select":    {R: 0x80, G: 0x00, B: 0x00, A: 0xff},
+	"toggle":    {R: 0x00, G: 0x00, B: 0x80, A: 0xff},
+	"slider":    {R: 0x80, G: 0x80, B: 0x00, A: 0xff},
+	"tab":       {R: 0x9a, G: 0x63, B: 0x24, A: 0xff},
+	"menu":      {R: 0xd0, G: 0x20, B: 0x90, A: 0xff},
+	"card":      {R: 0x2f, G: 0x4f, B: 0x4f, A: 0xff},
+	"container": {R: 0x55, G: 0x55, B: 0x55, A: 0xff},
+}
+
+// otherColor is used for "other" and types outside the taxonomy
+var otherColor = color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}
+
+// Color returns the color of an element type
+func Color(elementType string) color.RGBA {
+	if c, ok := palette[elementType]; ok {
+		return c
+	}
+	return otherColor
+}
+
+const (
+	dashLength = 8
+	gapLength  = 4
+	labelPad   = 2
+)
+
+// Overlay draws the layers over a copy of img, in order. Each element gets
+// its box and a label with its type, prefixed by the layer name when there
+// are several layers.
+func Overlay(img image.Image, layers []Layer) *image.RGBA {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+
+	thickness := max(2, min(dst.Bounds().Dx(), dst.Bounds().Dy())/400)
+	for _, layer := range layers {
+		for _, el := range layer.Elements {
+			c := Color(el.Type)
+			box := image.Rect(
+				int(el.BBox.X), int(el.BBox.Y),
+				int(el.BBox.X+el.BBox.Width), int(el.BBox.Y+el.BBox.Height),
+			).Intersect(dst.Bounds())
+			if box.Empty() {
+				continue
+			}
+			outline(dst, box, c, thickness, layer.Dashed)
+
+			label := el.Type
+			if len(layers) > 1 {
+				label = layer.Name + ": " + el.Type
+			}
+			drawLabel(dst, box, label, c)
+		}
+	}
+	return dst
+}
+
+// outline draws the border of box, thickness pixels wide and inside it
+func outline(dst *image.RGBA, box image.Rectangle, c color.RGBA, thickness int, dashed bool) {
+	t := min(thickness, box.Dx(), box.Dy())
+	edges := []image.Rectangle{
+		image.Rect(box.Min.X, box.Min.Y, box.Max.X, box.Min.Y+t),
+		image.Rect(box.Min.X, box.Max.Y-t, box.Max.X, box.Max.Y),
+		image.Rect(box.Min.X, box.Min.Y, box.Min.X+t, box.Max.Y),
+		image.Rect(box.Max.X-t, box.Min.Y, box.Max.X, box.Max.Y),
+	}
+	src := image.NewUniform(c)
+	for i, edge := range edges {
+		if !dashed {
+			draw.Draw(dst, edge, src, image.Point{}, draw.Src)
+			continue
+		}
+		horizontal := i < 2
+		length := edge.Dx()
+		if !horizontal {
+			length = edge.Dy()
+		}
+		for start := 0; start < length; start += dashLength + gapLength {
+			end := min(start+dashLength, length)
+			dash := image.Rect(edge.Min.X, edge.Min.Y+start, edge.Max.X, edge.Min.Y+end)
+			if horizontal {
+				dash = image.Rect(edge.Min.X+start, edge.Min.Y, edge.Min.X+end, edge.Max.Y)
+			}
+			draw.Draw(dst, dash, src, image.Point{}, draw.Src)
+		}
+	}
+}
+
+// drawLabel writes text in white on a background of color c above the
+// top-left corner of box, or inside the box when there is no room above
+func drawLabel(dst *image.RGBA, box image.Rectangle, text string, c color.RGBA) {
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, text).Ceil() + 2*labelPad
+	height := face.Metrics().Height.Ceil() + 2*labelPad
+
+	bounds := dst.Bounds()
+	x := min(box.Min.X, bounds.Max.X-width)
+	y := box.Min.Y - height
+	if y < bounds.Min.Y {
+		y = box.Min.Y
+	}
+	background := image.Rect(max(x, bounds.Min.X), y, x+width, y+height).Intersect(bounds)
+	draw.Draw(dst, background, image.NewUniform(c), image.Point{}, draw.Src)
+
+	d := &font.Drawer{
+		Dst:  dst,
+		Src:  image.White,
+		Face: face,
+		Dot:  fixed.P(x+labelPad, y+labelPad+face.Metrics().Ascent.Ceil()),
+	}
+	d.DrawString(text)
+}
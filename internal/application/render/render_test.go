@@ -0,0 +1,46 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func whiteImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	return img
+}
+
+func TestOverlay(t *testing.T) {
+	src := whiteImage(200, 120)
+	button := entity.Element{Type: "button", BBox: entity.BBox{X: 20, Y: 40, Width: 100, Height: 50}}
+
+	out := Overlay(src, []Layer{{Name: "gt", Elements: []entity.Element{button}}})
+
+	white := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	assert.Equal(t, Color("button"), out.RGBAAt(60, 40), "top border")
+	assert.Equal(t, Color("button"), out.RGBAAt(119, 60), "right border")
+	assert.Equal(t, white, out.RGBAAt(60, 60), "inside the box")
+	assert.Equal(t, Color("button"), out.RGBAAt(21, 38), "label background above the box")
+	assert.Equal(t, white, src.RGBAAt(60, 40), "the source image is left untouched")
+}
+
+func TestOverlay_Dashed(t *testing.T) {
+	icon := entity.Element{Type: "icon", BBox: entity.BBox{X: 10, Y: 30, Width: 60, Height: 20}}
+
+	out := Overlay(whiteImage(100, 60), []Layer{{Name: "claude", Elements: []entity.Element{icon}, Dashed: true}})
+
+	assert.Equal(t, Color("icon"), out.RGBAAt(10+dashLength-1, 30))
+	assert.Equal(t, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, out.RGBAAt(10+dashLength, 49), "gap in the bottom border")
+}
+
+func TestColor(t *testing.T) {
+	assert.Equal(t, otherColor, Color("other"))
+	assert.Equal(t, otherColor, Color("unknown"))
+	assert.NotEqual(t, Color("button"), Color("link"))
+}
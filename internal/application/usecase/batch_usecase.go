@@ -0,0 +1,418 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
+	"github.com/label-platform-backend/internal/infrastructure/redis"
+	"github.com/label-platform-backend/pkg/worker"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Batch concurrency bounds
+const (
+	DefaultBatchConcurrency = 10
+	MaxBatchConcurrency     = 500
+)
+
+// BatchUseCaseImpl implements the BatchUseCase interface
+type BatchUseCaseImpl struct {
+	batchRepo         repository.PredictionBatchRepository
+	runRepo           repository.EvaluationRunRepository
+	projectRepo       repository.ProjectRepository
+	modelRepo         repository.ModelRepository
+	runUseCase        domainusecase.RunUseCase
+	predictionUseCase domainusecase.PredictionUseCase
+}
+
+// NewBatchUseCase creates a new prediction batch use case
+func NewBatchUseCase(batchRepo repository.PredictionBatchRepository, runRepo repository.EvaluationRunRepository, projectRepo repository.ProjectRepository, modelRepo repository.ModelRepository, runUseCase domainusecase.RunUseCase, predictionUseCase domainusecase.PredictionUseCase) *BatchUseCaseImpl {
+	return &BatchUseCaseImpl{
+		batchRepo:         batchRepo,
+		runRepo:           runRepo,
+		projectRepo:       projectRepo,
+		modelRepo:         modelRepo,
+		runUseCase:        runUseCase,
+		predictionUseCase: predictionUseCase,
+	}
+}
+
+// CreateBatch creates a batch and one run per model over the same snapshot
+// of images. The snapshot excludes images uploaded after the batch, so every
+// run gets the same images.
+func (u *BatchUseCaseImpl) CreateBatch(ctx context.Context, projectID uuid.UUID, req domainusecase.BatchRequest) (*entity.PredictionBatch, error) {
+	if req.Concurrency == 0 {
+		req.Concurrency = DefaultBatchConcurrency
+	}
+	if req.Concurrency < 1 || req.Concurrency > MaxBatchConcurrency {
+		return nil, fmt.Errorf("%w: concurrency must be between 1 and %d", domainusecase.ErrInvalidInput, MaxBatchConcurrency)
+	}
+	if req.Budget < 0 {
+		return nil, fmt.Errorf("%w: budget must not be negative", domainusecase.ErrInvalidInput)
+	}
+	if req.Priority == "" {
+		req.Priority = worker.PriorityLow
+	}
+	if !worker.ValidPriority(req.Priority) {
+		return nil, fmt.Errorf("%w: unknown priority %q", domainusecase.ErrInvalidInput, req.Priority)
+	}
+	if _, err := u.projectRepo.GetByID(ctx, projectID); err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	models := uniqueStrings(req.Models)
+	if len(models) == 0 {
+		var err error
+		if models, err = u.availableModels(ctx); err != nil {
+			return nil, err
+		}
+		if len(models) == 0 {
+			return nil, fmt.Errorf("%w: no models available", domainusecase.ErrInvalidInput)
+		}
+	}
+
+	now := time.Now()
+	req.Filter.ProjectID = &projectID
+	if req.Filter.To == nil || req.Filter.To.After(now) {
+		req.Filter.To = &now
+	}
+	filter, err := json.Marshal(req.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal filter: %w", err)
+	}
+
+	batch := &entity.PredictionBatch{
+		ProjectID:     projectID,
+		Name:          strings.TrimSpace(req.Name),
+		Models:        models,
+		PromptVersion: req.PromptVersion,
+		Filter:        datatypes.JSON(filter),
+		Force:         req.Force,
+		Priority:      req.Priority,
+		Concurrency:   req.Concurrency,
+		Budget:        req.Budget,
+		Status:        entity.BatchStatusRunning,
+	}
+	if err := u.batchRepo.Create(ctx, batch); err != nil {
+		return nil, fmt.Errorf("failed to create batch: %w", err)
+	}
+
+	for _, model := range models {
+		version := req.PromptVersion
+		if v, ok := req.PromptVersions[model]; ok {
+			version = v
+		}
+		_, err := u.runUseCase.CreateRun(ctx, domainusecase.RunRequest{
+			ProjectID:     &projectID,
+			BatchID:       &batch.ID,
+			Name:          batch.Name,
+			Model:         model,
+			PromptVersion: version,
+			Parameters:    req.Parameters,
+			Filter:        req.Filter,
+		})
+		if err != nil {
+			u.deleteBatch(ctx, batch.ID)
+			return nil, err
+		}
+	}
+	return u.GetBatch(ctx, batch.ID)
+}
+
+// GetBatch returns a batch with its runs and their summed progress
+func (u *BatchUseCaseImpl) GetBatch(ctx context.Context, id uuid.UUID) (*entity.PredictionBatch, error) {
+	batch, err := u.batchRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch: %w", err)
+	}
+	if batch.Runs, err = u.runRepo.GetByBatch(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to get batch runs: %w", err)
+	}
+
+	batch.Progress = &entity.RunProgress{}
+	for _, run := range batch.Runs {
+		progress, err := u.runRepo.GetProgress(ctx, run.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get run progress: %w", err)
+		}
+		batch.Progress.Add(*progress)
+	}
+	return batch, nil
+}
+
+// GetAllBatches lists the batches, optionally of a single project
+func (u *BatchUseCaseImpl) GetAllBatches(ctx context.Context, projectID *uuid.UUID) ([]*entity.PredictionBatch, error) {
+	return u.batchRepo.GetAll(ctx, projectID)
+}
+
+// CancelBatch stops dispatching a batch and cancels its active jobs. Their
+// images become pending again, so resuming dispatches them anew.
+func (u *BatchUseCaseImpl) CancelBatch(ctx context.Context, id uuid.UUID) (*entity.PredictionBatch, error) {
+	batch, err := u.batchRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch: %w", err)
+	}
+	ok, err := u.batchRepo.UpdateStatus(ctx, id, entity.BatchStatusCancelled, entity.BatchStatusRunning, entity.BatchStatusBudgetExhausted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel batch: %w", err)
+	}
+	if !ok && batch.Status != entity.BatchStatusCancelled {
+		return nil, fmt.Errorf("%w: batch is %s", domainusecase.ErrInvalidInput, batch.Status)
+	}
+
+	runs, err := u.runRepo.GetByBatch(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch runs: %w", err)
+	}
+	runIDs := make([]uuid.UUID, len(runs))
+	for i, run := range runs {
+		runIDs[i] = run.ID
+	}
+	if len(runIDs) > 0 {
+		if _, err := u.predictionUseCase.CancelJobs(ctx, domainusecase.JobFilter{RunIDs: runIDs}); err != nil {
+			return nil, err
+		}
+	}
+	return u.GetBatch(ctx, id)
+}
+
+// ResumeBatch sets a cancelled or exhausted batch and its cancelled runs
+// running again. A new budget must exceed the predictions already
+// dispatched. With retryFailed the failed images are dispatched again, which
+// also resumes completed batches.
+func (u *BatchUseCaseImpl) ResumeBatch(ctx context.Context, id uuid.UUID, budget *int, retryFailed bool) (*entity.PredictionBatch, error) {
+	batch, err := u.batchRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch: %w", err)
+	}
+	if budget != nil && *budget < 0 {
+		return nil, fmt.Errorf("%w: budget must not be negative", domainusecase.ErrInvalidInput)
+	}
+	if budget != nil && *budget > 0 && *budget <= batch.Dispatched {
+		return nil, fmt.Errorf("%w: budget must exceed the %d predictions already dispatched", domainusecase.ErrInvalidInput, batch.Dispatched)
+	}
+
+	from := []string{entity.BatchStatusCancelled, entity.BatchStatusBudgetExhausted}
+	if retryFailed {
+		from = append(from, entity.BatchStatusCompleted)
+	} else if batch.Status == entity.BatchStatusCompleted {
+		return nil, fmt.Errorf("%w: batch is completed", domainusecase.ErrInvalidInput)
+	}
+
+	if budget != nil {
+		if err := u.batchRepo.UpdateBudget(ctx, id, *budget); err != nil {
+			return nil, fmt.Errorf("failed to update budget: %w", err)
+		}
+	}
+	runs, err := u.runRepo.GetByBatch(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch runs: %w", err)
+	}
+	for _, run := range runs {
+		restart := run.Status == entity.RunStatusCancelled
+		if retryFailed {
+			requeued, err := u.runRepo.RequeueFailed(ctx, run.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to requeue failed images: %w", err)
+			}
+			restart = restart || (requeued > 0 && run.Status == entity.RunStatusCompleted)
+		}
+		if restart {
+			if err := u.runRepo.UpdateStatus(ctx, run.ID, entity.RunStatusRunning); err != nil {
+				return nil, fmt.Errorf("failed to restart run: %w", err)
+			}
+		}
+	}
+	if _, err := u.batchRepo.UpdateStatus(ctx, id, entity.BatchStatusRunning, from...); err != nil {
+		return nil, fmt.Errorf("failed to resume batch: %w", err)
+	}
+	return u.GetBatch(ctx, id)
+}
+
+// RunDispatcher dispatches the running batches every interval until ctx is
+// cancelled
+func (u *BatchUseCaseImpl) RunDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := u.Dispatch(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("[batch] dispatch failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch tops up the in-flight predictions of every running batch
+func (u *BatchUseCaseImpl) Dispatch(ctx context.Context) error {
+	batches, err := u.batchRepo.GetByStatus(ctx, entity.BatchStatusRunning)
+	if err != nil {
+		return fmt.Errorf("failed to get running batches: %w", err)
+	}
+	for _, batch := range batches {
+		if err := u.dispatchBatch(ctx, batch); err != nil {
+			log.Printf("[batch] failed to dispatch batch %s: %v", batch.ID, err)
+		}
+	}
+	return nil
+}
+
+// dispatchBatch claims pending images of the batch's runs up to its
+// concurrency and budget, sharing the capacity between runs, and predicts
+// them. Only predictions dispatched to a model count against the budget,
+// not those served from the cache. It completes the batch once nothing is
+// pending or queued.
+func (u *BatchUseCaseImpl) dispatchBatch(ctx context.Context, batch *entity.PredictionBatch) error {
+	runs, err := u.runRepo.GetByBatch(ctx, batch.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get batch runs: %w", err)
+	}
+
+	var total entity.RunProgress
+	var active []*entity.EvaluationRun
+	for _, run := range runs {
+		// Runs cancelled on their own are left out of the batch
+		if run.Status == entity.RunStatusCancelled {
+			continue
+		}
+		progress, err := u.runRepo.GetProgress(ctx, run.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get run progress: %w", err)
+		}
+		total.Add(*progress)
+		if progress.Pending > 0 {
+			active = append(active, run)
+		}
+	}
+
+	if total.Pending == 0 {
+		if total.Queued == 0 {
+			_, err := u.batchRepo.UpdateStatus(ctx, batch.ID, entity.BatchStatusCompleted, entity.BatchStatusRunning)
+			return err
+		}
+		return nil
+	}
+
+	capacity := batch.Concurrency - total.Queued
+	if batch.Budget > 0 {
+		remaining := batch.Budget - batch.Dispatched
+		if remaining <= 0 {
+			_, err := u.batchRepo.UpdateStatus(ctx, batch.ID, entity.BatchStatusBudgetExhausted, entity.BatchStatusRunning)
+			return err
+		}
+		capacity = min(capacity, remaining)
+	}
+
+	for i, run := range active {
+		if capacity <= 0 {
+			break
+		}
+		// Spread the capacity evenly over the runs left
+		share := max(1, capacity/(len(active)-i))
+		imageIDs, err := u.runRepo.ClaimPending(ctx, run.ID, share)
+		if err != nil {
+			return fmt.Errorf("failed to claim images: %w", err)
+		}
+		if len(imageIDs) == 0 {
+			continue
+		}
+		capacity -= len(imageIDs)
+		dispatched := 0
+		for _, imageID := range imageIDs {
+			if u.predict(ctx, batch, run, imageID) {
+				dispatched++
+			}
+		}
+		if dispatched == 0 {
+			continue
+		}
+		if err := u.batchRepo.AddDispatched(ctx, batch.ID, dispatched); err != nil {
+			return fmt.Errorf("failed to count dispatched predictions: %w", err)
+		}
+	}
+	return nil
+}
+
+// predict dispatches one image of a run, failing its result when the
+// prediction cannot be dispatched. It reports whether a model was called,
+// i.e. the prediction was not served from the cache.
+func (u *BatchUseCaseImpl) predict(ctx context.Context, batch *entity.PredictionBatch, run *entity.EvaluationRun, imageID uuid.UUID) bool {
+	runID := run.ID
+	dispatches, err := u.predictionUseCase.PredictImage(ctx, imageID, domainusecase.PredictOptions{RunID: &runID, Force: batch.Force, Priority: batch.Priority})
+	if err == nil {
+		for _, d := range dispatches {
+			if d.Mode != domainusecase.ModeCache {
+				return true
+			}
+		}
+		return false
+	}
+
+	log.Printf("[batch] failed to predict image %s with %s: %v", imageID, run.Model, err)
+	prediction, _ := json.Marshal(entity.Prediction{Error: err.Error()})
+	if err := u.runRepo.SetPrediction(ctx, run.ID, imageID, entity.RunResultFailed, datatypes.JSON(prediction), nil); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("[batch] failed to record failure of image %s: %v", imageID, err)
+	}
+	if err := u.runRepo.CompleteIfDone(ctx, run.ID); err != nil {
+		log.Printf("[batch] failed to update run %s: %v", run.ID, err)
+	}
+	return false
+}
+
+// availableModels lists the queue models and the enabled in-process models
+func (u *BatchUseCaseImpl) availableModels(ctx context.Context) ([]string, error) {
+	names := make([]string, 0, len(redis.ModelQueues))
+	for name := range redis.ModelQueues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	models, err := u.modelRepo.GetEnabled(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get models: %w", err)
+	}
+	return uniqueStrings(append(names, modelNames(models)...)), nil
+}
+
+// deleteBatch removes a batch whose runs could not all be created
+func (u *BatchUseCaseImpl) deleteBatch(ctx context.Context, id uuid.UUID) {
+	runs, err := u.runRepo.GetByBatch(ctx, id)
+	if err != nil {
+		log.Printf("[batch] failed to get runs of batch %s: %v", id, err)
+	}
+	for _, run := range runs {
+		if err := u.runRepo.Delete(ctx, run.ID); err != nil {
+			log.Printf("[batch] failed to delete run %s: %v", run.ID, err)
+		}
+	}
+	if err := u.batchRepo.Delete(ctx, id); err != nil {
+		log.Printf("[batch] failed to delete batch %s: %v", id, err)
+	}
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
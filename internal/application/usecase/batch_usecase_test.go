@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunRepo holds the pending and queued images of runs and records the
+// claims. Methods dispatchBatch does not use panic through the nil embedded
+// interface.
+type fakeRunRepo struct {
+	repository.EvaluationRunRepository
+	runs    []*entity.EvaluationRun
+	pending map[uuid.UUID]int
+	queued  map[uuid.UUID]int
+	claims  []int
+}
+
+func (r *fakeRunRepo) GetByBatch(ctx context.Context, batchID uuid.UUID) ([]*entity.EvaluationRun, error) {
+	return r.runs, nil
+}
+
+func (r *fakeRunRepo) GetProgress(ctx context.Context, runID uuid.UUID) (*entity.RunProgress, error) {
+	return &entity.RunProgress{Pending: r.pending[runID], Queued: r.queued[runID]}, nil
+}
+
+func (r *fakeRunRepo) ClaimPending(ctx context.Context, runID uuid.UUID, limit int) ([]uuid.UUID, error) {
+	n := min(limit, r.pending[runID])
+	r.pending[runID] -= n
+	r.claims = append(r.claims, n)
+	return imageIDs(n), nil
+}
+
+type fakeBatchRepo struct {
+	repository.PredictionBatchRepository
+	status     string
+	dispatched int
+}
+
+func (r *fakeBatchRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string, from ...string) (bool, error) {
+	r.status = status
+	return true, nil
+}
+
+func (r *fakeBatchRepo) AddDispatched(ctx context.Context, id uuid.UUID, n int) error {
+	r.dispatched += n
+	return nil
+}
+
+// fakePredictionUseCase serves the first cached predictions from the cache
+// and queues the others
+type fakePredictionUseCase struct {
+	domainusecase.PredictionUseCase
+	cached int
+}
+
+func (p *fakePredictionUseCase) PredictImage(ctx context.Context, id uuid.UUID, opts domainusecase.PredictOptions) ([]domainusecase.ModelDispatch, error) {
+	mode := domainusecase.ModeQueue
+	if p.cached > 0 {
+		p.cached--
+		mode = domainusecase.ModeCache
+	}
+	return []domainusecase.ModelDispatch{{Model: "gpt", Mode: mode}}, nil
+}
+
+func newBatchFixture(pending ...int) (*BatchUseCaseImpl, *fakeRunRepo, *fakeBatchRepo, *fakePredictionUseCase) {
+	runRepo := &fakeRunRepo{pending: map[uuid.UUID]int{}, queued: map[uuid.UUID]int{}}
+	for _, n := range pending {
+		run := &entity.EvaluationRun{ID: uuid.New(), Status: entity.RunStatusRunning}
+		runRepo.runs = append(runRepo.runs, run)
+		runRepo.pending[run.ID] = n
+	}
+	batchRepo := &fakeBatchRepo{status: entity.BatchStatusRunning}
+	predictions := &fakePredictionUseCase{}
+	return &BatchUseCaseImpl{batchRepo: batchRepo, runRepo: runRepo, predictionUseCase: predictions}, runRepo, batchRepo, predictions
+}
+
+func TestDispatchBatch_SharesCapacity(t *testing.T) {
+	u, runs, batches, _ := newBatchFixture(100, 100, 100)
+
+	require.NoError(t, u.dispatchBatch(context.Background(), &entity.PredictionBatch{ID: uuid.New(), Concurrency: 10}))
+	// 10/3, then 7/2, then the remaining 4
+	assert.Equal(t, []int{3, 3, 4}, runs.claims)
+	assert.Equal(t, 10, batches.dispatched)
+	assert.Equal(t, entity.BatchStatusRunning, batches.status)
+}
+
+func TestDispatchBatch_QueuedAndSmallRuns(t *testing.T) {
+	u, runs, batches, _ := newBatchFixture(1, 100, 100)
+	runs.queued[runs.runs[1].ID] = 4
+
+	require.NoError(t, u.dispatchBatch(context.Background(), &entity.PredictionBatch{ID: uuid.New(), Concurrency: 10}))
+	// 6 in flight slots: the first run only has 1 image, the others split the rest
+	assert.Equal(t, []int{1, 2, 3}, runs.claims)
+	assert.Equal(t, 6, batches.dispatched)
+}
+
+func TestDispatchBatch_AtLeastOnePerRun(t *testing.T) {
+	u, runs, _, _ := newBatchFixture(100, 100, 100)
+
+	require.NoError(t, u.dispatchBatch(context.Background(), &entity.PredictionBatch{ID: uuid.New(), Concurrency: 2}))
+	assert.Equal(t, []int{1, 1}, runs.claims, "the capacity runs out before the last run")
+}
+
+func TestDispatchBatch_Budget(t *testing.T) {
+	u, runs, batches, predictions := newBatchFixture(100, 100)
+	predictions.cached = 2
+
+	batch := &entity.PredictionBatch{ID: uuid.New(), Concurrency: 10, Budget: 8, Dispatched: 3}
+	require.NoError(t, u.dispatchBatch(context.Background(), batch))
+	assert.Equal(t, []int{2, 3}, runs.claims, "capped by the 5 predictions left")
+	assert.Equal(t, 3, batches.dispatched, "cached predictions do not count")
+	assert.Equal(t, entity.BatchStatusRunning, batches.status)
+}
+
+func TestDispatchBatch_BudgetExhausted(t *testing.T) {
+	u, runs, batches, _ := newBatchFixture(100)
+
+	batch := &entity.PredictionBatch{ID: uuid.New(), Concurrency: 10, Budget: 5, Dispatched: 5}
+	require.NoError(t, u.dispatchBatch(context.Background(), batch))
+	assert.Empty(t, runs.claims)
+	assert.Equal(t, entity.BatchStatusBudgetExhausted, batches.status)
+}
+
+func TestDispatchBatch_Completed(t *testing.T) {
+	u, runs, batches, _ := newBatchFixture(0, 0)
+
+	require.NoError(t, u.dispatchBatch(context.Background(), &entity.PredictionBatch{ID: uuid.New(), Concurrency: 10}))
+	assert.Equal(t, entity.BatchStatusCompleted, batches.status)
+
+	runs.queued[runs.runs[0].ID] = 1
+	batches.status = entity.BatchStatusRunning
+	require.NoError(t, u.dispatchBatch(context.Background(), &entity.PredictionBatch{ID: uuid.New(), Concurrency: 10}))
+	assert.Equal(t, entity.BatchStatusRunning, batches.status, "queued predictions keep the batch running")
+}
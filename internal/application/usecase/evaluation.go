@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/label-platform-backend/internal/application/evaluator"
+	"github.com/label-platform-backend/internal/application/parser"
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// errNoGroundTruth is returned for images without annotated ground truth
+var errNoGroundTruth = errors.New("image has no ground truth")
+
+// groundTruthElements parses the ground truth of an image into elements,
+// using the same normalization as model outputs
+func groundTruthElements(image *entity.Image) ([]entity.Element, error) {
+	if len(image.GroundTruth) == 0 || string(image.GroundTruth) == "null" {
+		return nil, errNoGroundTruth
+	}
+	result, err := parser.ForModel("").Parse(string(image.GroundTruth), image.Width, image.Height)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ground truth: %w", err)
+	}
+	return result.Elements, nil
+}
+
+// visibleGroundTruth is the ground truth of an image as shown through image
+// routes. The ground truth of gold images answers their gold tasks, so they
+// look like images without ground truth.
+func visibleGroundTruth(image *entity.Image) ([]entity.Element, error) {
+	if image.Gold {
+		return nil, errNoGroundTruth
+	}
+	return groundTruthElements(image)
+}
+
+// imagePredictions decodes the normalized predictions of an image by model.
+// Entries that are not normalized predictions, such as scores written
+// manually, are skipped.
+func imagePredictions(image *entity.Image) map[string]*entity.Prediction {
+	predictions := map[string]*entity.Prediction{}
+	if len(image.PredictedLabels) == 0 {
+		return predictions
+	}
+
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(image.PredictedLabels, &entries); err != nil {
+		return predictions
+	}
+	for model, raw := range entries {
+		var keys map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &keys); err != nil {
+			continue
+		}
+		if _, ok := keys["raw"]; !ok {
+			if _, ok := keys["elements"]; !ok {
+				continue
+			}
+		}
+		var prediction entity.Prediction
+		if err := json.Unmarshal(raw, &prediction); err != nil {
+			continue
+		}
+		predictions[model] = &prediction
+	}
+	return predictions
+}
+
+// errTooFewPredictions is returned when an image lacks the predictions of
+// two models to combine
+var errTooFewPredictions = errors.New("image has fewer than two usable predictions")
+
+// usablePredictions returns the elements of the predictions of an image that
+// did not fail, by model in sorted order, leaving out the ensemble
+func usablePredictions(image *entity.Image) ([]string, [][]entity.Element) {
+	predictions := imagePredictions(image)
+	models := make([]string, 0, len(predictions))
+	for model, prediction := range predictions {
+		if model != entity.EnsembleModel && prediction.Error == "" && prediction.ParseError == "" {
+			models = append(models, model)
+		}
+	}
+	sort.Strings(models)
+
+	elements := make([][]entity.Element, len(models))
+	for i, model := range models {
+		elements[i] = predictions[model].Elements
+	}
+	return models, elements
+}
+
+// scorePrediction evaluates one prediction against the ground truth. Failed
+// predictions are not scored; unparsable outputs score as empty predictions.
+func scorePrediction(gt []entity.Element, prediction *entity.Prediction) (entity.EvaluationScore, bool) {
+	if prediction.Error != "" {
+		return entity.EvaluationScore{}, false
+	}
+	score := evaluator.Evaluate(gt, prediction.Elements, evaluator.DefaultIoUThreshold)
+	score.PromptVersion = prediction.PromptVersion
+	return score, true
+}
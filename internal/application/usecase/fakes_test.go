@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// fakeImageRepo keeps images in memory. Methods the tests do not use panic
+// through the nil embedded interface.
+type fakeImageRepo struct {
+	repository.ImageRepository
+	images map[uuid.UUID]*entity.Image
+	order  []uuid.UUID
+}
+
+func newFakeImageRepo(images ...*entity.Image) *fakeImageRepo {
+	r := &fakeImageRepo{images: map[uuid.UUID]*entity.Image{}}
+	for _, image := range images {
+		r.images[image.ID] = image
+		r.order = append(r.order, image.ID)
+	}
+	return r
+}
+
+// ListIDs supports the IDs, project, tags and split filters
+func (r *fakeImageRepo) ListIDs(ctx context.Context, filter repository.ImageFilter) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for _, id := range r.order {
+		image := r.images[id]
+		switch {
+		case len(filter.IDs) > 0 && !slices.Contains(filter.IDs, id):
+		case filter.ProjectID != nil && (image.ProjectID == nil || *image.ProjectID != *filter.ProjectID):
+		case filter.Split != "" && image.Split != filter.Split:
+		case !containsAll(image.Tags, filter.Tags):
+		default:
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// List returns the images matching the filters supported by ListIDs
+func (r *fakeImageRepo) List(ctx context.Context, filter repository.ImageFilter) ([]*entity.Image, error) {
+	ids, err := r.ListIDs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	images := make([]*entity.Image, len(ids))
+	for i, id := range ids {
+		loaded := *r.images[id]
+		images[i] = &loaded
+	}
+	return images, nil
+}
+
+func containsAll(tags, wanted []string) bool {
+	for _, tag := range wanted {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *fakeImageRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Image, error) {
+	image, ok := r.images[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	loaded := *image
+	return &loaded, nil
+}
+
+func (r *fakeImageRepo) SetPredictedLabel(ctx context.Context, id uuid.UUID, model string, value datatypes.JSON) error {
+	image, ok := r.images[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	labels := map[string]json.RawMessage{}
+	if len(image.PredictedLabels) > 0 {
+		if err := json.Unmarshal(image.PredictedLabels, &labels); err != nil {
+			return err
+		}
+	}
+	labels[model] = json.RawMessage(value)
+	merged, err := json.Marshal(labels)
+	if err != nil {
+		return err
+	}
+	image.PredictedLabels = merged
+	return nil
+}
+
+func (r *fakeImageRepo) MergeEvaluationScores(ctx context.Context, id uuid.UUID, scores datatypes.JSON) error {
+	image, ok := r.images[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	merged := map[string]json.RawMessage{}
+	if len(image.EvaluationScores) > 0 {
+		if err := json.Unmarshal(image.EvaluationScores, &merged); err != nil {
+			return err
+		}
+	}
+	if err := json.Unmarshal(scores, &merged); err != nil {
+		return err
+	}
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	image.EvaluationScores = data
+	return nil
+}
+
+func (r *fakeImageRepo) SetDraft(ctx context.Context, id uuid.UUID, draft datatypes.JSON) error {
+	image, ok := r.images[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	image.Draft = draft
+	return nil
+}
+
+// draft decodes the stored draft of an image, nil when it has none
+func (r *fakeImageRepo) draft(id uuid.UUID) *entity.Draft {
+	image, ok := r.images[id]
+	if !ok || len(image.Draft) == 0 {
+		return nil
+	}
+	var draft entity.Draft
+	if err := json.Unmarshal(image.Draft, &draft); err != nil {
+		return nil
+	}
+	return &draft
+}
+
+// prediction decodes the stored prediction of a model of an image
+func (r *fakeImageRepo) prediction(id uuid.UUID, model string) *entity.Prediction {
+	image, ok := r.images[id]
+	if !ok {
+		return nil
+	}
+	return imagePredictions(image)[model]
+}
+
+type fakeProjectRepo struct {
+	repository.ProjectRepository
+	projects map[uuid.UUID]*entity.Project
+}
+
+func newFakeProjectRepo(projects ...*entity.Project) *fakeProjectRepo {
+	r := &fakeProjectRepo{projects: map[uuid.UUID]*entity.Project{}}
+	for _, project := range projects {
+		r.projects[project.ID] = project
+	}
+	return r
+}
+
+func (r *fakeProjectRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+	project, ok := r.projects[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return project, nil
+}
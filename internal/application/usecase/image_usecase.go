@@ -2,35 +2,65 @@ package usecase
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
 	"mime/multipart"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/application/ensemble"
+	"github.com/label-platform-backend/internal/application/evaluator"
+	"github.com/label-platform-backend/internal/application/parser"
 	"github.com/label-platform-backend/internal/domain/entity"
 	"github.com/label-platform-backend/internal/domain/repository"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
 	"github.com/label-platform-backend/internal/infrastructure/storage"
 	"github.com/minio/minio-go/v7"
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 // ImageUseCaseImpl implements the ImageUseCase interface
 type ImageUseCaseImpl struct {
 	imageRepo   repository.ImageRepository
+	projectRepo repository.ProjectRepository
+	cacheRepo   repository.PredictionCacheRepository
+	runRepo     repository.EvaluationRunRepository
+	taskRepo    repository.AnnotationTaskRepository
 	minioClient *storage.MinioClient
+	// renderCache keeps renders in MinIO to reuse them
+	renderCache bool
 }
 
 // NewImageUseCase creates a new image use case
-func NewImageUseCase(imageRepo repository.ImageRepository, minioClient *storage.MinioClient) *ImageUseCaseImpl {
+func NewImageUseCase(imageRepo repository.ImageRepository, projectRepo repository.ProjectRepository, cacheRepo repository.PredictionCacheRepository, runRepo repository.EvaluationRunRepository, taskRepo repository.AnnotationTaskRepository, minioClient *storage.MinioClient, renderCache bool) *ImageUseCaseImpl {
 	return &ImageUseCaseImpl{
 		imageRepo:   imageRepo,
+		projectRepo: projectRepo,
+		cacheRepo:   cacheRepo,
+		runRepo:     runRepo,
+		taskRepo:    taskRepo,
 		minioClient: minioClient,
+		renderCache: renderCache,
 	}
 }
 
 // UploadImage handles the upload of an image file and creates a new image
-func (u *ImageUseCaseImpl) UploadImage(ctx context.Context, file *multipart.FileHeader, groundTruth map[string]any) (*entity.Image, error) {
+func (u *ImageUseCaseImpl) UploadImage(ctx context.Context, file *multipart.FileHeader, metadata domainusecase.ImageMetadata) (*entity.Image, error) {
+	if err := u.validateMetadata(ctx, metadata); err != nil {
+		return nil, err
+	}
+
 	// Generate unique filename with format: screenshots/{uuid}-{original_filename}
 	uuidStr := uuid.New().String()
 	filename := fmt.Sprintf("screenshots/%s-%s", uuidStr, file.Filename)
@@ -42,39 +72,52 @@ func (u *ImageUseCaseImpl) UploadImage(ctx context.Context, file *multipart.File
 	}
 	defer src.Close()
 
+	// Read the pixel size, needed to scale normalized model coordinates
+	var width, height int
+	if cfg, _, err := image.DecodeConfig(src); err == nil {
+		width, height = cfg.Width, cfg.Height
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	// Hash the content, used as the prediction cache key
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, src); err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
 	// Upload to MinIO
 	_, err = u.minioClient.GetClient().PutObject(ctx, u.minioClient.GetBucket(), filename, src, file.Size, minio.PutObjectOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload file to MinIO: %w", err)
 	}
 
-	// Convert ground truth to datatypes.JSON
-	var groundTruthJSON datatypes.JSON
-	if groundTruth != nil {
-		groundTruthBytes, err := json.Marshal(groundTruth)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal ground truth: %w", err)
-		}
-		groundTruthJSON = datatypes.JSON(groundTruthBytes)
-	}
-
 	// Create image entity
-	image := &entity.Image{
+	img := &entity.Image{
 		ID:          uuid.MustParse(uuidStr),
+		ProjectID:   metadata.ProjectID,
 		Name:        file.Filename,
 		MinioPath:   filename,
-		GroundTruth: groundTruthJSON,
+		Width:       width,
+		Height:      height,
+		ContentHash: hex.EncodeToString(hasher.Sum(nil)),
+		Tags:        datatypes.JSONSlice[string](metadata.Tags),
+		Split:       metadata.Split,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 
 	// Save to database
-	err = u.imageRepo.Create(ctx, image)
+	err = u.imageRepo.Create(ctx, img)
 	if err != nil {
 		return nil, fmt.Errorf("failed to save image: %w", err)
 	}
 
-	return image, nil
+	return img, nil
 }
 
 // GetImageByID retrieves an image by its ID
@@ -134,8 +177,15 @@ func (u *ImageUseCaseImpl) DeleteImage(ctx context.Context, id uuid.UUID) error
 	if err != nil {
 		return fmt.Errorf("failed to delete file from MinIO: %w", err)
 	}
+	u.removeRenders(ctx, id)
 
 	// Delete from database
+	if err := u.runRepo.DeleteResultsByImage(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete run results: %w", err)
+	}
+	if err := u.taskRepo.DeleteByImage(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete annotation tasks: %w", err)
+	}
 	return u.imageRepo.Delete(ctx, id)
 }
 
@@ -168,14 +218,466 @@ func (u *ImageUseCaseImpl) UpdateGroundTruth(ctx context.Context, id uuid.UUID,
 	image.GroundTruth = groundTruthJSON
 	image.UpdatedAt = time.Now()
 
-	err = u.imageRepo.Update(ctx, image)
+	if err := u.imageRepo.SetGroundTruth(ctx, id, groundTruthJSON); err != nil {
+		return nil, fmt.Errorf("failed to update image: %w", err)
+	}
+
+	// Re-score every model and run against the new ground truth
+	if err := u.evaluate(ctx, image, imagePredictions(image)); err != nil && !errors.Is(err, errNoGroundTruth) {
+		log.Printf("[evaluate] failed to evaluate image %s: %v", id, err)
+	}
+	if err := u.rescoreRuns(ctx, image); err != nil && !errors.Is(err, errNoGroundTruth) {
+		log.Printf("[evaluate] failed to re-score runs of image %s: %v", id, err)
+	}
+
+	return image, nil
+}
+
+// UpdateImageMetadata updates the project, tags and split of an image
+func (u *ImageUseCaseImpl) UpdateImageMetadata(ctx context.Context, id uuid.UUID, metadata domainusecase.ImageMetadata) (*entity.Image, error) {
+	if err := u.validateMetadata(ctx, metadata); err != nil {
+		return nil, err
+	}
+
+	image, err := u.imageRepo.GetByID(ctx, id)
 	if err != nil {
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+
+	image.ProjectID = metadata.ProjectID
+	image.Tags = datatypes.JSONSlice[string](metadata.Tags)
+	image.Split = metadata.Split
+	image.UpdatedAt = time.Now()
+
+	if err := u.imageRepo.UpdateMetadata(ctx, id, metadata.ProjectID, metadata.Tags, metadata.Split); err != nil {
 		return nil, fmt.Errorf("failed to update image: %w", err)
 	}
+	return image, nil
+}
+
+// SetGold marks or unmarks an image as gold. Only images with ground truth
+// can be gold.
+func (u *ImageUseCaseImpl) SetGold(ctx context.Context, id uuid.UUID, gold bool) (*entity.Image, error) {
+	image, err := u.imageRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+	if gold {
+		if _, err := groundTruthElements(image); err != nil {
+			return nil, fmt.Errorf("%w: %v", domainusecase.ErrInvalidInput, err)
+		}
+	}
+
+	image.Gold = gold
+	image.UpdatedAt = time.Now()
+	if err := u.imageRepo.SetGold(ctx, id, gold); err != nil {
+		return nil, fmt.Errorf("failed to update image: %w", err)
+	}
+	return image, nil
+}
+
+// DraftGroundTruth builds the draft ground truth of an image from the
+// predictions of the requested models, or of the pre-labeling models of its
+// project
+func (u *ImageUseCaseImpl) DraftGroundTruth(ctx context.Context, id uuid.UUID, request domainusecase.PrelabelRequest) (*entity.Image, error) {
+	models, minVotes, err := prelabelSetting(request.Models, request.MinVotes)
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := u.imageRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+	if len(models) == 0 && image.ProjectID != nil {
+		project, err := u.projectRepo.GetByID(ctx, *image.ProjectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get project: %w", err)
+		}
+		models, minVotes = project.PrelabelModels, project.PrelabelMinVotes
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("%w: no pre-labeling model given", domainusecase.ErrInvalidInput)
+	}
 
+	draft, err := buildDraft(image, models, minVotes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domainusecase.ErrInvalidInput, err)
+	}
+	if err := u.saveDraft(ctx, image, draft); err != nil {
+		return nil, err
+	}
 	return image, nil
 }
 
+// prelabel refreshes the draft of an image without ground truth when its
+// project pre-labels with model. Failures are logged, the prediction being
+// already stored.
+func (u *ImageUseCaseImpl) prelabel(ctx context.Context, image *entity.Image, model string) {
+	if image.ProjectID == nil {
+		return
+	}
+	if _, err := groundTruthElements(image); !errors.Is(err, errNoGroundTruth) {
+		return
+	}
+	project, err := u.projectRepo.GetByID(ctx, *image.ProjectID)
+	if err != nil {
+		log.Printf("[prelabel] failed to get project of image %s: %v", image.ID, err)
+		return
+	}
+	if !slices.Contains(project.PrelabelModels, model) {
+		return
+	}
+
+	// Reload the image to see the prediction just stored
+	current, err := u.imageRepo.GetByID(ctx, image.ID)
+	if err != nil {
+		log.Printf("[prelabel] failed to get image %s: %v", image.ID, err)
+		return
+	}
+	draft, err := buildDraft(current, project.PrelabelModels, project.PrelabelMinVotes)
+	if err != nil {
+		if !errors.Is(err, errNoPrelabelPrediction) {
+			log.Printf("[prelabel] failed to build draft of image %s: %v", image.ID, err)
+		}
+		return
+	}
+	if err := u.saveDraft(ctx, current, draft); err != nil {
+		log.Printf("[prelabel] %v", err)
+	}
+}
+
+// saveDraft stores the draft of an image
+func (u *ImageUseCaseImpl) saveDraft(ctx context.Context, image *entity.Image, draft *entity.Draft) error {
+	draftJSON, err := marshalDraft(draft)
+	if err != nil {
+		return err
+	}
+	if err := u.imageRepo.SetDraft(ctx, image.ID, draftJSON); err != nil {
+		return fmt.Errorf("failed to save draft of image %s: %w", image.ID, err)
+	}
+	image.Draft = draftJSON
+	return nil
+}
+
+// EvaluateImage scores every model prediction of an image against its ground truth
+func (u *ImageUseCaseImpl) EvaluateImage(ctx context.Context, id uuid.UUID) (*entity.Image, error) {
+	image, err := u.imageRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+	// Gold images are scored when their predictions are stored, but their
+	// scores are not shown
+	if image.Gold {
+		return nil, fmt.Errorf("%w: %v", domainusecase.ErrInvalidInput, errNoGroundTruth)
+	}
+	if err := u.evaluate(ctx, image, imagePredictions(image)); err != nil {
+		if errors.Is(err, errNoGroundTruth) {
+			return nil, fmt.Errorf("%w: %v", domainusecase.ErrInvalidInput, err)
+		}
+		return nil, err
+	}
+	if err := u.rescoreRuns(ctx, image); err != nil {
+		return nil, err
+	}
+	return u.imageRepo.GetByID(ctx, id)
+}
+
+// ComparePrediction matches the prediction of a model with the ground truth
+// of an image like the evaluator does and lists the pairs, mismatches and
+// unmatched elements. An unparsable output compares as an empty prediction.
+// Gold images compare as images without ground truth.
+func (u *ImageUseCaseImpl) ComparePrediction(ctx context.Context, id uuid.UUID, model string) (*entity.PredictionDiff, error) {
+	if model == "" {
+		return nil, fmt.Errorf("%w: model is required", domainusecase.ErrInvalidInput)
+	}
+	image, err := u.imageRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+	gt, err := visibleGroundTruth(image)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domainusecase.ErrInvalidInput, err)
+	}
+	prediction, ok := imagePredictions(image)[model]
+	if !ok {
+		return nil, fmt.Errorf("%w: image has no prediction of model %s", domainusecase.ErrInvalidInput, model)
+	}
+	if prediction.Error != "" {
+		return nil, fmt.Errorf("%w: prediction of model %s failed: %s", domainusecase.ErrInvalidInput, model, prediction.Error)
+	}
+
+	diff := evaluator.Diff(gt, prediction.Elements, evaluator.DefaultIoUThreshold)
+	diff.ImageID = image.ID
+	diff.Model = model
+	diff.PromptVersion = prediction.PromptVersion
+	diff.Score.PromptVersion = prediction.PromptVersion
+	return &diff, nil
+}
+
+// evaluate scores predictions by model against the ground truth of an image
+// and merges the scores into evaluation_scores
+func (u *ImageUseCaseImpl) evaluate(ctx context.Context, image *entity.Image, predictions map[string]*entity.Prediction) error {
+	gt, err := groundTruthElements(image)
+	if err != nil {
+		return err
+	}
+
+	scores := map[string]entity.EvaluationScore{}
+	for name, prediction := range predictions {
+		if score, ok := scorePrediction(gt, prediction); ok {
+			scores[name] = score
+		}
+	}
+	if len(scores) == 0 {
+		return nil
+	}
+
+	scoresBytes, err := json.Marshal(scores)
+	if err != nil {
+		return fmt.Errorf("failed to marshal evaluation scores: %w", err)
+	}
+	if err := u.imageRepo.MergeEvaluationScores(ctx, image.ID, datatypes.JSON(scoresBytes)); err != nil {
+		return fmt.Errorf("failed to save evaluation scores: %w", err)
+	}
+	return nil
+}
+
+// validateMetadata checks that the project of an image exists
+func (u *ImageUseCaseImpl) validateMetadata(ctx context.Context, metadata domainusecase.ImageMetadata) error {
+	if metadata.ProjectID == nil {
+		return nil
+	}
+	if _, err := u.projectRepo.GetByID(ctx, *metadata.ProjectID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: project %s not found", domainusecase.ErrInvalidInput, metadata.ProjectID)
+		}
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	return nil
+}
+
+// rescoreRuns scores the run predictions of an image against its current
+// ground truth
+func (u *ImageUseCaseImpl) rescoreRuns(ctx context.Context, image *entity.Image) error {
+	gt, err := groundTruthElements(image)
+	if err != nil {
+		return err
+	}
+	results, err := u.runRepo.GetResultsByImage(ctx, image.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get run results: %w", err)
+	}
+
+	for _, result := range results {
+		if len(result.Prediction) == 0 {
+			continue
+		}
+		var prediction entity.Prediction
+		if err := json.Unmarshal(result.Prediction, &prediction); err != nil {
+			continue
+		}
+		score, ok := scorePrediction(gt, &prediction)
+		if !ok {
+			continue
+		}
+		scoreBytes, err := json.Marshal(score)
+		if err != nil {
+			return fmt.Errorf("failed to marshal run score: %w", err)
+		}
+		if err := u.runRepo.SetScore(ctx, result.RunID, image.ID, datatypes.JSON(scoreBytes)); err != nil {
+			return fmt.Errorf("failed to save run score: %w", err)
+		}
+	}
+	return nil
+}
+
+// SavePrediction normalizes the result reported by a model and stores it
+// under predicted_labels[model], and in the run when the prediction belongs
+// to one. The raw output is always kept; parse errors and prediction failures
+// are recorded alongside it.
+func (u *ImageUseCaseImpl) SavePrediction(ctx context.Context, report domainusecase.PredictionReport) error {
+	image, err := u.imageRepo.GetByID(ctx, report.ImageID)
+	if err != nil {
+		return fmt.Errorf("failed to get image: %w", err)
+	}
+
+	prediction := entity.Prediction{Raw: report.Result, PromptVersion: report.PromptVersion, Error: report.Error}
+	if report.Error == "" {
+		width, height, err := u.imageSize(ctx, image)
+		if err != nil {
+			log.Printf("[predict] failed to read size of image %s: %v", image.ID, err)
+		}
+
+		p := parser.ForModel(report.Model)
+		prediction.Parser = p.Name
+		parsed, err := p.Parse(report.Result, width, height)
+		if err != nil {
+			prediction.ParseError = err.Error()
+		} else {
+			prediction.Elements = parsed.Elements
+			prediction.Repairs = parsed.Repairs
+			prediction.Warnings = parsed.Warnings
+		}
+	}
+
+	if err := u.storePrediction(ctx, image, report.Model, &prediction, report.RunID); err != nil {
+		return err
+	}
+
+	// Only usable predictions are cached, failures are retried next time. Run
+	// predictions may use non-default parameters and are not cached.
+	if report.CacheVersion != nil && report.RunID == nil && report.Model != entity.EnsembleModel && image.ContentHash != "" && prediction.Error == "" && prediction.ParseError == "" {
+		u.cachePrediction(ctx, image, report.Model, *report.CacheVersion, &prediction)
+	}
+	return nil
+}
+
+// cachePrediction stores a prediction in the prediction cache under the
+// prompt version it was requested with, the version PredictImage looks up
+func (u *ImageUseCaseImpl) cachePrediction(ctx context.Context, image *entity.Image, model, promptVersion string, prediction *entity.Prediction) {
+	predictionBytes, err := json.Marshal(prediction)
+	if err == nil {
+		err = u.cacheRepo.Put(ctx, &entity.PredictionCacheEntry{
+			ContentHash:   image.ContentHash,
+			Model:         model,
+			PromptVersion: promptVersion,
+			Prediction:    datatypes.JSON(predictionBytes),
+			CreatedAt:     time.Now(),
+		})
+	}
+	if err != nil {
+		log.Printf("[predict] failed to cache %s prediction for %s: %v", model, image.ID, err)
+	}
+}
+
+// StorePrediction stores an already normalized prediction, such as one served
+// from the prediction cache
+func (u *ImageUseCaseImpl) StorePrediction(ctx context.Context, id uuid.UUID, model string, prediction *entity.Prediction, runID *uuid.UUID) error {
+	image, err := u.imageRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get image: %w", err)
+	}
+	return u.storePrediction(ctx, image, model, prediction, runID)
+}
+
+func (u *ImageUseCaseImpl) storePrediction(ctx context.Context, image *entity.Image, model string, prediction *entity.Prediction, runID *uuid.UUID) error {
+	predictionBytes, err := json.Marshal(prediction)
+	if err != nil {
+		return fmt.Errorf("failed to marshal prediction: %w", err)
+	}
+
+	if err := u.imageRepo.SetPredictedLabel(ctx, image.ID, model, datatypes.JSON(predictionBytes)); err != nil {
+		return fmt.Errorf("failed to save prediction: %w", err)
+	}
+
+	if err := u.evaluate(ctx, image, map[string]*entity.Prediction{model: prediction}); err != nil && !errors.Is(err, errNoGroundTruth) {
+		log.Printf("[evaluate] failed to evaluate %s prediction for %s: %v", model, image.ID, err)
+	}
+	if model != entity.EnsembleModel {
+		if _, err := u.combinePredictions(ctx, image.ID); err != nil && !errors.Is(err, errTooFewPredictions) {
+			log.Printf("[ensemble] failed to combine predictions of image %s: %v", image.ID, err)
+		}
+	}
+	u.prelabel(ctx, image, model)
+
+	if runID != nil {
+		return u.saveRunResult(ctx, *runID, image, prediction, predictionBytes)
+	}
+	return nil
+}
+
+// CombinePredictions stores the consensus of the usable predictions of an
+// image as the ensemble model's prediction
+func (u *ImageUseCaseImpl) CombinePredictions(ctx context.Context, id uuid.UUID) (*entity.Prediction, error) {
+	prediction, err := u.combinePredictions(ctx, id)
+	if errors.Is(err, errTooFewPredictions) {
+		return nil, fmt.Errorf("%w: %v", domainusecase.ErrInvalidInput, err)
+	}
+	return prediction, err
+}
+
+// combinePredictions combines the usable predictions of every model of an
+// image and stores the result under the ensemble model, which evaluates it
+func (u *ImageUseCaseImpl) combinePredictions(ctx context.Context, id uuid.UUID) (*entity.Prediction, error) {
+	// The image is loaded again to see the predictions stored concurrently
+	image, err := u.imageRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+
+	models, elements := usablePredictions(image)
+	if len(models) < 2 {
+		return nil, errTooFewPredictions
+	}
+	prediction := &entity.Prediction{
+		Elements: ensemble.Combine(elements, ensemble.Options{}),
+		Members:  models,
+		Parser:   entity.EnsembleModel,
+	}
+	if err := u.storePrediction(ctx, image, entity.EnsembleModel, prediction, nil); err != nil {
+		return nil, err
+	}
+	return prediction, nil
+}
+
+// saveRunResult stores the prediction and score of an image in a run and
+// completes the run once every image has a prediction
+func (u *ImageUseCaseImpl) saveRunResult(ctx context.Context, runID uuid.UUID, image *entity.Image, prediction *entity.Prediction, predictionBytes []byte) error {
+	status := entity.RunResultPredicted
+	if prediction.Error != "" {
+		status = entity.RunResultFailed
+	}
+
+	var scoreJSON datatypes.JSON
+	if gt, err := groundTruthElements(image); err == nil {
+		if score, ok := scorePrediction(gt, prediction); ok {
+			scoreBytes, err := json.Marshal(score)
+			if err != nil {
+				return fmt.Errorf("failed to marshal run score: %w", err)
+			}
+			scoreJSON = datatypes.JSON(scoreBytes)
+		}
+	}
+
+	err := u.runRepo.SetPrediction(ctx, runID, image.ID, status, datatypes.JSON(predictionBytes), scoreJSON)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: image %s is not part of run %s", domainusecase.ErrInvalidInput, image.ID, runID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save run result: %w", err)
+	}
+	if err := u.runRepo.CompleteIfDone(ctx, runID); err != nil {
+		return fmt.Errorf("failed to update run status: %w", err)
+	}
+	return nil
+}
+
+// imageSize returns the pixel size of an image, reading it from MinIO for
+// images uploaded before sizes were recorded
+func (u *ImageUseCaseImpl) imageSize(ctx context.Context, img *entity.Image) (int, int, error) {
+	if img.Width > 0 && img.Height > 0 {
+		return img.Width, img.Height, nil
+	}
+
+	obj, err := u.minioClient.GetClient().GetObject(ctx, u.minioClient.GetBucket(), img.MinioPath, minio.GetObjectOptions{})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get image from MinIO: %w", err)
+	}
+	defer obj.Close()
+
+	cfg, _, err := image.DecodeConfig(obj)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	if err := u.imageRepo.UpdateDimensions(ctx, img.ID, cfg.Width, cfg.Height); err != nil {
+		return 0, 0, fmt.Errorf("failed to save image size: %w", err)
+	}
+	img.Width, img.Height = cfg.Width, cfg.Height
+	return cfg.Width, cfg.Height, nil
+}
+
 // GetMinioClient returns the MinioClient instance
 func (u *ImageUseCaseImpl) GetMinioClient() *storage.MinioClient {
 	return u.minioClient
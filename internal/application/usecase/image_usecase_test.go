@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func TestGoldGroundTruthIsHidden(t *testing.T) {
+	labels := `{"claude":{"elements":[{"type":"button","text":"Submit","bbox":{"x":100,"y":200,"width":80,"height":40}}]}}`
+	labeled := &entity.Image{ID: uuid.New(), GroundTruth: datatypes.JSON(goldGroundTruth), PredictedLabels: datatypes.JSON(labels)}
+	gold := &entity.Image{ID: uuid.New(), Gold: true, GroundTruth: datatypes.JSON(goldGroundTruth), PredictedLabels: datatypes.JSON(labels)}
+	u := &ImageUseCaseImpl{imageRepo: newFakeImageRepo(labeled, gold)}
+	ctx := context.Background()
+
+	diff, err := u.ComparePrediction(ctx, labeled.ID, "claude")
+	require.NoError(t, err)
+	assert.Len(t, diff.FalseNegatives, 1)
+	layers, err := renderLayers(labeled, []string{layerGroundTruth})
+	require.NoError(t, err)
+	assert.Len(t, layers[0].Elements, 2)
+
+	// Gold images look like images without ground truth
+	_, err = u.ComparePrediction(ctx, gold.ID, "claude")
+	assert.ErrorIs(t, err, domainusecase.ErrInvalidInput)
+	assert.ErrorContains(t, err, errNoGroundTruth.Error())
+	_, err = renderLayers(gold, []string{layerGroundTruth})
+	assert.ErrorIs(t, err, domainusecase.ErrInvalidInput)
+	assert.ErrorContains(t, err, errNoGroundTruth.Error())
+	_, err = u.EvaluateImage(ctx, gold.ID)
+	assert.ErrorIs(t, err, domainusecase.ErrInvalidInput)
+	assert.ErrorContains(t, err, errNoGroundTruth.Error())
+
+	layers, err = renderLayers(gold, []string{"claude"})
+	require.NoError(t, err, "predictions of gold images are still drawn")
+	assert.Len(t, layers[0].Elements, 1)
+}
+
+func TestSavePrediction_CachesUnderRequestedPromptVersion(t *testing.T) {
+	const raw = `{"elements":[{"type":"button","text":"Submit","bbox":{"x":100,"y":200,"width":80,"height":40}}]}`
+	v2 := "v2"
+	tests := []struct {
+		name   string
+		report domainusecase.PredictionReport
+		cached bool
+	}{
+		// The worker may report the version of the prompt it was sent, the
+		// cache key is the version the prediction was requested with
+		{"requested version", domainusecase.PredictionReport{Model: "local", PromptVersion: "v3", CacheVersion: &v2, Result: raw}, true},
+		{"not requested through the cache", domainusecase.PredictionReport{Model: "local", Result: raw}, false},
+		{"failed prediction", domainusecase.PredictionReport{Model: "local", CacheVersion: &v2, Error: "timeout"}, false},
+		{"unparsable output", domainusecase.PredictionReport{Model: "local", CacheVersion: &v2, Result: "not json"}, false},
+		{"ensemble", domainusecase.PredictionReport{Model: entity.EnsembleModel, CacheVersion: &v2, Result: raw}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image := &entity.Image{ID: uuid.New(), ContentHash: "abc", Width: 1000, Height: 800}
+			images := newFakeImageRepo(image)
+			cache := &fakeCacheRepo{}
+			u := &ImageUseCaseImpl{imageRepo: images, cacheRepo: cache}
+
+			tt.report.ImageID = image.ID
+			require.NoError(t, u.SavePrediction(context.Background(), tt.report))
+			require.NotNil(t, images.prediction(image.ID, tt.report.Model), "the prediction is stored either way")
+
+			if !tt.cached {
+				assert.Empty(t, cache.entries)
+				return
+			}
+			require.Contains(t, cache.entries, cacheKey("abc", "local", "v2"))
+			var cached entity.Prediction
+			require.NoError(t, json.Unmarshal(cache.entries[cacheKey("abc", "local", "v2")].Prediction, &cached))
+			assert.Equal(t, "v3", cached.PromptVersion)
+			assert.Len(t, cached.Elements, 1)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
+)
+
+// ModelUseCaseImpl implements the ModelUseCase interface
+type ModelUseCaseImpl struct {
+	modelRepo repository.ModelRepository
+}
+
+// NewModelUseCase creates a new model use case
+func NewModelUseCase(modelRepo repository.ModelRepository) *ModelUseCaseImpl {
+	return &ModelUseCaseImpl{
+		modelRepo: modelRepo,
+	}
+}
+
+// CreateModel validates and registers a new model
+func (u *ModelUseCaseImpl) CreateModel(ctx context.Context, model *entity.Model) error {
+	if err := validateModel(model); err != nil {
+		return err
+	}
+
+	model.ID = uuid.New()
+	model.CreatedAt = time.Now()
+	model.UpdatedAt = time.Now()
+
+	if err := u.modelRepo.Create(ctx, model); err != nil {
+		return fmt.Errorf("failed to save model: %w", err)
+	}
+	return nil
+}
+
+// GetModelByID retrieves a model by its ID
+func (u *ModelUseCaseImpl) GetModelByID(ctx context.Context, id uuid.UUID) (*entity.Model, error) {
+	return u.modelRepo.GetByID(ctx, id)
+}
+
+// GetAllModels retrieves all registered models
+func (u *ModelUseCaseImpl) GetAllModels(ctx context.Context) ([]*entity.Model, error) {
+	return u.modelRepo.GetAll(ctx)
+}
+
+// UpdateModel validates and updates a registered model
+func (u *ModelUseCaseImpl) UpdateModel(ctx context.Context, model *entity.Model) error {
+	if err := validateModel(model); err != nil {
+		return err
+	}
+
+	model.UpdatedAt = time.Now()
+
+	if err := u.modelRepo.Update(ctx, model); err != nil {
+		return fmt.Errorf("failed to update model: %w", err)
+	}
+	return nil
+}
+
+// DeleteModel removes a registered model
+func (u *ModelUseCaseImpl) DeleteModel(ctx context.Context, id uuid.UUID) error {
+	return u.modelRepo.Delete(ctx, id)
+}
+
+func validateModel(model *entity.Model) error {
+	if model.Name == "" {
+		return fmt.Errorf("%w: name is required", domainusecase.ErrInvalidInput)
+	}
+	if model.Name == entity.EnsembleModel {
+		return fmt.Errorf("%w: %s is reserved for the consensus of the other models", domainusecase.ErrInvalidInput, entity.EnsembleModel)
+	}
+	if model.APIKey != "" && model.APIKeyEnv != "" {
+		return fmt.Errorf("%w: api_key and api_key_env are mutually exclusive", domainusecase.ErrInvalidInput)
+	}
+	switch model.Provider {
+	case entity.ProviderOpenAI:
+		if model.BaseURL == "" || model.ModelName == "" {
+			return fmt.Errorf("%w: base_url and model_name are required for openai models", domainusecase.ErrInvalidInput)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported provider %q", domainusecase.ErrInvalidInput, model.Provider)
+	}
+	return nil
+}
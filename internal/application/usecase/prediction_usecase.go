@@ -0,0 +1,692 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
+	"github.com/label-platform-backend/internal/infrastructure"
+	"github.com/label-platform-backend/internal/infrastructure/predictor"
+	"github.com/label-platform-backend/internal/infrastructure/redis"
+	"github.com/label-platform-backend/internal/infrastructure/storage"
+	"github.com/label-platform-backend/pkg/worker"
+	"github.com/minio/minio-go/v7"
+	"gorm.io/gorm"
+)
+
+// inProcessPredictTimeout bounds a single in-process prediction
+const inProcessPredictTimeout = 2 * time.Minute
+
+// PredictionUseCaseImpl implements the PredictionUseCase interface
+type PredictionUseCaseImpl struct {
+	imageRepo     repository.ImageRepository
+	modelRepo     repository.ModelRepository
+	cacheRepo     repository.PredictionCacheRepository
+	cacheStats    repository.PredictionCacheStatsRepository
+	runRepo       repository.EvaluationRunRepository
+	imageUseCase  domainusecase.ImageUseCase
+	promptUseCase domainusecase.PromptUseCase
+	minioClient   *storage.MinioClient
+
+	// running holds the cancel functions of the in-process jobs by job ID
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+}
+
+// NewPredictionUseCase creates a new prediction use case
+func NewPredictionUseCase(imageRepo repository.ImageRepository, modelRepo repository.ModelRepository, cacheRepo repository.PredictionCacheRepository, cacheStats repository.PredictionCacheStatsRepository, runRepo repository.EvaluationRunRepository, imageUseCase domainusecase.ImageUseCase, promptUseCase domainusecase.PromptUseCase, minioClient *storage.MinioClient) *PredictionUseCaseImpl {
+	return &PredictionUseCaseImpl{
+		imageRepo:     imageRepo,
+		modelRepo:     modelRepo,
+		cacheRepo:     cacheRepo,
+		cacheStats:    cacheStats,
+		runRepo:       runRepo,
+		imageUseCase:  imageUseCase,
+		promptUseCase: promptUseCase,
+		minioClient:   minioClient,
+		running:       map[string]context.CancelFunc{},
+	}
+}
+
+// PredictImage serves cached predictions, pushes the image onto the model
+// queues for the remaining queue models and starts the remaining in-process
+// models. All prompts are resolved before anything is dispatched, so an
+// unknown prompt version fails the whole request. The version requested for
+// all models applies to the models that have it, the others use their
+// active prompt; it is unknown when no model has it.
+func (u *PredictionUseCaseImpl) PredictImage(ctx context.Context, id uuid.UUID, opts domainusecase.PredictOptions) ([]domainusecase.ModelDispatch, error) {
+	image, err := u.imageRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+
+	if opts.Priority == "" {
+		opts.Priority = worker.PriorityNormal
+	}
+	if !worker.ValidPriority(opts.Priority) {
+		return nil, fmt.Errorf("%w: unknown priority %q", domainusecase.ErrInvalidInput, opts.Priority)
+	}
+	if opts.RunID != nil {
+		if opts, err = u.runOptions(ctx, image.ID, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	models, err := u.modelRepo.GetEnabled(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get models: %w", err)
+	}
+
+	queueModels := make([]string, 0, len(redis.ModelQueues))
+	for name := range redis.ModelQueues {
+		queueModels = append(queueModels, name)
+	}
+	sort.Strings(queueModels)
+
+	if len(opts.Models) > 0 {
+		if queueModels, models, err = selectModels(opts.Models, queueModels, models); err != nil {
+			return nil, err
+		}
+	}
+
+	sharedTried, sharedFound := false, false
+	resolve := func(model string) (*entity.PromptTemplate, error) {
+		if v, ok := opts.PromptVersions[model]; ok {
+			return u.promptUseCase.ResolvePrompt(ctx, model, v)
+		}
+		if opts.PromptVersion != "" {
+			sharedTried = true
+			prompt, err := u.promptUseCase.ResolvePrompt(ctx, model, opts.PromptVersion)
+			if !errors.Is(err, domainusecase.ErrInvalidInput) {
+				sharedFound = sharedFound || err == nil
+				return prompt, err
+			}
+		}
+		return u.promptUseCase.ResolvePrompt(ctx, model, "")
+	}
+
+	// Prompt versions per model as requested: the stored prompt's version,
+	// the built-in version of in-process models or none. Predictions are
+	// cached under it.
+	prompts := map[string]*entity.PromptTemplate{}
+	versions := map[string]string{}
+	for _, name := range queueModels {
+		if prompts[name], err = resolve(name); err != nil {
+			return nil, err
+		}
+		if prompts[name] != nil {
+			versions[name] = prompts[name].Version
+		}
+	}
+	for _, model := range models {
+		if prompts[model.Name], err = resolve(model.Name); err != nil {
+			return nil, err
+		}
+		versions[model.Name] = predictor.PromptVersion
+		if prompts[model.Name] != nil {
+			versions[model.Name] = prompts[model.Name].Version
+		}
+	}
+	if sharedTried && !sharedFound {
+		return nil, fmt.Errorf("%w: no model has prompt version %s", domainusecase.ErrInvalidInput, opts.PromptVersion)
+	}
+
+	var imgBytes []byte
+	if image.ContentHash == "" {
+		// Images uploaded before content hashing get their hash on first predict
+		if imgBytes, err = u.readImage(ctx, image); err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(imgBytes)
+		image.ContentHash = hex.EncodeToString(sum[:])
+		if err := u.imageRepo.UpdateContentHash(ctx, image.ID, image.ContentHash); err != nil {
+			return nil, fmt.Errorf("failed to save content hash: %w", err)
+		}
+	}
+
+	var dispatched []domainusecase.ModelDispatch
+	pending := map[string]bool{}
+	for _, name := range append(queueModels, modelNames(models)...) {
+		if u.serveFromCache(ctx, image, name, versions[name], opts) {
+			dispatched = append(dispatched, domainusecase.ModelDispatch{Model: name, Mode: domainusecase.ModeCache, PromptVersion: versions[name]})
+			continue
+		}
+		pending[name] = true
+	}
+	if len(pending) == 0 {
+		return dispatched, nil
+	}
+
+	if imgBytes == nil {
+		if imgBytes, err = u.readImage(ctx, image); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range queueModels {
+		if !pending[name] {
+			continue
+		}
+		job := worker.Job{
+			ID:          image.ID.String(),
+			JobID:       uuid.NewString(),
+			Priority:    opts.Priority,
+			ImageBase64: base64.StdEncoding.EncodeToString(imgBytes),
+			Parameters:  opts.Parameters,
+		}
+		if opts.RunID != nil {
+			job.RunID = opts.RunID.String()
+		}
+		if prompt := prompts[name]; prompt != nil {
+			job.Prompt, job.PromptVersion = prompt.Template, prompt.Version
+		}
+		payload, err := json.Marshal(job)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal job: %w", err)
+		}
+		info := &entity.PredictionJob{
+			ID:            job.JobID,
+			ImageID:       job.ID,
+			Model:         name,
+			RunID:         job.RunID,
+			PromptVersion: job.PromptVersion,
+			Priority:      job.Priority,
+			Status:        worker.JobQueued,
+			Attempt:       opts.Attempt,
+		}
+		if err := redis.EnqueueJob(ctx, info, redis.ModelQueues[name], payload); err != nil {
+			return nil, fmt.Errorf("failed to push job to %s queue: %w", name, err)
+		}
+		dispatched = append(dispatched, domainusecase.ModelDispatch{Model: name, JobID: job.JobID, Mode: domainusecase.ModeQueue, PromptVersion: job.PromptVersion})
+	}
+
+	for _, model := range models {
+		if !pending[model.Name] {
+			continue
+		}
+		p, err := predictor.New(model)
+		if err != nil {
+			log.Printf("[predict] skipping model %s: %v", model.Name, err)
+			continue
+		}
+		req := &worker.Request{ImageID: image.ID.String(), Image: imgBytes, Parameters: opts.Parameters}
+		if prompt := prompts[model.Name]; prompt != nil {
+			req.Prompt, req.PromptVersion = prompt.Template, prompt.Version
+		}
+		now := time.Now()
+		info := &entity.PredictionJob{
+			ID:        uuid.NewString(),
+			ImageID:   image.ID.String(),
+			Model:     model.Name,
+			Priority:  opts.Priority,
+			Status:    worker.JobRunning,
+			Attempt:   opts.Attempt,
+			QueuedAt:  now,
+			StartedAt: &now,
+		}
+		if prompt := prompts[model.Name]; prompt != nil {
+			info.PromptVersion = prompt.Version
+		}
+		if opts.RunID != nil {
+			info.RunID = opts.RunID.String()
+		}
+		if err := redis.EnqueueJob(ctx, info, "", nil); err != nil {
+			return nil, fmt.Errorf("failed to record %s job: %w", model.Name, err)
+		}
+		go u.runPredictor(info.ID, model.Name, p, image.ID, opts.RunID, req, versions[model.Name])
+		dispatched = append(dispatched, domainusecase.ModelDispatch{Model: model.Name, JobID: info.ID, Mode: domainusecase.ModeInProcess, PromptVersion: versions[model.Name]})
+	}
+
+	return dispatched, nil
+}
+
+// runOptions returns the options predicting an image for a run: the run's
+// model, prompt version and parameters. The cache only holds predictions
+// made with default parameters, so runs with parameters bypass it. The run
+// is marked running.
+func (u *PredictionUseCaseImpl) runOptions(ctx context.Context, imageID uuid.UUID, opts domainusecase.PredictOptions) (domainusecase.PredictOptions, error) {
+	runID := *opts.RunID
+	run, err := u.runRepo.GetByID(ctx, runID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domainusecase.PredictOptions{}, fmt.Errorf("%w: unknown run %s", domainusecase.ErrInvalidInput, runID)
+	}
+	if err != nil {
+		return domainusecase.PredictOptions{}, fmt.Errorf("failed to get run: %w", err)
+	}
+
+	if run.Status == entity.RunStatusCancelled {
+		return domainusecase.PredictOptions{}, fmt.Errorf("%w: run %s is cancelled", domainusecase.ErrInvalidInput, runID)
+	}
+
+	results, err := u.runRepo.GetResultsByImage(ctx, imageID)
+	if err != nil {
+		return domainusecase.PredictOptions{}, fmt.Errorf("failed to get run results: %w", err)
+	}
+	inRun := false
+	for _, result := range results {
+		inRun = inRun || result.RunID == runID
+	}
+	if !inRun {
+		return domainusecase.PredictOptions{}, fmt.Errorf("%w: image %s is not part of run %s", domainusecase.ErrInvalidInput, imageID, runID)
+	}
+
+	var parameters map[string]any
+	if len(run.Parameters) > 0 {
+		if err := json.Unmarshal(run.Parameters, &parameters); err != nil {
+			return domainusecase.PredictOptions{}, fmt.Errorf("invalid run parameters: %w", err)
+		}
+	}
+
+	if run.Status == entity.RunStatusCreated {
+		if err := u.runRepo.UpdateStatus(ctx, run.ID, entity.RunStatusRunning); err != nil {
+			return domainusecase.PredictOptions{}, fmt.Errorf("failed to start run: %w", err)
+		}
+	}
+
+	return domainusecase.PredictOptions{
+		PromptVersion: run.PromptVersion,
+		Force:         opts.Force || len(parameters) > 0,
+		Models:        []string{run.Model},
+		RunID:         &run.ID,
+		Parameters:    parameters,
+		Priority:      opts.Priority,
+		Attempt:       opts.Attempt,
+	}, nil
+}
+
+// serveFromCache stores the cached prediction of a model as the image's
+// prediction. It returns false when the model has to run.
+func (u *PredictionUseCaseImpl) serveFromCache(ctx context.Context, image *entity.Image, model, promptVersion string, opts domainusecase.PredictOptions) bool {
+	if opts.Force {
+		u.countCache(ctx, model, redis.CacheBypass)
+		return false
+	}
+
+	entry, err := u.cacheRepo.Get(ctx, image.ContentHash, model, promptVersion)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("[predict] failed to read cache for %s: %v", model, err)
+		}
+		u.countCache(ctx, model, redis.CacheMiss)
+		return false
+	}
+
+	var prediction entity.Prediction
+	if err := json.Unmarshal(entry.Prediction, &prediction); err != nil {
+		log.Printf("[predict] invalid cache entry for %s: %v", model, err)
+		u.countCache(ctx, model, redis.CacheMiss)
+		return false
+	}
+	prediction.Cached = true
+
+	if err := u.imageUseCase.StorePrediction(ctx, image.ID, model, &prediction, opts.RunID); err != nil {
+		log.Printf("[predict] failed to serve cached %s prediction for %s: %v", model, image.ID, err)
+		u.countCache(ctx, model, redis.CacheMiss)
+		return false
+	}
+
+	u.countCache(ctx, model, redis.CacheHit)
+	return true
+}
+
+func (u *PredictionUseCaseImpl) countCache(ctx context.Context, model, outcome string) {
+	if err := u.cacheStats.Incr(ctx, model, outcome); err != nil {
+		log.Printf("[predict] failed to count cache %s for %s: %v", outcome, model, err)
+	}
+}
+
+// GetCacheStats returns hit, miss and bypass counters and the number of
+// cached entries per model
+func (u *PredictionUseCaseImpl) GetCacheStats(ctx context.Context) (map[string]*domainusecase.CacheStats, error) {
+	counters, err := u.cacheStats.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cache counters: %w", err)
+	}
+	entries, err := u.cacheRepo.CountByModel(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count cache entries: %w", err)
+	}
+
+	stats := map[string]*domainusecase.CacheStats{}
+	get := func(model string) *domainusecase.CacheStats {
+		if stats[model] == nil {
+			stats[model] = &domainusecase.CacheStats{}
+		}
+		return stats[model]
+	}
+	for model, c := range counters {
+		s := get(model)
+		s.Hits, s.Misses, s.Bypassed = c[redis.CacheHit], c[redis.CacheMiss], c[redis.CacheBypass]
+		if lookups := s.Hits + s.Misses; lookups > 0 {
+			s.HitRate = float64(s.Hits) / float64(lookups)
+		}
+	}
+	for model, n := range entries {
+		get(model).Entries = n
+	}
+	return stats, nil
+}
+
+// ClearCache removes the cached predictions and counters of a model, or of
+// every model when model is empty
+func (u *PredictionUseCaseImpl) ClearCache(ctx context.Context, model string) (int64, error) {
+	deleted, err := u.cacheRepo.Delete(ctx, model)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear cache: %w", err)
+	}
+	if err := u.cacheStats.Reset(ctx, model); err != nil {
+		return deleted, fmt.Errorf("failed to reset cache counters: %w", err)
+	}
+	return deleted, nil
+}
+
+// selectModels keeps the queue and in-process models named in names. Every
+// name must be a queue model or an enabled in-process model.
+func selectModels(names []string, queueModels []string, models []*entity.Model) ([]string, []*entity.Model, error) {
+	var selectedQueue []string
+	var selectedModels []*entity.Model
+	for _, name := range names {
+		found := false
+		for _, q := range queueModels {
+			if q == name {
+				selectedQueue = append(selectedQueue, q)
+				found = true
+			}
+		}
+		for _, m := range models {
+			if m.Name == name {
+				selectedModels = append(selectedModels, m)
+				found = true
+			}
+		}
+		if !found {
+			return nil, nil, fmt.Errorf("%w: unknown or disabled model %q", domainusecase.ErrInvalidInput, name)
+		}
+	}
+	return selectedQueue, selectedModels, nil
+}
+
+func modelNames(models []*entity.Model) []string {
+	names := make([]string, len(models))
+	for i, m := range models {
+		names[i] = m.Name
+	}
+	return names
+}
+
+func (u *PredictionUseCaseImpl) readImage(ctx context.Context, image *entity.Image) ([]byte, error) {
+	obj, err := u.minioClient.GetClient().GetObject(ctx, u.minioClient.GetBucket(), image.MinioPath, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image from MinIO: %w", err)
+	}
+	defer obj.Close()
+
+	imgBytes, err := io.ReadAll(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image data: %w", err)
+	}
+	return imgBytes, nil
+}
+
+func (u *PredictionUseCaseImpl) runPredictor(jobID, name string, p worker.Predictor, imageID uuid.UUID, runID *uuid.UUID, req *worker.Request, cacheVersion string) {
+	// The HTTP request that triggered the prediction is already answered, so
+	// the prediction gets its own context, cancelled with the job
+	ctx, cancel := context.WithTimeout(context.Background(), inProcessPredictTimeout)
+	defer cancel()
+	u.mu.Lock()
+	u.running[jobID] = cancel
+	u.mu.Unlock()
+	defer func() {
+		u.mu.Lock()
+		delete(u.running, jobID)
+		u.mu.Unlock()
+	}()
+
+	var errMsg string
+	resultStr, promptVersion, err := predictInProcess(ctx, p, req)
+	if err != nil {
+		errMsg = err.Error()
+	}
+
+	// Saving must not be cut short by the predict timeout
+	ctx = context.Background()
+	if _, ok, err := u.CompleteJob(ctx, jobID); err != nil {
+		log.Printf("[predict] failed to complete job %s: %v", jobID, err)
+	} else if !ok {
+		log.Printf("[predict] discarding %s prediction for %s: job %s was cancelled or timed out", name, imageID, jobID)
+		return
+	}
+
+	report := domainusecase.PredictionReport{
+		ImageID:       imageID,
+		Model:         name,
+		PromptVersion: promptVersion,
+		CacheVersion:  &cacheVersion,
+		RunID:         runID,
+		Result:        resultStr,
+		Error:         errMsg,
+	}
+	if err := u.imageUseCase.SavePrediction(ctx, report); err != nil {
+		log.Printf("[predict] failed to save %s prediction for %s: %v", name, imageID, err)
+	}
+}
+
+// predictInProcess runs a predictor and returns its output and the prompt
+// version it used. A predictor returning no result fails like one returning
+// an error, as in worker.Worker.
+func predictInProcess(ctx context.Context, p worker.Predictor, req *worker.Request) (string, string, error) {
+	result, err := p.Predict(ctx, req)
+	if err == nil && result == nil {
+		err = errors.New("predictor returned no result")
+	}
+	if err != nil {
+		return "", req.PromptVersion, err
+	}
+
+	promptVersion := req.PromptVersion
+	if result.PromptVersion != "" {
+		promptVersion = result.PromptVersion
+	}
+	// Keep the model's own output as raw; it is normalized on save
+	if result.Raw != "" {
+		return result.Raw, promptVersion, nil
+	}
+	raw, err := result.Encode()
+	return raw, promptVersion, err
+}
+
+// GetJobs lists the queued and running jobs matching the filter
+func (u *PredictionUseCaseImpl) GetJobs(ctx context.Context, filter domainusecase.JobFilter) ([]*entity.PredictionJob, error) {
+	jobs, err := redis.ActiveJobs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	matching := []*entity.PredictionJob{}
+	for _, job := range jobs {
+		if matchesJob(filter, job) {
+			matching = append(matching, job)
+		}
+	}
+	return matching, nil
+}
+
+// CancelJobs cancels the active jobs matching the filter. Queue workers skip
+// or abort cancelled jobs; in-process predictions are cancelled directly.
+func (u *PredictionUseCaseImpl) CancelJobs(ctx context.Context, filter domainusecase.JobFilter) ([]*entity.PredictionJob, error) {
+	jobs, err := u.GetJobs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelled := []*entity.PredictionJob{}
+	for _, job := range jobs {
+		ok, err := redis.CancelJob(ctx, job.ID)
+		if err != nil {
+			return cancelled, fmt.Errorf("failed to cancel job %s: %w", job.ID, err)
+		}
+		if !ok {
+			continue
+		}
+		job.Status = worker.JobCancelled
+		cancelled = append(cancelled, job)
+		u.stopRunning(job.ID)
+
+		if err := u.releaseRunResult(ctx, job); err != nil {
+			log.Printf("[predict] failed to release run result of job %s: %v", job.ID, err)
+		}
+	}
+	return cancelled, nil
+}
+
+// CompleteJob marks a job done unless it was cancelled
+func (u *PredictionUseCaseImpl) CompleteJob(ctx context.Context, jobID string) (*entity.PredictionJob, bool, error) {
+	ok, err := redis.FinishJob(ctx, jobID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to complete job: %w", err)
+	}
+	if !ok {
+		return nil, false, nil
+	}
+	job, err := redis.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, true, nil
+}
+
+// TimeOutJob gives up on an active job and stops it when it runs in-process
+func (u *PredictionUseCaseImpl) TimeOutJob(ctx context.Context, jobID string) (bool, error) {
+	ok, err := redis.TimeOutJob(ctx, jobID)
+	if err != nil {
+		return false, fmt.Errorf("failed to time out job: %w", err)
+	}
+	if ok {
+		u.stopRunning(jobID)
+	}
+	return ok, nil
+}
+
+// RetryJob predicts the image of a job again with the same model, run,
+// prompt version and priority
+func (u *PredictionUseCaseImpl) RetryJob(ctx context.Context, job *entity.PredictionJob) error {
+	imageID, err := uuid.Parse(job.ImageID)
+	if err != nil {
+		return fmt.Errorf("invalid job image: %w", err)
+	}
+	opts := domainusecase.PredictOptions{
+		PromptVersion: job.PromptVersion,
+		Models:        []string{job.Model},
+		Priority:      job.Priority,
+		Attempt:       job.Attempt + 1,
+	}
+	if job.RunID != "" {
+		runID, err := uuid.Parse(job.RunID)
+		if err != nil {
+			return fmt.Errorf("invalid job run: %w", err)
+		}
+		opts.RunID = &runID
+	}
+	_, err = u.PredictImage(ctx, imageID, opts)
+	return err
+}
+
+// FailJob stores a failed prediction for the job, failing its run result
+func (u *PredictionUseCaseImpl) FailJob(ctx context.Context, job *entity.PredictionJob, reason string) error {
+	imageID, err := uuid.Parse(job.ImageID)
+	if err != nil {
+		return fmt.Errorf("invalid job image: %w", err)
+	}
+	report := domainusecase.PredictionReport{
+		ImageID:       imageID,
+		Model:         job.Model,
+		PromptVersion: job.PromptVersion,
+		Error:         reason,
+	}
+	if job.RunID != "" {
+		runID, err := uuid.Parse(job.RunID)
+		if err != nil {
+			return fmt.Errorf("invalid job run: %w", err)
+		}
+		report.RunID = &runID
+	}
+	return u.imageUseCase.SavePrediction(ctx, report)
+}
+
+// RecordJobEvent counts a job event and forwards it to WEBHOOK_URL when set
+func (u *PredictionUseCaseImpl) RecordJobEvent(ctx context.Context, event *entity.JobEvent) {
+	log.Printf("[jobs] %s job %s (image %s, model %s, attempt %d): %s", event.Type, event.Job.ID, event.Job.ImageID, event.Job.Model, event.Job.Attempt, event.Reason)
+	if err := redis.IncrJobStat(ctx, event.Job.Model, event.Type); err != nil {
+		log.Printf("[jobs] failed to count %s event: %v", event.Type, err)
+	}
+	if webhookURL := os.Getenv("WEBHOOK_URL"); webhookURL != "" {
+		if err := infrastructure.NotifyPredictResult(webhookURL, event); err != nil {
+			log.Printf("[jobs] failed to send %s event: %v", event.Type, err)
+		}
+	}
+}
+
+// GetJobStats returns the timed-out, retried and failed job counters per model
+func (u *PredictionUseCaseImpl) GetJobStats(ctx context.Context) (map[string]map[string]int64, error) {
+	stats, err := redis.GetJobStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job stats: %w", err)
+	}
+	return stats, nil
+}
+
+// stopRunning cancels the context of an in-process job
+func (u *PredictionUseCaseImpl) stopRunning(jobID string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if cancel, ok := u.running[jobID]; ok {
+		cancel()
+	}
+}
+
+// releaseRunResult sets the run result waiting for a job pending again
+func (u *PredictionUseCaseImpl) releaseRunResult(ctx context.Context, job *entity.PredictionJob) error {
+	if job.RunID == "" {
+		return nil
+	}
+	runID, err := uuid.Parse(job.RunID)
+	if err != nil {
+		return err
+	}
+	imageID, err := uuid.Parse(job.ImageID)
+	if err != nil {
+		return err
+	}
+	return u.runRepo.ReleaseQueued(ctx, runID, imageID)
+}
+
+func matchesJob(filter domainusecase.JobFilter, job *entity.PredictionJob) bool {
+	if filter.ImageID != nil && job.ImageID != filter.ImageID.String() {
+		return false
+	}
+	if filter.Model != "" && job.Model != filter.Model {
+		return false
+	}
+	if len(filter.RunIDs) > 0 {
+		for _, runID := range filter.RunIDs {
+			if job.RunID == runID.String() {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,289 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
+	"github.com/label-platform-backend/internal/infrastructure/redis"
+	"github.com/label-platform-backend/pkg/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// fakeCacheRepo keeps cache entries by content hash, model and prompt
+// version and records the lookups
+type fakeCacheRepo struct {
+	entries map[string]*entity.PredictionCacheEntry
+	lookups []string
+}
+
+func cacheKey(contentHash, model, promptVersion string) string {
+	return contentHash + "/" + model + "/" + promptVersion
+}
+
+func (r *fakeCacheRepo) Get(ctx context.Context, contentHash, model, promptVersion string) (*entity.PredictionCacheEntry, error) {
+	key := cacheKey(contentHash, model, promptVersion)
+	r.lookups = append(r.lookups, key)
+	entry, ok := r.entries[key]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return entry, nil
+}
+
+func (r *fakeCacheRepo) Put(ctx context.Context, entry *entity.PredictionCacheEntry) error {
+	if r.entries == nil {
+		r.entries = map[string]*entity.PredictionCacheEntry{}
+	}
+	r.entries[cacheKey(entry.ContentHash, entry.Model, entry.PromptVersion)] = entry
+	return nil
+}
+
+func (r *fakeCacheRepo) CountByModel(ctx context.Context) (map[string]int64, error) {
+	counts := map[string]int64{}
+	for _, entry := range r.entries {
+		counts[entry.Model]++
+	}
+	return counts, nil
+}
+
+func (r *fakeCacheRepo) Delete(ctx context.Context, model string) (int64, error) {
+	var deleted int64
+	for key, entry := range r.entries {
+		if model == "" || entry.Model == model {
+			delete(r.entries, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// fakeCacheStats counts cache outcomes in memory
+type fakeCacheStats struct {
+	counters map[string]map[string]int64
+}
+
+func (s *fakeCacheStats) Incr(ctx context.Context, model, outcome string) error {
+	if s.counters == nil {
+		s.counters = map[string]map[string]int64{}
+	}
+	if s.counters[model] == nil {
+		s.counters[model] = map[string]int64{}
+	}
+	s.counters[model][outcome]++
+	return nil
+}
+
+func (s *fakeCacheStats) GetAll(ctx context.Context) (map[string]map[string]int64, error) {
+	return s.counters, nil
+}
+
+func (s *fakeCacheStats) Reset(ctx context.Context, model string) error {
+	if model == "" {
+		s.counters = nil
+	} else {
+		delete(s.counters, model)
+	}
+	return nil
+}
+
+// fakeImageUseCase records the predictions stored through it, failing when
+// storeErr is set
+type fakeImageUseCase struct {
+	domainusecase.ImageUseCase
+	stored   map[string]*entity.Prediction
+	storeErr error
+}
+
+func (f *fakeImageUseCase) StorePrediction(ctx context.Context, id uuid.UUID, model string, prediction *entity.Prediction, runID *uuid.UUID) error {
+	if f.storeErr != nil {
+		return f.storeErr
+	}
+	if f.stored == nil {
+		f.stored = map[string]*entity.Prediction{}
+	}
+	f.stored[model] = prediction
+	return nil
+}
+
+// fakePromptUseCase resolves the prompt versions of versions, the first
+// being the active one
+type fakePromptUseCase struct {
+	domainusecase.PromptUseCase
+	versions []string
+}
+
+func (f *fakePromptUseCase) ResolvePrompt(ctx context.Context, model, version string) (*entity.PromptTemplate, error) {
+	if version == "" {
+		version = f.versions[0]
+	}
+	for _, v := range f.versions {
+		if v == version {
+			return &entity.PromptTemplate{Model: model, Version: v, Template: "prompt " + v}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown prompt version %s", domainusecase.ErrInvalidInput, version)
+}
+
+type fakeModelRepo struct {
+	repository.ModelRepository
+	models []*entity.Model
+}
+
+func (r *fakeModelRepo) GetEnabled(ctx context.Context) ([]*entity.Model, error) {
+	return r.models, nil
+}
+
+// cachedPrediction is a cache entry of a prediction with one button
+func cachedPrediction(t *testing.T, contentHash, model, promptVersion string) *entity.PredictionCacheEntry {
+	prediction, err := json.Marshal(entity.Prediction{
+		PromptVersion: promptVersion,
+		Elements:      []entity.Element{{Type: "button", BBox: entity.BBox{X: 1, Y: 2, Width: 3, Height: 4}}},
+	})
+	require.NoError(t, err)
+	return &entity.PredictionCacheEntry{ContentHash: contentHash, Model: model, PromptVersion: promptVersion, Prediction: datatypes.JSON(prediction)}
+}
+
+func newCacheFixture(t *testing.T) (*PredictionUseCaseImpl, *entity.Image, *fakeCacheRepo, *fakeCacheStats, *fakeImageUseCase) {
+	image := &entity.Image{ID: uuid.New(), ContentHash: "abc"}
+	cache := &fakeCacheRepo{}
+	require.NoError(t, cache.Put(context.Background(), cachedPrediction(t, "abc", "local", "v2")))
+	stats := &fakeCacheStats{}
+	images := &fakeImageUseCase{}
+	u := &PredictionUseCaseImpl{
+		imageRepo:     newFakeImageRepo(image),
+		modelRepo:     &fakeModelRepo{models: []*entity.Model{{Name: "local", Provider: entity.ProviderOpenAI}}},
+		cacheRepo:     cache,
+		cacheStats:    stats,
+		imageUseCase:  images,
+		promptUseCase: &fakePromptUseCase{versions: []string{"v1", "v2"}},
+	}
+	return u, image, cache, stats, images
+}
+
+func TestPredictImage_CacheHitUnderRequestedPromptVersion(t *testing.T) {
+	u, image, cache, stats, images := newCacheFixture(t)
+
+	dispatched, err := u.PredictImage(context.Background(), image.ID, domainusecase.PredictOptions{Models: []string{"local"}, PromptVersion: "v2"})
+	require.NoError(t, err)
+	assert.Equal(t, []domainusecase.ModelDispatch{{Model: "local", Mode: domainusecase.ModeCache, PromptVersion: "v2"}}, dispatched)
+	// Looked up under the requested version, not the active v1
+	assert.Equal(t, []string{"abc/local/v2"}, cache.lookups)
+
+	require.Contains(t, images.stored, "local")
+	assert.True(t, images.stored["local"].Cached)
+	assert.Len(t, images.stored["local"].Elements, 1)
+	assert.Equal(t, map[string]int64{redis.CacheHit: 1}, stats.counters["local"])
+}
+
+func TestServeFromCache(t *testing.T) {
+	tests := []struct {
+		name          string
+		promptVersion string
+		opts          domainusecase.PredictOptions
+		storeErr      error
+		corrupt       bool
+		served        bool
+		outcome       string
+		lookups       int
+	}{
+		{name: "hit", promptVersion: "v2", served: true, outcome: redis.CacheHit, lookups: 1},
+		{name: "miss on another prompt version", promptVersion: "v1", outcome: redis.CacheMiss, lookups: 1},
+		{name: "force bypasses the cache", promptVersion: "v2", opts: domainusecase.PredictOptions{Force: true}, outcome: redis.CacheBypass},
+		{name: "invalid entry", promptVersion: "v2", corrupt: true, outcome: redis.CacheMiss, lookups: 1},
+		{name: "failed store", promptVersion: "v2", storeErr: errors.New("db down"), outcome: redis.CacheMiss, lookups: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, image, cache, stats, images := newCacheFixture(t)
+			images.storeErr = tt.storeErr
+			if tt.corrupt {
+				cache.entries[cacheKey("abc", "local", "v2")].Prediction = datatypes.JSON(`not json`)
+			}
+
+			served := u.serveFromCache(context.Background(), image, "local", tt.promptVersion, tt.opts)
+			assert.Equal(t, tt.served, served)
+			assert.Len(t, cache.lookups, tt.lookups)
+			assert.Equal(t, map[string]int64{tt.outcome: 1}, stats.counters["local"])
+			if !tt.served {
+				assert.Empty(t, images.stored)
+			}
+		})
+	}
+}
+
+func TestGetCacheStats(t *testing.T) {
+	u, image, _, _, _ := newCacheFixture(t)
+	ctx := context.Background()
+
+	u.serveFromCache(ctx, image, "local", "v2", domainusecase.PredictOptions{})
+	u.serveFromCache(ctx, image, "local", "v2", domainusecase.PredictOptions{})
+	u.serveFromCache(ctx, image, "local", "v1", domainusecase.PredictOptions{})
+	u.serveFromCache(ctx, image, "local", "v2", domainusecase.PredictOptions{Force: true})
+	u.serveFromCache(ctx, image, "other", "", domainusecase.PredictOptions{})
+
+	stats, err := u.GetCacheStats(ctx)
+	require.NoError(t, err)
+	local := stats["local"]
+	require.NotNil(t, local)
+	assert.Equal(t, []int64{2, 1, 1, 1}, []int64{local.Hits, local.Misses, local.Bypassed, local.Entries})
+	assert.InDelta(t, 2.0/3, local.HitRate, 1e-9, "bypasses are not lookups")
+	assert.Equal(t, &domainusecase.CacheStats{Misses: 1}, stats["other"])
+}
+
+func TestClearCache(t *testing.T) {
+	u, image, cache, stats, _ := newCacheFixture(t)
+	ctx := context.Background()
+	require.NoError(t, cache.Put(ctx, cachedPrediction(t, "abc", "other", "")))
+	u.serveFromCache(ctx, image, "local", "v2", domainusecase.PredictOptions{})
+	u.serveFromCache(ctx, image, "other", "", domainusecase.PredictOptions{})
+
+	deleted, err := u.ClearCache(ctx, "local")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.NotContains(t, stats.counters, "local")
+	assert.Contains(t, stats.counters, "other")
+
+	deleted, err = u.ClearCache(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.Empty(t, stats.counters)
+}
+
+func TestPredictInProcess(t *testing.T) {
+	req := &worker.Request{ImageID: "img", PromptVersion: "v1"}
+	predictor := func(result *worker.Result, err error) worker.Predictor {
+		return worker.PredictorFunc(func(ctx context.Context, req *worker.Request) (*worker.Result, error) {
+			return result, err
+		})
+	}
+
+	raw, version, err := predictInProcess(context.Background(), predictor(&worker.Result{Raw: `{"elements":[]}`}, nil), req)
+	require.NoError(t, err)
+	assert.Equal(t, `{"elements":[]}`, raw)
+	assert.Equal(t, "v1", version)
+
+	raw, version, err = predictInProcess(context.Background(), predictor(&worker.Result{
+		Elements:      []worker.Element{{Type: "button"}},
+		PromptVersion: "builtin-2",
+	}, nil), req)
+	require.NoError(t, err)
+	assert.Contains(t, raw, `"button"`, "encoded from the elements without raw output")
+	assert.Equal(t, "builtin-2", version)
+
+	_, version, err = predictInProcess(context.Background(), predictor(nil, errors.New("rate limited")), req)
+	assert.EqualError(t, err, "rate limited")
+	assert.Equal(t, "v1", version)
+
+	_, _, err = predictInProcess(context.Background(), predictor(nil, nil), req)
+	assert.EqualError(t, err, "predictor returned no result")
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/label-platform-backend/internal/application/ensemble"
+	"github.com/label-platform-backend/internal/domain/entity"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
+	"gorm.io/datatypes"
+)
+
+// errNoPrelabelPrediction is returned when none of the pre-labeling models
+// has a usable prediction of an image
+var errNoPrelabelPrediction = errors.New("no usable prediction of the pre-labeling models")
+
+// prelabelSetting normalizes the models and minimum votes of a pre-labeling
+// setting. Zero votes means a majority of the models.
+func prelabelSetting(models []string, minVotes int) ([]string, int, error) {
+	trimmed := make([]string, len(models))
+	for i, model := range models {
+		trimmed[i] = strings.TrimSpace(model)
+	}
+	models = uniqueStrings(trimmed)
+	if minVotes < 0 || minVotes > len(models) {
+		return nil, 0, fmt.Errorf("%w: min_votes must be between 0 and the number of models", domainusecase.ErrInvalidInput)
+	}
+	return models, minVotes, nil
+}
+
+// buildDraft builds the draft ground truth of an image from the predictions
+// of models. The elements of a single model are copied as they are; with
+// several models, they are combined by the ensemble consensus and kept when
+// at least minVotes models found them.
+func buildDraft(image *entity.Image, models []string, minVotes int) (*entity.Draft, error) {
+	predictions := imagePredictions(image)
+	var elements [][]entity.Element
+	for _, model := range models {
+		prediction, ok := predictions[model]
+		if !ok || prediction.Error != "" || prediction.ParseError != "" {
+			continue
+		}
+		tagged := make([]entity.Element, len(prediction.Elements))
+		for i, el := range prediction.Elements {
+			el.Source = model
+			tagged[i] = el
+		}
+		elements = append(elements, tagged)
+	}
+	if len(elements) == 0 {
+		return nil, errNoPrelabelPrediction
+	}
+
+	draft := &entity.Draft{Models: models, CreatedAt: time.Now()}
+	if len(models) == 1 {
+		draft.Elements = elements[0]
+		return draft, nil
+	}
+	if minVotes == 0 {
+		minVotes = len(models)/2 + 1
+	}
+	draft.MinVotes = minVotes
+	draft.Elements = ensemble.Combine(elements, ensemble.Options{MinVotes: minVotes})
+	return draft, nil
+}
+
+func marshalDraft(draft *entity.Draft) (datatypes.JSON, error) {
+	draftBytes, err := json.Marshal(draft)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal draft: %w", err)
+	}
+	return datatypes.JSON(draftBytes), nil
+}
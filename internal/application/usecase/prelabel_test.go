@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+var (
+	submitButton = entity.Element{Type: "button", Text: "Submit", BBox: entity.BBox{X: 100, Y: 200, Width: 80, Height: 40}, Confidence: 0.9}
+	emailInput   = entity.Element{Type: "input", Text: "Email", BBox: entity.BBox{X: 100, Y: 100, Width: 200, Height: 40}, Confidence: 0.8}
+	helpLink     = entity.Element{Type: "link", Text: "Help", BBox: entity.BBox{X: 500, Y: 500, Width: 40, Height: 20}, Confidence: 0.6}
+)
+
+// predictedImage is an image of project with the predictions of models
+func predictedImage(t *testing.T, project *uuid.UUID, predictions map[string]entity.Prediction) *entity.Image {
+	labels, err := json.Marshal(predictions)
+	require.NoError(t, err)
+	return &entity.Image{ID: uuid.New(), ProjectID: project, PredictedLabels: datatypes.JSON(labels)}
+}
+
+func sources(elements []entity.Element) []string {
+	var names []string
+	for _, el := range elements {
+		names = append(names, el.Source)
+	}
+	return names
+}
+
+func TestPrelabelSetting(t *testing.T) {
+	models, minVotes, err := prelabelSetting([]string{" gpt ", "claude", "gpt", ""}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpt", "claude"}, models)
+	assert.Equal(t, 2, minVotes)
+
+	models, _, err = prelabelSetting(nil, 0)
+	require.NoError(t, err)
+	assert.Empty(t, models)
+
+	_, _, err = prelabelSetting([]string{"gpt", "gpt"}, 2)
+	assert.ErrorIs(t, err, domainusecase.ErrInvalidInput, "duplicates count once")
+	_, _, err = prelabelSetting([]string{"gpt"}, -1)
+	assert.ErrorIs(t, err, domainusecase.ErrInvalidInput)
+}
+
+func TestBuildDraft(t *testing.T) {
+	image := predictedImage(t, nil, map[string]entity.Prediction{
+		"gpt":    {Elements: []entity.Element{submitButton, emailInput}},
+		"claude": {Elements: []entity.Element{submitButton, helpLink}},
+		"gemini": {Elements: []entity.Element{emailInput}},
+		"broken": {Raw: "oops", ParseError: "no JSON found"},
+		"failed": {Error: "rate limited"},
+	})
+
+	tests := []struct {
+		name     string
+		models   []string
+		minVotes int
+		want     []string
+		sources  []string
+		draftMin int
+	}{
+		{name: "single model", models: []string{"gpt"}, want: []string{"button", "input"}, sources: []string{"gpt", "gpt"}},
+		{name: "majority of three", models: []string{"gpt", "claude", "gemini"}, want: []string{"button", "input"}, sources: []string{"ensemble", "ensemble"}, draftMin: 2},
+		{name: "one vote", models: []string{"gpt", "claude"}, minVotes: 1, want: []string{"button", "input", "link"}, sources: []string{"ensemble", "ensemble", "ensemble"}, draftMin: 1},
+		{name: "unusable predictions are left out", models: []string{"broken", "failed", "claude"}, minVotes: 1, want: []string{"button", "link"}, sources: []string{"ensemble", "ensemble"}, draftMin: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			draft, err := buildDraft(image, tt.models, tt.minVotes)
+			require.NoError(t, err)
+			assert.Equal(t, tt.models, draft.Models)
+			assert.Equal(t, tt.draftMin, draft.MinVotes)
+			var types []string
+			for _, el := range draft.Elements {
+				types = append(types, el.Type)
+			}
+			assert.ElementsMatch(t, tt.want, types)
+			assert.Equal(t, tt.sources, sources(draft.Elements))
+		})
+	}
+
+	_, err := buildDraft(image, []string{"broken", "failed", "missing"}, 0)
+	assert.ErrorIs(t, err, errNoPrelabelPrediction)
+	assert.Empty(t, imagePredictions(image)["gpt"].Elements[0].Source, "predictions are not tagged in place")
+}
+
+func TestDraftGroundTruth(t *testing.T) {
+	project := &entity.Project{ID: uuid.New(), PrelabelModels: []string{"claude"}}
+	predictions := map[string]entity.Prediction{
+		"gpt":    {Elements: []entity.Element{submitButton}},
+		"claude": {Elements: []entity.Element{emailInput}},
+	}
+	inProject := predictedImage(t, &project.ID, predictions)
+	standalone := predictedImage(t, nil, predictions)
+	images := newFakeImageRepo(inProject, standalone)
+	u := &ImageUseCaseImpl{imageRepo: images, projectRepo: newFakeProjectRepo(project)}
+	ctx := context.Background()
+
+	image, err := u.DraftGroundTruth(ctx, standalone.ID, domainusecase.PrelabelRequest{Models: []string{"gpt"}})
+	require.NoError(t, err)
+	assert.JSONEq(t, string(images.images[standalone.ID].Draft), string(image.Draft))
+	draft := images.draft(standalone.ID)
+	require.NotNil(t, draft)
+	assert.Equal(t, []string{"gpt"}, draft.Models)
+	assert.Equal(t, []string{"gpt"}, sources(draft.Elements))
+
+	// The project's models apply when none is requested
+	_, err = u.DraftGroundTruth(ctx, inProject.ID, domainusecase.PrelabelRequest{})
+	require.NoError(t, err)
+	draft = images.draft(inProject.ID)
+	require.NotNil(t, draft)
+	assert.Equal(t, []string{"claude"}, sources(draft.Elements))
+
+	tests := []struct {
+		name    string
+		id      uuid.UUID
+		request domainusecase.PrelabelRequest
+		err     error
+	}{
+		{name: "no model", id: standalone.ID, err: domainusecase.ErrInvalidInput},
+		{name: "no usable prediction", id: standalone.ID, request: domainusecase.PrelabelRequest{Models: []string{"gemini"}}, err: domainusecase.ErrInvalidInput},
+		{name: "invalid min votes", id: standalone.ID, request: domainusecase.PrelabelRequest{Models: []string{"gpt"}, MinVotes: 2}, err: domainusecase.ErrInvalidInput},
+		{name: "unknown image", id: uuid.New(), request: domainusecase.PrelabelRequest{Models: []string{"gpt"}}, err: gorm.ErrRecordNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := u.DraftGroundTruth(ctx, tt.id, tt.request)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestPrelabel_OnPrediction(t *testing.T) {
+	project := &entity.Project{ID: uuid.New(), PrelabelModels: []string{"gpt"}}
+	predictions := map[string]entity.Prediction{
+		"gpt":    {Elements: []entity.Element{submitButton}},
+		"claude": {Elements: []entity.Element{emailInput}},
+	}
+	unlabeled := predictedImage(t, &project.ID, predictions)
+	labeled := predictedImage(t, &project.ID, predictions)
+	labeled.GroundTruth = datatypes.JSON(`{"elements":[{"type":"button","bbox":{"x":1,"y":1,"width":1,"height":1}}]}`)
+	outside := predictedImage(t, nil, predictions)
+	images := newFakeImageRepo(unlabeled, labeled, outside)
+	u := &ImageUseCaseImpl{imageRepo: images, projectRepo: newFakeProjectRepo(project)}
+	ctx := context.Background()
+
+	u.prelabel(ctx, unlabeled, "claude")
+	assert.Nil(t, images.draft(unlabeled.ID), "not a pre-labeling model of the project")
+
+	u.prelabel(ctx, unlabeled, "gpt")
+	draft := images.draft(unlabeled.ID)
+	require.NotNil(t, draft)
+	assert.Equal(t, []string{"gpt"}, sources(draft.Elements))
+
+	u.prelabel(ctx, labeled, "gpt")
+	assert.Nil(t, images.draft(labeled.ID), "images with ground truth keep it")
+	u.prelabel(ctx, outside, "gpt")
+	assert.Nil(t, images.draft(outside.ID), "no project")
+}
+
+func TestProjectPrelabel(t *testing.T) {
+	project := &entity.Project{ID: uuid.New(), PrelabelModels: []string{"gpt", "claude"}, PrelabelMinVotes: 1}
+	both := predictedImage(t, &project.ID, map[string]entity.Prediction{
+		"gpt":    {Elements: []entity.Element{submitButton}},
+		"claude": {Elements: []entity.Element{submitButton}},
+	})
+	failed := predictedImage(t, &project.ID, map[string]entity.Prediction{"gpt": {Error: "rate limited"}})
+	labeled := predictedImage(t, &project.ID, map[string]entity.Prediction{"gpt": {Elements: []entity.Element{submitButton}}})
+	labeled.GroundTruth = datatypes.JSON(`{"elements":[]}`)
+	other := predictedImage(t, nil, map[string]entity.Prediction{"gpt": {Elements: []entity.Element{submitButton}}})
+	images := newFakeImageRepo(both, failed, labeled, other)
+	empty := &entity.Project{ID: uuid.New()}
+	u := &ProjectUseCaseImpl{projectRepo: newFakeProjectRepo(project, empty), imageRepo: images}
+
+	drafted, skipped, err := u.Prelabel(context.Background(), project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, drafted)
+	assert.Equal(t, 1, skipped, "no usable prediction")
+	draft := images.draft(both.ID)
+	require.NotNil(t, draft)
+	assert.Equal(t, 1, draft.MinVotes)
+	assert.Equal(t, []string{"ensemble"}, sources(draft.Elements))
+	assert.Nil(t, images.draft(labeled.ID))
+	assert.Nil(t, images.draft(other.ID))
+
+	_, _, err = u.Prelabel(context.Background(), empty.ID)
+	assert.ErrorIs(t, err, domainusecase.ErrInvalidInput)
+	_, _, err = u.Prelabel(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
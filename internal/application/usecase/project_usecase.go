@@ -0,0 +1,416 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/application/evaluator"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
+)
+
+// ProjectUseCaseImpl implements the ProjectUseCase interface
+type ProjectUseCaseImpl struct {
+	projectRepo repository.ProjectRepository
+	imageRepo   repository.ImageRepository
+}
+
+// NewProjectUseCase creates a new project use case
+func NewProjectUseCase(projectRepo repository.ProjectRepository, imageRepo repository.ImageRepository) *ProjectUseCaseImpl {
+	return &ProjectUseCaseImpl{
+		projectRepo: projectRepo,
+		imageRepo:   imageRepo,
+	}
+}
+
+// CreateProject validates and creates a new project
+func (u *ProjectUseCaseImpl) CreateProject(ctx context.Context, project *entity.Project) error {
+	if err := validateProject(project); err != nil {
+		return err
+	}
+
+	project.ID = uuid.New()
+	project.CreatedAt = time.Now()
+	project.UpdatedAt = time.Now()
+
+	if err := u.projectRepo.Create(ctx, project); err != nil {
+		return fmt.Errorf("failed to save project: %w", err)
+	}
+	return nil
+}
+
+// GetProjectByID retrieves a project by its ID
+func (u *ProjectUseCaseImpl) GetProjectByID(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+	return u.projectRepo.GetByID(ctx, id)
+}
+
+// GetAllProjects retrieves all projects
+func (u *ProjectUseCaseImpl) GetAllProjects(ctx context.Context) ([]*entity.Project, error) {
+	return u.projectRepo.GetAll(ctx)
+}
+
+// UpdateProject validates and updates a project
+func (u *ProjectUseCaseImpl) UpdateProject(ctx context.Context, project *entity.Project) error {
+	if err := validateProject(project); err != nil {
+		return err
+	}
+
+	project.UpdatedAt = time.Now()
+
+	if err := u.projectRepo.Update(ctx, project); err != nil {
+		return fmt.Errorf("failed to update project: %w", err)
+	}
+	return nil
+}
+
+// GetUncertainImages ranks the images of a project that still need ground
+// truth by the disagreement, lack of confidence and element density of the
+// models' predictions, so annotators label the most informative ones first
+func (u *ProjectUseCaseImpl) GetUncertainImages(ctx context.Context, projectID uuid.UUID, filter repository.ImageFilter, limit int) ([]*entity.ImageUncertainty, error) {
+	if limit < 1 {
+		return nil, fmt.Errorf("%w: limit must be positive", domainusecase.ErrInvalidInput)
+	}
+	if _, err := u.projectRepo.GetByID(ctx, projectID); err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	filter.ProjectID = &projectID
+
+	images, err := u.imageRepo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+	unlabeled := images[:0]
+	for _, image := range images {
+		if _, err := groundTruthElements(image); errors.Is(err, errNoGroundTruth) {
+			unlabeled = append(unlabeled, image)
+		}
+	}
+
+	ranked := rankImages(unlabeled)
+	return ranked[:min(limit, len(ranked))], nil
+}
+
+// Prelabel drafts the ground truth of every image of a project without
+// ground truth from the project's pre-labeling models. Images without a
+// usable prediction of these models are skipped.
+func (u *ProjectUseCaseImpl) Prelabel(ctx context.Context, projectID uuid.UUID) (int, int, error) {
+	project, err := u.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get project: %w", err)
+	}
+	if len(project.PrelabelModels) == 0 {
+		return 0, 0, fmt.Errorf("%w: project has no pre-labeling model", domainusecase.ErrInvalidInput)
+	}
+
+	images, err := u.imageRepo.List(ctx, repository.ImageFilter{ProjectID: &projectID})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list images: %w", err)
+	}
+
+	drafted, skipped := 0, 0
+	for _, image := range images {
+		if _, err := groundTruthElements(image); !errors.Is(err, errNoGroundTruth) {
+			continue
+		}
+		draft, err := buildDraft(image, project.PrelabelModels, project.PrelabelMinVotes)
+		if err != nil {
+			skipped++
+			continue
+		}
+		draftJSON, err := marshalDraft(draft)
+		if err != nil {
+			return drafted, skipped, err
+		}
+		if err := u.imageRepo.SetDraft(ctx, image.ID, draftJSON); err != nil {
+			return drafted, skipped, fmt.Errorf("failed to save draft of image %s: %w", image.ID, err)
+		}
+		drafted++
+	}
+	return drafted, skipped, nil
+}
+
+// DeleteProject removes a project and its prediction batches. Its images,
+// runs and tasks are kept without project.
+func (u *ProjectUseCaseImpl) DeleteProject(ctx context.Context, id uuid.UUID) error {
+	return u.projectRepo.Delete(ctx, id)
+}
+
+// GetLeaderboard ranks every model and prompt version evaluated on the
+// project's images by micro-averaged F1
+func (u *ProjectUseCaseImpl) GetLeaderboard(ctx context.Context, projectID uuid.UUID, filter repository.ImageFilter) ([]*entity.LeaderboardEntry, error) {
+	if _, err := u.projectRepo.GetByID(ctx, projectID); err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	filter.ProjectID = &projectID
+
+	aggregates, err := u.imageRepo.AggregateScores(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate scores: %w", err)
+	}
+
+	entries := make([]*entity.LeaderboardEntry, 0, len(aggregates))
+	for _, agg := range aggregates {
+		entry := &entity.LeaderboardEntry{
+			Model:         agg.Model,
+			PromptVersion: agg.PromptVersion,
+			Images:        agg.Images,
+			TP:            agg.TP,
+			FP:            agg.FP,
+			FN:            agg.FN,
+		}
+		entry.Micro, entry.Macro = aggregateMetrics(agg)
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Micro.F1 > entries[j].Micro.F1
+	})
+	return entries, nil
+}
+
+// GetConfusion sums the confusion matrices of the project's images by model
+// and prompt version and breaks the errors down per class
+func (u *ProjectUseCaseImpl) GetConfusion(ctx context.Context, projectID uuid.UUID, model string, filter repository.ImageFilter) ([]*entity.ConfusionReport, error) {
+	if _, err := u.projectRepo.GetByID(ctx, projectID); err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	filter.ProjectID = &projectID
+
+	cells, err := u.imageRepo.AggregateConfusion(ctx, filter, model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate confusion matrices: %w", err)
+	}
+
+	// Cells are ordered by model and prompt version
+	var reports []*entity.ConfusionReport
+	var report *entity.ConfusionReport
+	for _, cell := range cells {
+		if report == nil || report.Model != cell.Model || report.PromptVersion != cell.PromptVersion {
+			report = &entity.ConfusionReport{
+				Model:         cell.Model,
+				PromptVersion: cell.PromptVersion,
+				Matrix:        entity.ConfusionMatrix{},
+			}
+			reports = append(reports, report)
+		}
+		report.Matrix.Add(cell.GroundTruth, cell.Predicted, cell.Count)
+	}
+	for _, report := range reports {
+		report.Classes = report.Matrix.Classes()
+		report.PerClass = evaluator.ClassBreakdown(report.Matrix)
+	}
+	return reports, nil
+}
+
+// GetPrecisionRecall sweeps the confidence threshold over the project's
+// detections and computes PR curves and COCO average precisions by model and
+// prompt version. Ground truth counts come from the confusion matrices.
+func (u *ProjectUseCaseImpl) GetPrecisionRecall(ctx context.Context, projectID uuid.UUID, model string, filter repository.ImageFilter) ([]*entity.PrecisionRecallReport, error) {
+	confusion, err := u.GetConfusion(ctx, projectID, model, filter)
+	if err != nil {
+		return nil, err
+	}
+	filter.ProjectID = &projectID
+
+	records, err := u.imageRepo.ListDetections(ctx, filter, model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list detections: %w", err)
+	}
+	detections := map[modelVersion][]entity.Detection{}
+	for _, g := range groupDetections(records) {
+		detections[g.modelVersion] = g.detections
+	}
+
+	reports := make([]*entity.PrecisionRecallReport, 0, len(confusion))
+	for _, c := range confusion {
+		groundTruth := map[string]int{}
+		for _, m := range c.PerClass {
+			groundTruth[m.Class] = m.Support
+		}
+		dets := detections[modelVersion{c.Model, c.PromptVersion}]
+
+		report := &entity.PrecisionRecallReport{Model: c.Model, PromptVersion: c.PromptVersion}
+		report.MAP, report.MAP50, report.MAP75, report.Classes = evaluator.PrecisionRecall(dets, groundTruth)
+		report.Curve = evaluator.PooledCurve(dets, groundTruth)
+		report.BestThreshold = evaluator.BestF1(report.Curve)
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// GetCalibration computes the reliability diagram, ECE and Brier score of
+// the confidences of each model and prompt version over the project's images.
+// Models whose ECE exceeds maxECE are flagged as miscalibrated.
+func (u *ProjectUseCaseImpl) GetCalibration(ctx context.Context, projectID uuid.UUID, model string, filter repository.ImageFilter, bins int, maxECE float64) ([]*entity.CalibrationReport, error) {
+	if _, err := u.projectRepo.GetByID(ctx, projectID); err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if bins < 1 || bins > 100 {
+		return nil, fmt.Errorf("%w: bins must be between 1 and 100", domainusecase.ErrInvalidInput)
+	}
+	filter.ProjectID = &projectID
+
+	records, err := u.imageRepo.ListDetections(ctx, filter, model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list detections: %w", err)
+	}
+
+	groups := groupDetections(records)
+	reports := make([]*entity.CalibrationReport, 0, len(groups))
+	for _, g := range groups {
+		report := evaluator.Calibrate(g.detections, bins)
+		report.Model, report.PromptVersion = g.model, g.promptVersion
+		report.Miscalibrated = report.Samples > 0 && report.ECE > maxECE
+		reports = append(reports, &report)
+	}
+	return reports, nil
+}
+
+// CompareModels tests whether the difference between two models on the
+// project's images is significant. Images are paired when both have been
+// scored. An image keeps one score per model, so prompt versions of the same
+// model are compared through their runs.
+func (u *ProjectUseCaseImpl) CompareModels(ctx context.Context, projectID uuid.UUID, filter repository.ImageFilter, opts domainusecase.CompareOptions) (*entity.ModelComparison, error) {
+	if opts.A.Model == "" || opts.B.Model == "" {
+		return nil, fmt.Errorf("%w: models a and b are required", domainusecase.ErrInvalidInput)
+	}
+	if opts.A.Model == opts.B.Model {
+		return nil, fmt.Errorf("%w: a and b must be different models, compare prompt versions with runs", domainusecase.ErrInvalidInput)
+	}
+	if err := validateCompareOptions(opts); err != nil {
+		return nil, err
+	}
+	if _, err := u.projectRepo.GetByID(ctx, projectID); err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	filter.ProjectID = &projectID
+
+	scores, err := u.imageRepo.ListScores(ctx, filter, []string{opts.A.Model, opts.B.Model})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scores: %w", err)
+	}
+
+	// Scores are ordered by image
+	var pairs []evaluator.PairedScore
+	for k := 0; k < len(scores); {
+		var a, b *entity.EvaluationScore
+		image := scores[k].ImageID
+		for ; k < len(scores) && scores[k].ImageID == image; k++ {
+			var score entity.EvaluationScore
+			if err := json.Unmarshal(scores[k].Score, &score); err != nil {
+				continue
+			}
+			if selects(opts.A, scores[k].Model, &score) {
+				a = &score
+			} else if selects(opts.B, scores[k].Model, &score) {
+				b = &score
+			}
+		}
+		if a != nil && b != nil {
+			pairs = append(pairs, evaluator.PairedScore{A: *a, B: *b})
+		}
+	}
+
+	return &entity.ModelComparison{
+		A:          opts.A.String(),
+		B:          opts.B.String(),
+		Images:     len(pairs),
+		Samples:    opts.Samples,
+		Confidence: opts.Confidence,
+		Seed:       opts.Seed,
+		Metrics: evaluator.Compare(pairs, evaluator.CompareOptions{
+			Samples:    opts.Samples,
+			Confidence: opts.Confidence,
+			Seed:       opts.Seed,
+		}),
+	}, nil
+}
+
+// aggregateMetrics computes the micro-averaged metrics of summed scores and
+// reads back the macro averages
+func aggregateMetrics(agg *entity.ScoreAggregate) (micro, macro entity.Metrics) {
+	macro = entity.Metrics{
+		Precision: agg.MacroPrecision,
+		Recall:    agg.MacroRecall,
+		F1:        agg.MacroF1,
+		MeanIoU:   agg.MacroMeanIoU,
+	}
+	micro.Precision, micro.Recall, micro.F1 = evaluator.PRF(agg.TP, agg.FP, agg.FN)
+	if agg.Matched > 0 {
+		miou := agg.IoUSum / float64(agg.Matched)
+		micro.MeanIoU = &miou
+	}
+	if agg.TextPairs > 0 {
+		text := evaluator.TextMetrics(agg.TextPairs, agg.TextExactMatches, agg.TextEditDistance, agg.TextGTChars, agg.TextNEDSum)
+		micro.Text = &text
+		macro.Text = &entity.TextMetrics{Pairs: agg.TextPairs, CER: agg.MacroTextCER}
+		if agg.MacroTextExactMatch != nil {
+			macro.Text.ExactMatch = *agg.MacroTextExactMatch
+		}
+		if agg.MacroTextNED != nil {
+			macro.Text.NED = *agg.MacroTextNED
+		}
+	}
+	return micro, macro
+}
+
+func selects(s domainusecase.ScoreSelector, model string, score *entity.EvaluationScore) bool {
+	return s.Model == model && (s.PromptVersion == "" || s.PromptVersion == score.PromptVersion)
+}
+
+// validateCompareOptions checks the bootstrap settings of a comparison
+func validateCompareOptions(opts domainusecase.CompareOptions) error {
+	if opts.Samples < 1 || opts.Samples > 10000 {
+		return fmt.Errorf("%w: samples must be between 1 and 10000", domainusecase.ErrInvalidInput)
+	}
+	if opts.Confidence <= 0 || opts.Confidence >= 1 {
+		return fmt.Errorf("%w: confidence must be between 0 and 1", domainusecase.ErrInvalidInput)
+	}
+	return nil
+}
+
+// modelVersion identifies a model and prompt version
+type modelVersion struct {
+	model         string
+	promptVersion string
+}
+
+type detectionGroup struct {
+	modelVersion
+	detections []entity.Detection
+}
+
+// groupDetections groups detection records ordered by model and prompt
+// version
+func groupDetections(records []*entity.DetectionRecord) []*detectionGroup {
+	var groups []*detectionGroup
+	var g *detectionGroup
+	for _, r := range records {
+		if g == nil || g.model != r.Model || g.promptVersion != r.PromptVersion {
+			g = &detectionGroup{modelVersion: modelVersion{r.Model, r.PromptVersion}}
+			groups = append(groups, g)
+		}
+		g.detections = append(g.detections, entity.Detection{Class: r.Class, Confidence: r.Confidence, TPMask: r.TPMask})
+	}
+	return groups
+}
+
+func validateProject(project *entity.Project) error {
+	project.Name = strings.TrimSpace(project.Name)
+	if project.Name == "" {
+		return fmt.Errorf("%w: name is required", domainusecase.ErrInvalidInput)
+	}
+	models, minVotes, err := prelabelSetting(project.PrelabelModels, project.PrelabelMinVotes)
+	if err != nil {
+		return err
+	}
+	project.PrelabelModels, project.PrelabelMinVotes = models, minVotes
+	return nil
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
+	"gorm.io/gorm"
+)
+
+// PromptUseCaseImpl implements the PromptUseCase interface
+type PromptUseCaseImpl struct {
+	promptRepo repository.PromptRepository
+}
+
+// NewPromptUseCase creates a new prompt use case
+func NewPromptUseCase(promptRepo repository.PromptRepository) *PromptUseCaseImpl {
+	return &PromptUseCaseImpl{
+		promptRepo: promptRepo,
+	}
+}
+
+// CreatePrompt stores a new prompt version. The first version of a model
+// becomes its active prompt.
+func (u *PromptUseCaseImpl) CreatePrompt(ctx context.Context, prompt *entity.PromptTemplate) error {
+	if prompt.Model == "" || prompt.Version == "" || prompt.Template == "" {
+		return fmt.Errorf("%w: model, version and template are required", domainusecase.ErrInvalidInput)
+	}
+
+	_, err := u.promptRepo.GetByVersion(ctx, prompt.Model, prompt.Version)
+	if err == nil {
+		return errPromptExists(prompt)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to get prompt: %w", err)
+	}
+
+	prompt.ID = uuid.New()
+	prompt.CreatedAt = time.Now()
+
+	// A concurrent creation of the same version fails on the unique index
+	err = u.promptRepo.Create(ctx, prompt)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return errPromptExists(prompt)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save prompt: %w", err)
+	}
+	return nil
+}
+
+func errPromptExists(prompt *entity.PromptTemplate) error {
+	return fmt.Errorf("%w: prompt version %s already exists for model %s", domainusecase.ErrInvalidInput, prompt.Version, prompt.Model)
+}
+
+// GetPromptByID retrieves a prompt template by its ID
+func (u *PromptUseCaseImpl) GetPromptByID(ctx context.Context, id uuid.UUID) (*entity.PromptTemplate, error) {
+	return u.promptRepo.GetByID(ctx, id)
+}
+
+// GetAllPrompts retrieves all prompt templates, optionally for a single model
+func (u *PromptUseCaseImpl) GetAllPrompts(ctx context.Context, model string) ([]*entity.PromptTemplate, error) {
+	return u.promptRepo.GetAll(ctx, model)
+}
+
+// ActivatePrompt makes a prompt version the default of its model
+func (u *PromptUseCaseImpl) ActivatePrompt(ctx context.Context, id uuid.UUID) (*entity.PromptTemplate, error) {
+	if err := u.promptRepo.Activate(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to activate prompt: %w", err)
+	}
+	return u.promptRepo.GetByID(ctx, id)
+}
+
+// DeletePrompt removes a prompt version
+func (u *PromptUseCaseImpl) DeletePrompt(ctx context.Context, id uuid.UUID) error {
+	return u.promptRepo.Delete(ctx, id)
+}
+
+// ResolvePrompt returns the prompt a model should use: the requested version,
+// or the active one when version is empty. It returns nil when the model has
+// no stored prompt, in which case the model's built-in prompt applies.
+func (u *PromptUseCaseImpl) ResolvePrompt(ctx context.Context, model, version string) (*entity.PromptTemplate, error) {
+	if version != "" {
+		prompt, err := u.promptRepo.GetByVersion(ctx, model, version)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: unknown prompt version %s for model %s", domainusecase.ErrInvalidInput, version, model)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get prompt: %w", err)
+		}
+		return prompt, nil
+	}
+
+	prompt, err := u.promptRepo.GetActive(ctx, model)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active prompt: %w", err)
+	}
+	return prompt, nil
+}
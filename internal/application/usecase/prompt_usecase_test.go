@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakePromptRepo holds prompts by model and version. getErr and createErr
+// make the lookup and the creation fail.
+type fakePromptRepo struct {
+	repository.PromptRepository
+	prompts   map[string]*entity.PromptTemplate
+	getErr    error
+	createErr error
+}
+
+func (r *fakePromptRepo) GetByVersion(ctx context.Context, model, version string) (*entity.PromptTemplate, error) {
+	if r.getErr != nil {
+		return nil, r.getErr
+	}
+	prompt, ok := r.prompts[model+"/"+version]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return prompt, nil
+}
+
+func (r *fakePromptRepo) Create(ctx context.Context, prompt *entity.PromptTemplate) error {
+	if r.createErr != nil {
+		return r.createErr
+	}
+	if r.prompts == nil {
+		r.prompts = map[string]*entity.PromptTemplate{}
+	}
+	r.prompts[prompt.Model+"/"+prompt.Version] = prompt
+	return nil
+}
+
+func TestCreatePrompt(t *testing.T) {
+	existing := &entity.PromptTemplate{Model: "gpt", Version: "v1", Template: "old"}
+	tests := []struct {
+		name      string
+		prompt    entity.PromptTemplate
+		getErr    error
+		createErr error
+		invalid   bool
+		wantErr   bool
+	}{
+		{name: "created", prompt: entity.PromptTemplate{Model: "gpt", Version: "v2", Template: "new"}},
+		{name: "missing template", prompt: entity.PromptTemplate{Model: "gpt", Version: "v2"}, invalid: true},
+		{name: "existing version", prompt: entity.PromptTemplate{Model: "gpt", Version: "v1", Template: "new"}, invalid: true},
+		{name: "concurrent creation", prompt: entity.PromptTemplate{Model: "gpt", Version: "v2", Template: "new"}, createErr: gorm.ErrDuplicatedKey, invalid: true},
+		{name: "failed lookup", prompt: entity.PromptTemplate{Model: "gpt", Version: "v2", Template: "new"}, getErr: errors.New("db down"), wantErr: true},
+		{name: "failed creation", prompt: entity.PromptTemplate{Model: "gpt", Version: "v2", Template: "new"}, createErr: errors.New("db down"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakePromptRepo{
+				prompts:   map[string]*entity.PromptTemplate{"gpt/v1": existing},
+				getErr:    tt.getErr,
+				createErr: tt.createErr,
+			}
+			u := NewPromptUseCase(repo)
+			prompt := tt.prompt
+
+			err := u.CreatePrompt(context.Background(), &prompt)
+			switch {
+			case tt.invalid:
+				assert.ErrorIs(t, err, domainusecase.ErrInvalidInput)
+			case tt.wantErr:
+				require.Error(t, err)
+				assert.NotErrorIs(t, err, domainusecase.ErrInvalidInput)
+			default:
+				require.NoError(t, err)
+				assert.Same(t, &prompt, repo.prompts["gpt/v2"])
+				assert.NotZero(t, prompt.ID)
+			}
+			assert.Equal(t, "old", repo.prompts["gpt/v1"].Template)
+		})
+	}
+}
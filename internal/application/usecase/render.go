@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/application/render"
+	"github.com/label-platform-backend/internal/domain/entity"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
+	"github.com/minio/minio-go/v7"
+)
+
+// Layers that are not model predictions
+const (
+	layerGroundTruth = "gt"
+	layerDraft       = "draft"
+)
+
+// RenderImage draws the ground truth, draft or model predictions of an image
+// over the screenshot, predictions in dashed lines. With the render cache on,
+// renders are kept in MinIO under a key derived from the drawn elements, so
+// they are reused until the elements change.
+func (u *ImageUseCaseImpl) RenderImage(ctx context.Context, id uuid.UUID, names []string) ([]byte, error) {
+	trimmed := make([]string, len(names))
+	for i, name := range names {
+		trimmed[i] = strings.TrimSpace(name)
+	}
+	names = uniqueStrings(trimmed)
+	if len(names) == 0 {
+		names = []string{layerGroundTruth}
+	}
+
+	image, err := u.imageRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+	layers, err := renderLayers(image, names)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := renderKey(image.ID, layers)
+	if err != nil {
+		return nil, err
+	}
+	if u.renderCache {
+		if cached, err := u.getObject(ctx, key); err == nil {
+			return cached, nil
+		}
+	}
+
+	screenshot, err := u.getObject(ctx, image.MinioPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image from MinIO: %w", err)
+	}
+	src, err := decodeImage(screenshot)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := png.Encode(&out, render.Overlay(src, layers)); err != nil {
+		return nil, fmt.Errorf("failed to encode render: %w", err)
+	}
+
+	if u.renderCache {
+		_, err := u.minioClient.GetClient().PutObject(ctx, u.minioClient.GetBucket(), key, bytes.NewReader(out.Bytes()), int64(out.Len()), minio.PutObjectOptions{ContentType: "image/png"})
+		if err != nil {
+			log.Printf("[render] failed to cache render of image %s: %v", image.ID, err)
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// renderLayers resolves the elements of the named layers of an image
+func renderLayers(image *entity.Image, names []string) ([]render.Layer, error) {
+	predictions := imagePredictions(image)
+	layers := make([]render.Layer, 0, len(names))
+	for _, name := range names {
+		layer := render.Layer{Name: name}
+		switch name {
+		case layerGroundTruth:
+			gt, err := visibleGroundTruth(image)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", domainusecase.ErrInvalidInput, err)
+			}
+			layer.Elements = gt
+		case layerDraft:
+			var draft entity.Draft
+			if len(image.Draft) == 0 || json.Unmarshal(image.Draft, &draft) != nil {
+				return nil, fmt.Errorf("%w: image has no draft", domainusecase.ErrInvalidInput)
+			}
+			layer.Elements = draft.Elements
+		default:
+			prediction, ok := predictions[name]
+			if !ok {
+				return nil, fmt.Errorf("%w: image has no prediction of model %s", domainusecase.ErrInvalidInput, name)
+			}
+			layer.Elements, layer.Dashed = prediction.Elements, true
+		}
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
+
+// renderKey is the MinIO key of the render of layers over an image
+func renderKey(id uuid.UUID, layers []render.Layer) (string, error) {
+	content, err := json.Marshal(layers)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal render layers: %w", err)
+	}
+	sum := sha256.Sum256(content)
+	return renderPrefix(id) + hex.EncodeToString(sum[:]) + ".png", nil
+}
+
+// renderPrefix is the MinIO prefix of the cached renders of an image
+func renderPrefix(id uuid.UUID) string {
+	return "renders/" + id.String() + "/"
+}
+
+// removeRenders deletes the cached renders of an image. Failures are
+// logged, the image being deleted anyway.
+func (u *ImageUseCaseImpl) removeRenders(ctx context.Context, id uuid.UUID) {
+	client, bucket := u.minioClient.GetClient(), u.minioClient.GetBucket()
+	for obj := range client.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: renderPrefix(id), Recursive: true}) {
+		if obj.Err != nil {
+			log.Printf("[render] failed to list renders of image %s: %v", id, obj.Err)
+			return
+		}
+		if err := client.RemoveObject(ctx, bucket, obj.Key, minio.RemoveObjectOptions{}); err != nil {
+			log.Printf("[render] failed to delete render %s: %v", obj.Key, err)
+		}
+	}
+}
+
+// getObject reads an object from MinIO
+func (u *ImageUseCaseImpl) getObject(ctx context.Context, key string) ([]byte, error) {
+	obj, err := u.minioClient.GetClient().GetObject(ctx, u.minioClient.GetBucket(), key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	return io.ReadAll(obj)
+}
+
+func decodeImage(content []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
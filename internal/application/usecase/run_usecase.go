@@ -0,0 +1,278 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/application/evaluator"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
+	"github.com/label-platform-backend/internal/infrastructure/redis"
+	"gorm.io/datatypes"
+)
+
+// RunUseCaseImpl implements the RunUseCase interface
+type RunUseCaseImpl struct {
+	runRepo           repository.EvaluationRunRepository
+	imageRepo         repository.ImageRepository
+	projectRepo       repository.ProjectRepository
+	modelRepo         repository.ModelRepository
+	promptUseCase     domainusecase.PromptUseCase
+	predictionUseCase domainusecase.PredictionUseCase
+}
+
+// NewRunUseCase creates a new evaluation run use case
+func NewRunUseCase(runRepo repository.EvaluationRunRepository, imageRepo repository.ImageRepository, projectRepo repository.ProjectRepository, modelRepo repository.ModelRepository, promptUseCase domainusecase.PromptUseCase, predictionUseCase domainusecase.PredictionUseCase) *RunUseCaseImpl {
+	return &RunUseCaseImpl{
+		runRepo:           runRepo,
+		imageRepo:         imageRepo,
+		projectRepo:       projectRepo,
+		modelRepo:         modelRepo,
+		promptUseCase:     promptUseCase,
+		predictionUseCase: predictionUseCase,
+	}
+}
+
+// CreateRun pins the prompt version of the run and snapshots its images.
+// Without a prompt version the model's active prompt is pinned; a model
+// without stored prompts keeps an empty version and uses its default prompt.
+func (u *RunUseCaseImpl) CreateRun(ctx context.Context, req domainusecase.RunRequest) (*entity.EvaluationRun, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Model == "" {
+		return nil, fmt.Errorf("%w: model is required", domainusecase.ErrInvalidInput)
+	}
+	if err := u.checkModel(ctx, req.Model); err != nil {
+		return nil, err
+	}
+	if req.ProjectID != nil {
+		if _, err := u.projectRepo.GetByID(ctx, *req.ProjectID); err != nil {
+			return nil, fmt.Errorf("failed to get project: %w", err)
+		}
+		req.Filter.ProjectID = req.ProjectID
+	}
+
+	prompt, err := u.promptUseCase.ResolvePrompt(ctx, req.Model, req.PromptVersion)
+	if err != nil {
+		return nil, err
+	}
+	promptVersion := ""
+	if prompt != nil {
+		promptVersion = prompt.Version
+	}
+
+	if len(req.ImageIDs) > 0 {
+		req.Filter = repository.ImageFilter{IDs: uniqueIDs(req.ImageIDs), ProjectID: req.ProjectID}
+	}
+	imageIDs, err := u.imageRepo.ListIDs(ctx, req.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+	if len(req.Filter.IDs) > 0 && len(imageIDs) != len(req.Filter.IDs) {
+		return nil, fmt.Errorf("%w: %d of the images do not exist or are outside the project", domainusecase.ErrInvalidInput, len(req.Filter.IDs)-len(imageIDs))
+	}
+	if len(imageIDs) == 0 {
+		return nil, fmt.Errorf("%w: no images match the run", domainusecase.ErrInvalidInput)
+	}
+
+	parameters, err := json.Marshal(req.Parameters)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid parameters: %v", domainusecase.ErrInvalidInput, err)
+	}
+	filter, err := json.Marshal(req.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal filter: %w", err)
+	}
+
+	run := &entity.EvaluationRun{
+		ProjectID:     req.ProjectID,
+		BatchID:       req.BatchID,
+		Name:          req.Name,
+		Model:         req.Model,
+		PromptVersion: promptVersion,
+		Parameters:    datatypes.JSON(parameters),
+		Filter:        datatypes.JSON(filter),
+		Status:        entity.RunStatusCreated,
+		ImageCount:    len(imageIDs),
+	}
+	if err := u.runRepo.Create(ctx, run, imageIDs); err != nil {
+		return nil, fmt.Errorf("failed to create run: %w", err)
+	}
+	return run, nil
+}
+
+// GetRun returns a run with its progress and aggregated metrics
+func (u *RunUseCaseImpl) GetRun(ctx context.Context, id uuid.UUID) (*entity.EvaluationRun, error) {
+	run, err := u.runRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get run: %w", err)
+	}
+
+	progress, err := u.runRepo.GetProgress(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get run progress: %w", err)
+	}
+	agg, err := u.runRepo.AggregateScores(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate run scores: %w", err)
+	}
+
+	run.Summary = &entity.RunSummary{RunProgress: *progress, TP: agg.TP, FP: agg.FP, FN: agg.FN}
+	run.Summary.Micro, run.Summary.Macro = aggregateMetrics(agg)
+	return run, nil
+}
+
+// GetAllRuns lists the runs, optionally of a single project
+func (u *RunUseCaseImpl) GetAllRuns(ctx context.Context, projectID *uuid.UUID) ([]*entity.EvaluationRun, error) {
+	return u.runRepo.GetAll(ctx, projectID)
+}
+
+// GetRunResults lists the results of a run, optionally with a given status
+func (u *RunUseCaseImpl) GetRunResults(ctx context.Context, id uuid.UUID, status string) ([]*entity.RunResult, error) {
+	switch status {
+	case "", entity.RunResultPending, entity.RunResultQueued, entity.RunResultPredicted, entity.RunResultFailed:
+	default:
+		return nil, fmt.Errorf("%w: unknown result status %q", domainusecase.ErrInvalidInput, status)
+	}
+	if _, err := u.runRepo.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to get run: %w", err)
+	}
+	return u.runRepo.GetResults(ctx, id, status)
+}
+
+// DeleteRun removes a run and its results
+func (u *RunUseCaseImpl) DeleteRun(ctx context.Context, id uuid.UUID) error {
+	return u.runRepo.Delete(ctx, id)
+}
+
+// CancelRun marks a run cancelled and cancels its active jobs. Completed
+// runs cannot be cancelled.
+func (u *RunUseCaseImpl) CancelRun(ctx context.Context, id uuid.UUID) (*entity.EvaluationRun, error) {
+	run, err := u.runRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get run: %w", err)
+	}
+	if run.Status == entity.RunStatusCompleted {
+		return nil, fmt.Errorf("%w: run is completed", domainusecase.ErrInvalidInput)
+	}
+
+	if err := u.runRepo.UpdateStatus(ctx, id, entity.RunStatusCancelled); err != nil {
+		return nil, fmt.Errorf("failed to cancel run: %w", err)
+	}
+	if _, err := u.predictionUseCase.CancelJobs(ctx, domainusecase.JobFilter{RunIDs: []uuid.UUID{id}}); err != nil {
+		return nil, err
+	}
+	return u.GetRun(ctx, id)
+}
+
+// CompareRuns pairs the scores of two runs by image and tests the
+// significance of their differences
+func (u *RunUseCaseImpl) CompareRuns(ctx context.Context, a, b uuid.UUID, samples int, confidence float64, seed int64) (*entity.RunComparison, error) {
+	if a == b {
+		return nil, fmt.Errorf("%w: a and b must differ", domainusecase.ErrInvalidInput)
+	}
+	if err := validateCompareOptions(domainusecase.CompareOptions{Samples: samples, Confidence: confidence}); err != nil {
+		return nil, err
+	}
+
+	runA, err := u.GetRun(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	runB, err := u.GetRun(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+
+	scoresA, err := u.runScores(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	scoresB, err := u.runScores(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+
+	byImage := make(map[uuid.UUID]*entity.EvaluationScore, len(scoresB))
+	for _, s := range scoresB {
+		byImage[s.ImageID] = s.Score
+	}
+
+	comparison := &entity.RunComparison{A: runA, B: runB, Images: []entity.RunImageScores{}}
+	var pairs []evaluator.PairedScore
+	for _, s := range scoresA {
+		other, ok := byImage[s.ImageID]
+		if !ok {
+			continue
+		}
+		comparison.Images = append(comparison.Images, entity.RunImageScores{ImageID: s.ImageID, A: s.Score, B: other})
+		pairs = append(pairs, evaluator.PairedScore{A: *s.Score, B: *other})
+	}
+	comparison.Metrics = evaluator.Compare(pairs, evaluator.CompareOptions{
+		Samples:    samples,
+		Confidence: confidence,
+		Seed:       seed,
+	})
+	return comparison, nil
+}
+
+// runScore is the decoded score of one image of a run
+type runScore struct {
+	ImageID uuid.UUID
+	Score   *entity.EvaluationScore
+}
+
+// runScores decodes the scores of a run in image order, without their
+// confusion matrices and detections
+func (u *RunUseCaseImpl) runScores(ctx context.Context, runID uuid.UUID) ([]runScore, error) {
+	results, err := u.runRepo.GetResults(ctx, runID, entity.RunResultPredicted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get run results: %w", err)
+	}
+
+	scores := make([]runScore, 0, len(results))
+	for _, result := range results {
+		if len(result.Score) == 0 || string(result.Score) == "null" {
+			continue
+		}
+		var score entity.EvaluationScore
+		if err := json.Unmarshal(result.Score, &score); err != nil {
+			continue
+		}
+		score.Confusion, score.Detections = nil, nil
+		scores = append(scores, runScore{ImageID: result.ImageID, Score: &score})
+	}
+	return scores, nil
+}
+
+// checkModel accepts queue models and enabled in-process models
+func (u *RunUseCaseImpl) checkModel(ctx context.Context, name string) error {
+	if _, ok := redis.ModelQueues[name]; ok {
+		return nil
+	}
+	models, err := u.modelRepo.GetEnabled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get models: %w", err)
+	}
+	for _, model := range models {
+		if model.Name == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown or disabled model %q", domainusecase.ErrInvalidInput, name)
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,252 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// memRunRepo keeps runs and their results in memory, completing runs like
+// the Postgres repository does
+type memRunRepo struct {
+	repository.EvaluationRunRepository
+	runs    map[uuid.UUID]*entity.EvaluationRun
+	results map[uuid.UUID]map[uuid.UUID]*entity.RunResult
+}
+
+func newMemRunRepo() *memRunRepo {
+	return &memRunRepo{runs: map[uuid.UUID]*entity.EvaluationRun{}, results: map[uuid.UUID]map[uuid.UUID]*entity.RunResult{}}
+}
+
+func (r *memRunRepo) Create(ctx context.Context, run *entity.EvaluationRun, imageIDs []uuid.UUID) error {
+	run.ID = uuid.New()
+	r.runs[run.ID] = run
+	r.results[run.ID] = map[uuid.UUID]*entity.RunResult{}
+	for _, id := range imageIDs {
+		r.results[run.ID][id] = &entity.RunResult{RunID: run.ID, ImageID: id, Status: entity.RunResultPending}
+	}
+	return nil
+}
+
+func (r *memRunRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.EvaluationRun, error) {
+	run, ok := r.runs[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return run, nil
+}
+
+func (r *memRunRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
+	r.runs[id].Status = status
+	return nil
+}
+
+func (r *memRunRepo) SetPrediction(ctx context.Context, runID, imageID uuid.UUID, status string, prediction, score datatypes.JSON) error {
+	result, ok := r.results[runID][imageID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	result.Status, result.Prediction, result.Score = status, prediction, score
+	return nil
+}
+
+func (r *memRunRepo) CompleteIfDone(ctx context.Context, id uuid.UUID) error {
+	run := r.runs[id]
+	if run.Status != entity.RunStatusRunning {
+		return nil
+	}
+	for _, result := range r.results[id] {
+		if result.Status == entity.RunResultPending || result.Status == entity.RunResultQueued {
+			return nil
+		}
+	}
+	run.Status = entity.RunStatusCompleted
+	return nil
+}
+
+func (r *memRunRepo) GetResultsByImage(ctx context.Context, imageID uuid.UUID) ([]*entity.RunResult, error) {
+	var results []*entity.RunResult
+	for _, byImage := range r.results {
+		if result, ok := byImage[imageID]; ok {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// snapshotImages returns images of a project, one of them outside it
+func snapshotImages(projectID uuid.UUID) []*entity.Image {
+	other := uuid.New()
+	return []*entity.Image{
+		{ID: uuid.New(), ProjectID: &projectID, Split: "test", Tags: []string{"mobile"}},
+		{ID: uuid.New(), ProjectID: &projectID, Split: "train", Tags: []string{"mobile"}},
+		{ID: uuid.New(), ProjectID: &projectID, Split: "test"},
+		{ID: uuid.New(), ProjectID: &other, Split: "test", Tags: []string{"mobile"}},
+	}
+}
+
+func newRunFixture() (*RunUseCaseImpl, *memRunRepo, *entity.Project, []*entity.Image) {
+	project := &entity.Project{ID: uuid.New()}
+	images := snapshotImages(project.ID)
+	runs := newMemRunRepo()
+	return &RunUseCaseImpl{
+		runRepo:       runs,
+		imageRepo:     newFakeImageRepo(images...),
+		projectRepo:   newFakeProjectRepo(project),
+		modelRepo:     &fakeModelRepo{models: []*entity.Model{{Name: "local", Provider: entity.ProviderOpenAI}}},
+		promptUseCase: &fakePromptUseCase{versions: []string{"v1", "v2"}},
+	}, runs, project, images
+}
+
+func TestCreateRun_SnapshotsFilteredImages(t *testing.T) {
+	u, runs, project, images := newRunFixture()
+
+	run, err := u.CreateRun(context.Background(), domainusecase.RunRequest{
+		ProjectID:  &project.ID,
+		Name:       " mobile test ",
+		Model:      "local",
+		Parameters: map[string]any{"temperature": 0.5},
+		Filter:     repository.ImageFilter{Tags: []string{"mobile"}, Split: "test"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "mobile test", run.Name)
+	assert.Equal(t, entity.RunStatusCreated, run.Status)
+	assert.Equal(t, "v1", run.PromptVersion, "the active prompt is pinned")
+	assert.Equal(t, 1, run.ImageCount)
+	assert.JSONEq(t, `{"temperature":0.5}`, string(run.Parameters))
+
+	var filter repository.ImageFilter
+	require.NoError(t, json.Unmarshal(run.Filter, &filter))
+	assert.Equal(t, &project.ID, filter.ProjectID, "the filter is recorded with the project")
+
+	require.Len(t, runs.results[run.ID], 1)
+	assert.Equal(t, entity.RunResultPending, runs.results[run.ID][images[0].ID].Status)
+}
+
+func TestCreateRun_ImageIDs(t *testing.T) {
+	u, runs, project, images := newRunFixture()
+
+	run, err := u.CreateRun(context.Background(), domainusecase.RunRequest{
+		ProjectID:     &project.ID,
+		Model:         "local",
+		PromptVersion: "v2",
+		ImageIDs:      []uuid.UUID{images[1].ID, images[2].ID, images[1].ID},
+		// Ignored in favor of the listed images
+		Filter: repository.ImageFilter{Split: "test"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "v2", run.PromptVersion)
+	assert.Equal(t, 2, run.ImageCount, "duplicates are dropped")
+	assert.Contains(t, runs.results[run.ID], images[1].ID)
+	assert.Contains(t, runs.results[run.ID], images[2].ID)
+}
+
+func TestCreateRun_Invalid(t *testing.T) {
+	u, runs, project, images := newRunFixture()
+	unknownProject := uuid.New()
+
+	tests := []struct {
+		name string
+		req  domainusecase.RunRequest
+		err  error
+	}{
+		{"no model", domainusecase.RunRequest{}, domainusecase.ErrInvalidInput},
+		{"unknown model", domainusecase.RunRequest{Model: "nope"}, domainusecase.ErrInvalidInput},
+		{"unknown project", domainusecase.RunRequest{Model: "local", ProjectID: &unknownProject}, gorm.ErrRecordNotFound},
+		{"unknown prompt version", domainusecase.RunRequest{Model: "local", PromptVersion: "v9"}, domainusecase.ErrInvalidInput},
+		{"image outside the project", domainusecase.RunRequest{Model: "local", ProjectID: &project.ID, ImageIDs: []uuid.UUID{images[0].ID, images[3].ID}}, domainusecase.ErrInvalidInput},
+		{"no matching image", domainusecase.RunRequest{Model: "local", ProjectID: &project.ID, Filter: repository.ImageFilter{Split: "validation"}}, domainusecase.ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := u.CreateRun(context.Background(), tt.req)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+	assert.Empty(t, runs.runs)
+}
+
+func TestRunOptions(t *testing.T) {
+	runs := newMemRunRepo()
+	image, outside := uuid.New(), uuid.New()
+	parameters := datatypes.JSON(`{"temperature":0.5}`)
+	run := &entity.EvaluationRun{Model: "local", PromptVersion: "v2", Parameters: parameters, Status: entity.RunStatusCreated}
+	require.NoError(t, runs.Create(context.Background(), run, []uuid.UUID{image}))
+	u := &PredictionUseCaseImpl{runRepo: runs}
+	ctx := context.Background()
+
+	opts, err := u.runOptions(ctx, image, domainusecase.PredictOptions{RunID: &run.ID, Models: []string{"other"}, PromptVersion: "v1", Priority: "high", Attempt: 2})
+	require.NoError(t, err)
+	assert.Equal(t, domainusecase.PredictOptions{
+		PromptVersion: "v2",
+		Force:         true,
+		Models:        []string{"local"},
+		RunID:         &run.ID,
+		Parameters:    map[string]any{"temperature": 0.5},
+		Priority:      "high",
+		Attempt:       2,
+	}, opts, "the run's settings replace the requested ones")
+	assert.Equal(t, entity.RunStatusRunning, run.Status)
+
+	// Runs with default parameters use the cache
+	run.Parameters = nil
+	opts, err = u.runOptions(ctx, image, domainusecase.PredictOptions{RunID: &run.ID})
+	require.NoError(t, err)
+	assert.False(t, opts.Force)
+
+	unknown := uuid.New()
+	_, err = u.runOptions(ctx, image, domainusecase.PredictOptions{RunID: &unknown})
+	assert.ErrorIs(t, err, domainusecase.ErrInvalidInput)
+	_, err = u.runOptions(ctx, outside, domainusecase.PredictOptions{RunID: &run.ID})
+	assert.ErrorIs(t, err, domainusecase.ErrInvalidInput, "image outside the run")
+
+	run.Status = entity.RunStatusCancelled
+	_, err = u.runOptions(ctx, image, domainusecase.PredictOptions{RunID: &run.ID})
+	assert.ErrorIs(t, err, domainusecase.ErrInvalidInput)
+}
+
+func TestSavePrediction_StoresRunResults(t *testing.T) {
+	const raw = `{"elements":[{"type":"button","text":"Submit","bbox":{"x":100,"y":200,"width":80,"height":40}}]}`
+	labeled := &entity.Image{ID: uuid.New(), ContentHash: "abc", GroundTruth: datatypes.JSON(goldGroundTruth), Width: 1000, Height: 800}
+	unlabeled := &entity.Image{ID: uuid.New(), ContentHash: "def", Width: 1000, Height: 800}
+	outside := &entity.Image{ID: uuid.New(), Width: 1000, Height: 800}
+	images := newFakeImageRepo(labeled, unlabeled, outside)
+	runs := newMemRunRepo()
+	run := &entity.EvaluationRun{Model: "local", Status: entity.RunStatusRunning}
+	require.NoError(t, runs.Create(context.Background(), run, []uuid.UUID{labeled.ID, unlabeled.ID}))
+	cache := &fakeCacheRepo{}
+	u := &ImageUseCaseImpl{imageRepo: images, runRepo: runs, cacheRepo: cache}
+	ctx := context.Background()
+	version := "v1"
+
+	require.NoError(t, u.SavePrediction(ctx, domainusecase.PredictionReport{ImageID: labeled.ID, Model: "local", RunID: &run.ID, CacheVersion: &version, Result: raw}))
+	result := runs.results[run.ID][labeled.ID]
+	assert.Equal(t, entity.RunResultPredicted, result.Status)
+	var score entity.EvaluationScore
+	require.NoError(t, json.Unmarshal(result.Score, &score), "scored against the ground truth")
+	assert.Equal(t, []int{1, 0, 1}, []int{score.TP, score.FP, score.FN})
+	assert.NotNil(t, images.prediction(labeled.ID, "local"), "also stored on the image")
+	assert.Empty(t, cache.entries, "run predictions are not cached")
+	assert.Equal(t, entity.RunStatusRunning, run.Status, "an image is still pending")
+
+	require.NoError(t, u.SavePrediction(ctx, domainusecase.PredictionReport{ImageID: unlabeled.ID, Model: "local", RunID: &run.ID, Error: "timeout"}))
+	result = runs.results[run.ID][unlabeled.ID]
+	assert.Equal(t, entity.RunResultFailed, result.Status, "failures are recorded")
+	assert.Nil(t, result.Score)
+	var failed entity.Prediction
+	require.NoError(t, json.Unmarshal(result.Prediction, &failed))
+	assert.Equal(t, "timeout", failed.Error)
+	assert.Equal(t, entity.RunStatusCompleted, run.Status, "every image has a prediction")
+
+	err := u.SavePrediction(ctx, domainusecase.PredictionReport{ImageID: outside.ID, Model: "local", RunID: &run.ID, Result: raw})
+	assert.ErrorIs(t, err, domainusecase.ErrInvalidInput, "image outside the run")
+}
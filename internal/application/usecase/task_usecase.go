@@ -0,0 +1,853 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/application/evaluator"
+	"github.com/label-platform-backend/internal/application/parser"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
+	"gorm.io/datatypes"
+)
+
+// TaskLease is how long a claimed task is reserved for its annotator
+const TaskLease = 30 * time.Minute
+
+// taskTransitions is the task state machine: the statuses a task may move
+// to from each status. Moving to the same status renews a claim or replaces
+// a submission.
+var taskTransitions = map[string][]string{
+	entity.TaskStatusTodo:       {entity.TaskStatusTodo, entity.TaskStatusInProgress, entity.TaskStatusSubmitted},
+	entity.TaskStatusInProgress: {entity.TaskStatusInProgress, entity.TaskStatusTodo, entity.TaskStatusSubmitted},
+	entity.TaskStatusSubmitted:  {entity.TaskStatusSubmitted, entity.TaskStatusApproved, entity.TaskStatusRejected},
+	entity.TaskStatusRejected:   {entity.TaskStatusInProgress, entity.TaskStatusSubmitted},
+	entity.TaskStatusApproved:   {entity.TaskStatusSubmitted},
+}
+
+// TaskUseCaseImpl implements the TaskUseCase interface
+type TaskUseCaseImpl struct {
+	taskRepo     repository.AnnotationTaskRepository
+	imageRepo    repository.ImageRepository
+	projectRepo  repository.ProjectRepository
+	imageUseCase domainusecase.ImageUseCase
+}
+
+// NewTaskUseCase creates a new annotation task use case
+func NewTaskUseCase(taskRepo repository.AnnotationTaskRepository, imageRepo repository.ImageRepository, projectRepo repository.ProjectRepository, imageUseCase domainusecase.ImageUseCase) *TaskUseCaseImpl {
+	return &TaskUseCaseImpl{
+		taskRepo:     taskRepo,
+		imageRepo:    imageRepo,
+		projectRepo:  projectRepo,
+		imageUseCase: imageUseCase,
+	}
+}
+
+// AssignTasks deals the selected images round-robin to the annotators
+func (u *TaskUseCaseImpl) AssignTasks(ctx context.Context, projectID uuid.UUID, req domainusecase.AssignRequest) (int64, error) {
+	annotators := make([]string, len(req.Annotators))
+	for i, annotator := range req.Annotators {
+		annotators[i] = strings.TrimSpace(annotator)
+	}
+	annotators = uniqueStrings(annotators)
+	if req.PerImage == 0 {
+		req.PerImage = 1
+	}
+	if req.PerImage < 1 {
+		return 0, fmt.Errorf("%w: per_image must be positive", domainusecase.ErrInvalidInput)
+	}
+	if req.Overlap < 0 || req.Overlap > 1 {
+		return 0, fmt.Errorf("%w: overlap must be between 0 and 1", domainusecase.ErrInvalidInput)
+	}
+	if req.Gold < 0 {
+		return 0, fmt.Errorf("%w: gold must not be negative", domainusecase.ErrInvalidInput)
+	}
+	if req.Gold > 0 && len(annotators) == 0 {
+		return 0, fmt.Errorf("%w: gold tasks need annotators", domainusecase.ErrInvalidInput)
+	}
+	if len(annotators) == 0 && req.PerImage > 1 {
+		return 0, fmt.Errorf("%w: per_image above 1 needs annotators", domainusecase.ErrInvalidInput)
+	}
+	if len(annotators) > 0 && req.PerImage > len(annotators) {
+		return 0, fmt.Errorf("%w: per_image exceeds the %d annotators", domainusecase.ErrInvalidInput, len(annotators))
+	}
+	if _, err := u.projectRepo.GetByID(ctx, projectID); err != nil {
+		return 0, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	filter := req.Filter
+	if len(req.ImageIDs) > 0 {
+		filter = repository.ImageFilter{IDs: uniqueIDs(req.ImageIDs)}
+	}
+	filter.ProjectID = &projectID
+	imageIDs, priorities, err := u.assignOrder(ctx, filter, req.Order)
+	if err != nil {
+		return 0, err
+	}
+	if len(filter.IDs) > 0 && len(imageIDs) != len(filter.IDs) {
+		return 0, fmt.Errorf("%w: %d of the images do not exist or are outside the project", domainusecase.ErrInvalidInput, len(filter.IDs)-len(imageIDs))
+	}
+
+	gold := true
+	goldIDs, err := u.imageRepo.ListIDs(ctx, repository.ImageFilter{ProjectID: &projectID, Gold: &gold})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list gold images: %w", err)
+	}
+	if req.Gold > 0 && len(goldIDs) == 0 {
+		return 0, fmt.Errorf("%w: the project has no gold images", domainusecase.ErrInvalidInput)
+	}
+
+	tasks := dealTasks(projectID, imageIDs, annotators, req.PerImage, req.Overlap)
+	dealt := len(tasks)
+	tasks = append(tasks, goldTasks(projectID, goldIDs, annotators, req.Gold)...)
+	isGold := map[uuid.UUID]bool{}
+	for _, id := range goldIDs {
+		isGold[id] = true
+	}
+	// Tasks created together are served in random ID order, which mixes
+	// the gold tasks in. Prioritized gold tasks take the priority of a
+	// random task for the same purpose.
+	now := time.Now()
+	for i, task := range tasks {
+		task.Gold = isGold[task.ImageID]
+		task.CreatedAt, task.UpdatedAt = now, now
+		switch {
+		case i < dealt:
+			task.Priority = priorities[task.ImageID]
+		case dealt > 0:
+			task.Priority = tasks[rand.Intn(dealt)].Priority
+		}
+	}
+	created, err := u.taskRepo.Create(ctx, tasks)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create tasks: %w", err)
+	}
+	return created, nil
+}
+
+// assignOrder lists the IDs of the images to assign in the order they are
+// dealt, and the priority of their tasks. By uncertainty, the images are
+// dealt most uncertain first and their tasks prioritized by their score.
+func (u *TaskUseCaseImpl) assignOrder(ctx context.Context, filter repository.ImageFilter, order string) ([]uuid.UUID, map[uuid.UUID]float64, error) {
+	switch order {
+	case "", domainusecase.AssignOrderCreated:
+		imageIDs, err := u.imageRepo.ListIDs(ctx, filter)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list images: %w", err)
+		}
+		return imageIDs, nil, nil
+	case domainusecase.AssignOrderUncertainty:
+		images, err := u.imageRepo.List(ctx, filter)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list images: %w", err)
+		}
+		ranked := rankImages(images)
+		imageIDs := make([]uuid.UUID, len(ranked))
+		priorities := make(map[uuid.UUID]float64, len(ranked))
+		for i, r := range ranked {
+			imageIDs[i] = r.ImageID
+			priorities[r.ImageID] = r.Score
+		}
+		return imageIDs, priorities, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: unknown order %q", domainusecase.ErrInvalidInput, order)
+	}
+}
+
+// GetTask returns a task by its ID
+func (u *TaskUseCaseImpl) GetTask(ctx context.Context, id uuid.UUID) (*entity.AnnotationTask, error) {
+	task, err := u.taskRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	return task, nil
+}
+
+// ListTasks lists the tasks matching the filter
+func (u *TaskUseCaseImpl) ListTasks(ctx context.Context, filter repository.TaskFilter) ([]*entity.AnnotationTask, error) {
+	switch filter.Status {
+	case "", entity.TaskStatusTodo, entity.TaskStatusInProgress, entity.TaskStatusSubmitted, entity.TaskStatusApproved, entity.TaskStatusRejected:
+	default:
+		return nil, fmt.Errorf("%w: unknown task status %q", domainusecase.ErrInvalidInput, filter.Status)
+	}
+	return u.taskRepo.List(ctx, filter)
+}
+
+// AssignTask reassigns an open task. The current claim is dropped.
+func (u *TaskUseCaseImpl) AssignTask(ctx context.Context, id uuid.UUID, assignee string) (*entity.AnnotationTask, error) {
+	assignee = strings.TrimSpace(assignee)
+	task, err := u.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Assignee == assignee {
+		return task, nil
+	}
+
+	existing, err := u.taskRepo.List(ctx, repository.TaskFilter{ImageID: &task.ImageID, Assignee: &assignee})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks: %w", err)
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("%w: the image already has a task for %q", domainusecase.ErrConflict, assignee)
+	}
+	if err := checkTransition(task, entity.TaskStatusTodo); err != nil {
+		return nil, err
+	}
+
+	ok, err := u.taskRepo.Assign(ctx, id, assignee)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign task: %w", err)
+	}
+	if !ok {
+		return nil, u.taskConflict(ctx, id, "assigned")
+	}
+	comment := "returned to the pool"
+	if assignee != "" {
+		comment = fmt.Sprintf("assigned to %s", assignee)
+	}
+	u.recordEvent(ctx, task, entity.TaskActionAssigned, entity.TaskStatusTodo, "", comment)
+	return u.GetTask(ctx, id)
+}
+
+// DeleteTask removes a task
+func (u *TaskUseCaseImpl) DeleteTask(ctx context.Context, id uuid.UUID) error {
+	return u.taskRepo.Delete(ctx, id)
+}
+
+// NextTask claims the next task of an annotator: the task it is working on,
+// then its assigned tasks, then pool tasks
+func (u *TaskUseCaseImpl) NextTask(ctx context.Context, annotator string, projectID *uuid.UUID) (*entity.AnnotationTask, error) {
+	annotator, err := checkAnnotator(annotator)
+	if err != nil {
+		return nil, err
+	}
+	claimed, err := u.taskRepo.ClaimNext(ctx, annotator, projectID, time.Now().Add(TaskLease))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim task: %w", err)
+	}
+	if claimed == nil {
+		return nil, nil
+	}
+	if claimed.PreviousStatus != entity.TaskStatusInProgress || claimed.PreviousClaimant != annotator {
+		previous := *claimed.Task
+		previous.Status = claimed.PreviousStatus
+		u.recordEvent(ctx, &previous, entity.TaskActionClaimed, entity.TaskStatusInProgress, annotator, "")
+	}
+	return claimed.Task, nil
+}
+
+// ClaimTask leases a task to an annotator for TaskLease
+func (u *TaskUseCaseImpl) ClaimTask(ctx context.Context, id uuid.UUID, annotator string) (*entity.AnnotationTask, error) {
+	annotator, err := checkAnnotator(annotator)
+	if err != nil {
+		return nil, err
+	}
+	task, err := u.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTransition(task, entity.TaskStatusInProgress); err != nil {
+		return nil, err
+	}
+
+	ok, err := u.taskRepo.Claim(ctx, id, annotator, time.Now().Add(TaskLease))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim task: %w", err)
+	}
+	if !ok {
+		return nil, u.taskConflict(ctx, id, "claimed")
+	}
+	// Renewing a lease is not recorded
+	if task.Status != entity.TaskStatusInProgress || task.ClaimedBy != annotator {
+		u.recordEvent(ctx, task, entity.TaskActionClaimed, entity.TaskStatusInProgress, annotator, "")
+	}
+	return u.GetTask(ctx, id)
+}
+
+// ReleaseTask puts a task claimed by the annotator back to todo
+func (u *TaskUseCaseImpl) ReleaseTask(ctx context.Context, id uuid.UUID, annotator string) (*entity.AnnotationTask, error) {
+	annotator, err := checkAnnotator(annotator)
+	if err != nil {
+		return nil, err
+	}
+	task, err := u.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTransition(task, entity.TaskStatusTodo); err != nil {
+		return nil, err
+	}
+
+	ok, err := u.taskRepo.Release(ctx, id, annotator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release task: %w", err)
+	}
+	if !ok {
+		return nil, u.taskConflict(ctx, id, "released")
+	}
+	u.recordEvent(ctx, task, entity.TaskActionReleased, entity.TaskStatusTodo, annotator, "")
+	return u.GetTask(ctx, id)
+}
+
+// SubmitTask stores the annotation of a task claimed by the annotator
+func (u *TaskUseCaseImpl) SubmitTask(ctx context.Context, id uuid.UUID, annotator string, annotation map[string]any) (*entity.AnnotationTask, error) {
+	annotator, err := checkAnnotator(annotator)
+	if err != nil {
+		return nil, err
+	}
+	data, err := marshalAnnotation(annotation)
+	if err != nil {
+		return nil, err
+	}
+	task, err := u.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTransition(task, entity.TaskStatusSubmitted); err != nil {
+		return nil, err
+	}
+
+	ok, err := u.taskRepo.Submit(ctx, id, annotator, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit task: %w", err)
+	}
+	if !ok {
+		return nil, u.taskConflict(ctx, id, "submitted")
+	}
+	u.recordEvent(ctx, task, entity.TaskActionSubmitted, entity.TaskStatusSubmitted, annotator, "")
+	if task.Gold {
+		u.scoreGold(ctx, task, data)
+	}
+	return u.GetTask(ctx, id)
+}
+
+// scoreGold scores the annotation submitted on a gold task against the
+// ground truth of its image with the evaluator used for models. The
+// submission is already stored, so failures are only logged.
+func (u *TaskUseCaseImpl) scoreGold(ctx context.Context, task *entity.AnnotationTask, annotation datatypes.JSON) {
+	image, err := u.imageRepo.GetByID(ctx, task.ImageID)
+	if err != nil {
+		log.Printf("[gold] failed to get image of task %s: %v", task.ID, err)
+		return
+	}
+	gt, err := groundTruthElements(image)
+	if err != nil {
+		log.Printf("[gold] cannot score task %s: %v", task.ID, err)
+		return
+	}
+
+	// Unparsable annotations score as empty, like model outputs
+	var elements []entity.Element
+	if result, err := parser.ForModel("").Parse(string(annotation), image.Width, image.Height); err == nil {
+		elements = result.Elements
+	}
+	score, err := json.Marshal(evaluator.Evaluate(gt, elements, evaluator.DefaultIoUThreshold))
+	if err != nil {
+		log.Printf("[gold] failed to marshal score of task %s: %v", task.ID, err)
+		return
+	}
+	if err := u.taskRepo.SetGoldScore(ctx, task.ID, score); err != nil {
+		log.Printf("[gold] failed to store score of task %s: %v", task.ID, err)
+	}
+}
+
+// ProposeGroundTruth submits an annotation of an image for review on the
+// task of the annotator, creating the task when there is none
+func (u *TaskUseCaseImpl) ProposeGroundTruth(ctx context.Context, imageID uuid.UUID, annotator string, groundTruth map[string]any) (*entity.AnnotationTask, error) {
+	annotator, err := checkAnnotator(annotator)
+	if err != nil {
+		return nil, err
+	}
+	data, err := marshalAnnotation(groundTruth)
+	if err != nil {
+		return nil, err
+	}
+	image, err := u.imageRepo.GetByID(ctx, imageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+
+	existing, err := u.taskRepo.List(ctx, repository.TaskFilter{ImageID: &imageID, Assignee: &annotator})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks: %w", err)
+	}
+	previous := &entity.AnnotationTask{}
+	if len(existing) > 0 {
+		previous = existing[0]
+		if err := checkTransition(previous, entity.TaskStatusSubmitted); err != nil {
+			return nil, err
+		}
+		if previous.Status == entity.TaskStatusInProgress && previous.ClaimedBy != annotator {
+			return nil, fmt.Errorf("%w: task is claimed by %q", domainusecase.ErrConflict, previous.ClaimedBy)
+		}
+	}
+
+	task := &entity.AnnotationTask{
+		ProjectID:  image.ProjectID,
+		ImageID:    imageID,
+		Assignee:   annotator,
+		ClaimedBy:  annotator,
+		Annotation: data,
+	}
+	if err := u.taskRepo.Propose(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to propose ground truth: %w", err)
+	}
+	previous.ID = task.ID
+	u.recordEvent(ctx, previous, entity.TaskActionSubmitted, entity.TaskStatusSubmitted, annotator, "")
+	return u.GetTask(ctx, task.ID)
+}
+
+// ApproveTask makes the submitted annotation of a task, or the reviewer's
+// edit of it, the ground truth of the image
+func (u *TaskUseCaseImpl) ApproveTask(ctx context.Context, id uuid.UUID, reviewer, comment string, annotation map[string]any) (*entity.AnnotationTask, error) {
+	task, err := u.reviewableTask(ctx, id, reviewer, entity.TaskStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+	reviewer = strings.TrimSpace(reviewer)
+
+	action := entity.TaskActionApproved
+	approved := task.Annotation
+	var edited datatypes.JSON
+	if annotation != nil {
+		if edited, err = marshalAnnotation(annotation); err != nil {
+			return nil, err
+		}
+		action = entity.TaskActionEdited
+		approved = edited
+	}
+	var groundTruth map[string]any
+	if err := json.Unmarshal(approved, &groundTruth); err != nil {
+		return nil, fmt.Errorf("failed to decode annotation: %w", err)
+	}
+
+	// The ground truth is written first: a failure leaves the task submitted
+	// so the review can be retried. The trusted ground truth of gold images
+	// is kept.
+	if !task.Gold {
+		if _, err := u.imageUseCase.UpdateGroundTruth(ctx, task.ImageID, groundTruth); err != nil {
+			return nil, err
+		}
+	}
+	ok, err := u.taskRepo.Review(ctx, id, entity.TaskStatusApproved, reviewer, strings.TrimSpace(comment), edited)
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve task: %w", err)
+	}
+	if !ok {
+		return nil, u.taskConflict(ctx, id, "approved")
+	}
+	u.recordEvent(ctx, task, action, entity.TaskStatusApproved, reviewer, strings.TrimSpace(comment))
+	return u.GetTask(ctx, id)
+}
+
+// RejectTask sends a submitted task back to its annotator with a comment
+func (u *TaskUseCaseImpl) RejectTask(ctx context.Context, id uuid.UUID, reviewer, comment string) (*entity.AnnotationTask, error) {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return nil, fmt.Errorf("%w: comment is required", domainusecase.ErrInvalidInput)
+	}
+	task, err := u.reviewableTask(ctx, id, reviewer, entity.TaskStatusRejected)
+	if err != nil {
+		return nil, err
+	}
+	reviewer = strings.TrimSpace(reviewer)
+
+	ok, err := u.taskRepo.Review(ctx, id, entity.TaskStatusRejected, reviewer, comment, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reject task: %w", err)
+	}
+	if !ok {
+		return nil, u.taskConflict(ctx, id, "rejected")
+	}
+	u.recordEvent(ctx, task, entity.TaskActionRejected, entity.TaskStatusRejected, reviewer, comment)
+	return u.GetTask(ctx, id)
+}
+
+// GetTaskHistory returns the actions taken on a task, oldest first
+func (u *TaskUseCaseImpl) GetTaskHistory(ctx context.Context, id uuid.UUID) ([]*entity.TaskEvent, error) {
+	if _, err := u.GetTask(ctx, id); err != nil {
+		return nil, err
+	}
+	events, err := u.taskRepo.GetEvents(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task history: %w", err)
+	}
+	return events, nil
+}
+
+// GetAgreement compares the submitted and approved annotations of the
+// project's images annotated by two or more annotators. Annotations that
+// cannot be parsed are left out.
+func (u *TaskUseCaseImpl) GetAgreement(ctx context.Context, projectID uuid.UUID, minAgreement float64) (*entity.AgreementReport, error) {
+	if minAgreement < 0 || minAgreement > 1 {
+		return nil, fmt.Errorf("%w: min_agreement must be between 0 and 1", domainusecase.ErrInvalidInput)
+	}
+	if _, err := u.projectRepo.GetByID(ctx, projectID); err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	tasks, err := u.taskRepo.List(ctx, repository.TaskFilter{ProjectID: &projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks: %w", err)
+	}
+
+	// Annotations by image and annotator
+	annotations := map[uuid.UUID]map[string]*entity.AnnotationTask{}
+	for _, task := range tasks {
+		if task.Status != entity.TaskStatusSubmitted && task.Status != entity.TaskStatusApproved || len(task.Annotation) == 0 {
+			continue
+		}
+		if annotations[task.ImageID] == nil {
+			annotations[task.ImageID] = map[string]*entity.AnnotationTask{}
+		}
+		annotations[task.ImageID][task.ClaimedBy] = task
+	}
+	report := &entity.AgreementReport{ProjectID: projectID, MinAgreement: minAgreement, Annotated: len(annotations)}
+	var overlapping []uuid.UUID
+	for imageID, byAnnotator := range annotations {
+		if len(byAnnotator) > 1 {
+			overlapping = append(overlapping, imageID)
+		}
+	}
+	if len(overlapping) == 0 {
+		return report, nil
+	}
+	images, err := u.imageRepo.List(ctx, repository.ImageFilter{IDs: overlapping})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+
+	pairs := map[[2]string]*entity.PairAgreement{}
+	var units [][]string
+	matched, iouSum, agreementSum := 0, 0.0, 0.0
+	for _, image := range images {
+		elements := map[string][]entity.Element{}
+		for annotator, task := range annotations[image.ID] {
+			result, err := parser.ForModel("").Parse(string(task.Annotation), image.Width, image.Height)
+			if err != nil {
+				log.Printf("[agreement] skipping annotation of task %s: %v", task.ID, err)
+				continue
+			}
+			elements[annotator] = result.Elements
+		}
+		if len(elements) < 2 {
+			continue
+		}
+
+		agreement, imageUnits := evaluator.ImageAgreement(elements, evaluator.DefaultIoUThreshold)
+		agreement.ImageID = image.ID
+		agreement.NeedsAdjudication = agreement.Agreement < minAgreement
+		report.Images = append(report.Images, agreement)
+		units = append(units, imageUnits...)
+		agreementSum += agreement.Agreement
+		for _, pair := range agreement.Pairs {
+			matched += pair.Matched
+			iouSum += pair.IoUSum
+			pooled, ok := pairs[[2]string{pair.A, pair.B}]
+			if !ok {
+				pooled = &entity.PairAgreement{A: pair.A, B: pair.B}
+				pairs[[2]string{pair.A, pair.B}] = pooled
+			}
+			evaluator.MergePair(pooled, pair)
+		}
+		if agreement.NeedsAdjudication {
+			report.Adjudication++
+		}
+	}
+
+	report.Overlapping = len(report.Images)
+	if report.Overlapping > 0 {
+		report.Agreement = agreementSum / float64(report.Overlapping)
+	}
+	if matched > 0 {
+		miou := iouSum / float64(matched)
+		report.MeanIoU = &miou
+	}
+	report.Alpha = evaluator.KrippendorffAlpha(units)
+	for _, pair := range pairs {
+		report.Pairs = append(report.Pairs, *pair)
+	}
+	sort.Slice(report.Pairs, func(i, j int) bool {
+		if report.Pairs[i].A != report.Pairs[j].A {
+			return report.Pairs[i].A < report.Pairs[j].A
+		}
+		return report.Pairs[i].B < report.Pairs[j].B
+	})
+	sort.SliceStable(report.Images, func(i, j int) bool {
+		return report.Images[i].Agreement < report.Images[j].Agreement
+	})
+	return report, nil
+}
+
+// RecordSession stores a work session on a task. Overlapping intervals are
+// only counted once.
+func (u *TaskUseCaseImpl) RecordSession(ctx context.Context, id uuid.UUID, report domainusecase.SessionReport) (*entity.AnnotationSession, error) {
+	annotator, err := checkAnnotator(report.Annotator)
+	if err != nil {
+		return nil, err
+	}
+	if len(report.Intervals) == 0 {
+		return nil, fmt.Errorf("%w: intervals are required", domainusecase.ErrInvalidInput)
+	}
+	for _, interval := range report.Intervals {
+		if !interval.End.After(interval.Start) {
+			return nil, fmt.Errorf("%w: interval ends before it starts", domainusecase.ErrInvalidInput)
+		}
+	}
+	if report.Created < 0 || report.Edited < 0 || report.Deleted < 0 {
+		return nil, fmt.Errorf("%w: element counts must not be negative", domainusecase.ErrInvalidInput)
+	}
+
+	task, err := u.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// Sessions may arrive after the submit, so only the claimant is checked
+	if task.ClaimedBy != annotator {
+		return nil, fmt.Errorf("%w: task is not claimed by %q", domainusecase.ErrConflict, annotator)
+	}
+
+	merged := entity.MergeIntervals(report.Intervals)
+	intervals, err := json.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal intervals: %w", err)
+	}
+	session := &entity.AnnotationSession{
+		TaskID:        task.ID,
+		ProjectID:     task.ProjectID,
+		Annotator:     annotator,
+		StartedAt:     merged[0].Start,
+		EndedAt:       merged[len(merged)-1].End,
+		ActiveSeconds: entity.ActiveDuration(merged).Seconds(),
+		Intervals:     datatypes.JSON(intervals),
+		Created:       report.Created,
+		Edited:        report.Edited,
+		Deleted:       report.Deleted,
+	}
+	if err := u.taskRepo.AddSession(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to save session: %w", err)
+	}
+	return session, nil
+}
+
+// GetProductivity reports the work of the annotators by annotator, project
+// and day. The time per task is the active time when sessions were
+// reported, else the handle time.
+func (u *TaskUseCaseImpl) GetProductivity(ctx context.Context, filter repository.ProductivityFilter) ([]*entity.ProductivityRow, error) {
+	rows, err := u.taskRepo.ProductivityReport(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get productivity: %w", err)
+	}
+	var perTask []float64
+	for _, row := range rows {
+		if row.Submitted == 0 {
+			continue
+		}
+		seconds := row.HandleSeconds
+		if row.ActiveSeconds > 0 {
+			seconds = row.ActiveSeconds
+		}
+		seconds /= float64(row.Submitted)
+		row.SecondsPerTask = &seconds
+		perTask = append(perTask, seconds)
+	}
+	flagOutliers(rows, perTask)
+	return rows, nil
+}
+
+// flagOutliers flags the rows whose time per task has a modified z-score,
+// based on the median absolute deviation, above 3.5
+func flagOutliers(rows []*entity.ProductivityRow, perTask []float64) {
+	if len(perTask) < 3 {
+		return
+	}
+	median := medianOf(perTask)
+	deviations := make([]float64, len(perTask))
+	for i, seconds := range perTask {
+		deviations[i] = math.Abs(seconds - median)
+	}
+	mad := medianOf(deviations)
+	if mad == 0 {
+		return
+	}
+	for _, row := range rows {
+		if row.SecondsPerTask != nil && 0.6745*math.Abs(*row.SecondsPerTask-median)/mad > 3.5 {
+			row.Outlier = true
+		}
+	}
+}
+
+func medianOf(values []float64) float64 {
+	sorted := slices.Clone(values)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// GetAnnotatorAccuracy aggregates the gold scores of each annotator of a
+// project
+func (u *TaskUseCaseImpl) GetAnnotatorAccuracy(ctx context.Context, projectID uuid.UUID) ([]*entity.AnnotatorAccuracy, error) {
+	if _, err := u.projectRepo.GetByID(ctx, projectID); err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	aggregates, err := u.taskRepo.AggregateGoldScores(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate gold scores: %w", err)
+	}
+
+	accuracy := make([]*entity.AnnotatorAccuracy, 0, len(aggregates))
+	for _, agg := range aggregates {
+		entry := &entity.AnnotatorAccuracy{
+			Annotator: agg.Annotator,
+			Tasks:     agg.Images,
+			TP:        agg.TP,
+			FP:        agg.FP,
+			FN:        agg.FN,
+		}
+		entry.Micro, entry.Macro = aggregateMetrics(&agg.ScoreAggregate)
+		accuracy = append(accuracy, entry)
+	}
+	return accuracy, nil
+}
+
+// reviewableTask returns a task the reviewer may move to status. Annotators
+// cannot review their own annotations.
+func (u *TaskUseCaseImpl) reviewableTask(ctx context.Context, id uuid.UUID, reviewer, status string) (*entity.AnnotationTask, error) {
+	reviewer = strings.TrimSpace(reviewer)
+	if reviewer == "" {
+		return nil, fmt.Errorf("%w: reviewer is required", domainusecase.ErrInvalidInput)
+	}
+	task, err := u.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTransition(task, status); err != nil {
+		return nil, err
+	}
+	if reviewer == task.ClaimedBy {
+		return nil, fmt.Errorf("%w: annotators cannot review their own annotations", domainusecase.ErrInvalidInput)
+	}
+	return task, nil
+}
+
+// recordEvent appends an action moving task to status to its history. The
+// action already happened, so a failure is only logged.
+func (u *TaskUseCaseImpl) recordEvent(ctx context.Context, task *entity.AnnotationTask, action, status, actor, comment string) {
+	event := &entity.TaskEvent{
+		TaskID:     task.ID,
+		Action:     action,
+		FromStatus: task.Status,
+		ToStatus:   status,
+		Actor:      actor,
+		Comment:    comment,
+	}
+	if err := u.taskRepo.AddEvent(ctx, event); err != nil {
+		log.Printf("[tasks] failed to record %s of task %s: %v", action, task.ID, err)
+	}
+}
+
+// taskConflict explains why a task could not be claimed, released or
+// submitted, or returns the not found error of a missing task
+func (u *TaskUseCaseImpl) taskConflict(ctx context.Context, id uuid.UUID, action string) error {
+	task, err := u.GetTask(ctx, id)
+	if err != nil {
+		return err
+	}
+	if task.ClaimedBy != "" && task.Status == entity.TaskStatusInProgress {
+		return fmt.Errorf("%w: task cannot be %s, it is claimed by %q", domainusecase.ErrConflict, action, task.ClaimedBy)
+	}
+	return fmt.Errorf("%w: task cannot be %s, it is %s", domainusecase.ErrConflict, action, task.Status)
+}
+
+// checkTransition returns a conflict error when the state machine does not
+// allow task to move to status
+func checkTransition(task *entity.AnnotationTask, status string) error {
+	if !slices.Contains(taskTransitions[task.Status], status) {
+		return fmt.Errorf("%w: a %s task cannot become %s", domainusecase.ErrConflict, task.Status, status)
+	}
+	return nil
+}
+
+func checkAnnotator(annotator string) (string, error) {
+	annotator = strings.TrimSpace(annotator)
+	if annotator == "" {
+		return "", fmt.Errorf("%w: annotator is required", domainusecase.ErrInvalidInput)
+	}
+	return annotator, nil
+}
+
+func marshalAnnotation(annotation map[string]any) (datatypes.JSON, error) {
+	if annotation == nil {
+		return nil, fmt.Errorf("%w: annotation is required", domainusecase.ErrInvalidInput)
+	}
+	data, err := json.Marshal(annotation)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid annotation: %v", domainusecase.ErrInvalidInput, err)
+	}
+	return datatypes.JSON(data), nil
+}
+
+// dealTasks gives each image to perImage consecutive annotators, continuing
+// the rotation from image to image so the annotators get equal shares. With
+// an overlap below 1 only that share of the images, spread evenly, gets
+// perImage annotators and the others one. Without annotators every image
+// gets one pool task.
+func dealTasks(projectID uuid.UUID, imageIDs []uuid.UUID, annotators []string, perImage int, overlap float64) []*entity.AnnotationTask {
+	if len(annotators) == 0 {
+		annotators, perImage = []string{""}, 1
+	}
+	tasks := make([]*entity.AnnotationTask, 0, len(imageIDs)*perImage)
+	next := 0
+	for i, imageID := range imageIDs {
+		count := perImage
+		if overlap > 0 && overlap < 1 && math.Floor(float64(i+1)*overlap) == math.Floor(float64(i)*overlap) {
+			count = 1
+		}
+		for j := 0; j < count; j++ {
+			tasks = append(tasks, &entity.AnnotationTask{
+				ProjectID: &projectID,
+				ImageID:   imageID,
+				Assignee:  annotators[next],
+				Status:    entity.TaskStatusTodo,
+			})
+			next = (next + 1) % len(annotators)
+		}
+	}
+	return tasks
+}
+
+// goldTasks gives each annotator up to perAnnotator tasks on randomly picked
+// gold images
+func goldTasks(projectID uuid.UUID, goldIDs []uuid.UUID, annotators []string, perAnnotator int) []*entity.AnnotationTask {
+	var tasks []*entity.AnnotationTask
+	if perAnnotator == 0 {
+		return tasks
+	}
+	picked := slices.Clone(goldIDs)
+	for _, annotator := range annotators {
+		rand.Shuffle(len(picked), func(i, j int) { picked[i], picked[j] = picked[j], picked[i] })
+		for _, imageID := range picked[:min(perAnnotator, len(picked))] {
+			tasks = append(tasks, &entity.AnnotationTask{
+				ProjectID: &projectID,
+				ImageID:   imageID,
+				Assignee:  annotator,
+				Status:    entity.TaskStatusTodo,
+				Gold:      true,
+			})
+		}
+	}
+	return tasks
+}
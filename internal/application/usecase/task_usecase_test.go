@@ -0,0 +1,419 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func imageIDs(n int) []uuid.UUID {
+	ids := make([]uuid.UUID, n)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	return ids
+}
+
+// assignees returns the assignees of the tasks of each image, in order
+func assignees(tasks []*entity.AnnotationTask, ids []uuid.UUID) [][]string {
+	byImage := map[uuid.UUID][]string{}
+	for _, task := range tasks {
+		byImage[task.ImageID] = append(byImage[task.ImageID], task.Assignee)
+	}
+	out := make([][]string, len(ids))
+	for i, id := range ids {
+		out[i] = byImage[id]
+	}
+	return out
+}
+
+func TestCheckTransition(t *testing.T) {
+	const (
+		todo       = entity.TaskStatusTodo
+		inProgress = entity.TaskStatusInProgress
+		submitted  = entity.TaskStatusSubmitted
+		approved   = entity.TaskStatusApproved
+		rejected   = entity.TaskStatusRejected
+	)
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{todo, todo, true},
+		{todo, inProgress, true},
+		{todo, submitted, true},
+		{todo, approved, false},
+		{todo, rejected, false},
+
+		{inProgress, inProgress, true},
+		{inProgress, todo, true},
+		{inProgress, submitted, true},
+		{inProgress, approved, false},
+		{inProgress, rejected, false},
+
+		{submitted, submitted, true},
+		{submitted, approved, true},
+		{submitted, rejected, true},
+		{submitted, todo, false},
+		{submitted, inProgress, false},
+
+		{rejected, inProgress, true},
+		{rejected, submitted, true},
+		{rejected, todo, false},
+		{rejected, approved, false},
+		{rejected, rejected, false},
+
+		{approved, submitted, true},
+		{approved, todo, false},
+		{approved, inProgress, false},
+		{approved, approved, false},
+		{approved, rejected, false},
+
+		{"unknown", submitted, false},
+	}
+	for _, tt := range tests {
+		err := checkTransition(&entity.AnnotationTask{Status: tt.from}, tt.to)
+		if tt.allowed {
+			assert.NoError(t, err, "%s -> %s", tt.from, tt.to)
+		} else {
+			assert.ErrorIs(t, err, domainusecase.ErrConflict, "%s -> %s", tt.from, tt.to)
+		}
+	}
+}
+
+func TestDealTasks_Rotation(t *testing.T) {
+	projectID := uuid.New()
+	ids := imageIDs(3)
+
+	tasks := dealTasks(projectID, ids, []string{"ann", "bob", "cat"}, 2, 1)
+	require.Len(t, tasks, 6)
+	// The rotation continues from image to image
+	assert.Equal(t, [][]string{{"ann", "bob"}, {"cat", "ann"}, {"bob", "cat"}}, assignees(tasks, ids))
+	for _, task := range tasks {
+		assert.Equal(t, projectID, *task.ProjectID)
+		assert.Equal(t, entity.TaskStatusTodo, task.Status)
+		assert.False(t, task.Gold)
+	}
+}
+
+func TestDealTasks_Overlap(t *testing.T) {
+	ids := imageIDs(4)
+
+	// Half of the images, every other one, get two annotators
+	tasks := dealTasks(uuid.New(), ids, []string{"ann", "bob"}, 2, 0.5)
+	assert.Equal(t, [][]string{{"ann"}, {"bob", "ann"}, {"bob"}, {"ann", "bob"}}, assignees(tasks, ids))
+
+	counts := map[string]int{}
+	for _, task := range tasks {
+		counts[task.Assignee]++
+	}
+	assert.Equal(t, map[string]int{"ann": 3, "bob": 3}, counts)
+}
+
+func TestDealTasks_Pool(t *testing.T) {
+	ids := imageIDs(2)
+
+	tasks := dealTasks(uuid.New(), ids, nil, 3, 1)
+	assert.Equal(t, [][]string{{""}, {""}}, assignees(tasks, ids), "one pool task per image")
+}
+
+func TestGoldTasks(t *testing.T) {
+	gold := imageIDs(5)
+	isGold := map[uuid.UUID]bool{}
+	for _, id := range gold {
+		isGold[id] = true
+	}
+
+	original := slices.Clone(gold)
+	tasks := goldTasks(uuid.New(), gold, []string{"ann", "bob"}, 3)
+	require.Len(t, tasks, 6)
+	assert.Equal(t, original, gold, "the gold images are shuffled in a copy")
+	picked := map[string]map[uuid.UUID]bool{}
+	for _, task := range tasks {
+		assert.True(t, task.Gold)
+		assert.True(t, isGold[task.ImageID])
+		if picked[task.Assignee] == nil {
+			picked[task.Assignee] = map[uuid.UUID]bool{}
+		}
+		picked[task.Assignee][task.ImageID] = true
+	}
+	assert.Len(t, picked["ann"], 3, "distinct gold images per annotator")
+	assert.Len(t, picked["bob"], 3)
+
+	assert.Len(t, goldTasks(uuid.New(), gold[:2], []string{"ann"}, 3), 2, "capped by the gold images")
+	assert.Empty(t, goldTasks(uuid.New(), gold, []string{"ann"}, 0))
+}
+
+// fakeTaskRepo holds tasks, records their gold scores and sessions and
+// serves canned aggregates and productivity rows
+type fakeTaskRepo struct {
+	repository.AnnotationTaskRepository
+	tasks        map[uuid.UUID]*entity.AnnotationTask
+	goldScores   map[uuid.UUID]datatypes.JSON
+	aggregates   []*entity.AnnotatorScoreAggregate
+	sessions     []*entity.AnnotationSession
+	productivity []*entity.ProductivityRow
+}
+
+func (r *fakeTaskRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.AnnotationTask, error) {
+	task, ok := r.tasks[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return task, nil
+}
+
+func (r *fakeTaskRepo) AddSession(ctx context.Context, session *entity.AnnotationSession) error {
+	r.sessions = append(r.sessions, session)
+	return nil
+}
+
+func (r *fakeTaskRepo) ProductivityReport(ctx context.Context, filter repository.ProductivityFilter) ([]*entity.ProductivityRow, error) {
+	return r.productivity, nil
+}
+
+func (r *fakeTaskRepo) SetGoldScore(ctx context.Context, id uuid.UUID, score datatypes.JSON) error {
+	if r.goldScores == nil {
+		r.goldScores = map[uuid.UUID]datatypes.JSON{}
+	}
+	r.goldScores[id] = score
+	return nil
+}
+
+func (r *fakeTaskRepo) AggregateGoldScores(ctx context.Context, projectID uuid.UUID) ([]*entity.AnnotatorScoreAggregate, error) {
+	return r.aggregates, nil
+}
+
+const goldGroundTruth = `{"elements":[
+	{"type":"button","text":"Submit","bbox":{"x":100,"y":200,"width":80,"height":40}},
+	{"type":"input","text":"Email","bbox":{"x":100,"y":100,"width":200,"height":40}}
+]}`
+
+func TestScoreGold(t *testing.T) {
+	image := &entity.Image{ID: uuid.New(), Gold: true, GroundTruth: datatypes.JSON(goldGroundTruth)}
+	taskRepo := &fakeTaskRepo{}
+	u := &TaskUseCaseImpl{taskRepo: taskRepo, imageRepo: newFakeImageRepo(image)}
+
+	tests := []struct {
+		name       string
+		annotation string
+		tp, fp, fn int
+	}{
+		{"exact", goldGroundTruth, 2, 0, 0},
+		{"missed and spurious elements", `{"elements":[
+			{"type":"button","text":"Submit","bbox":{"x":102,"y":200,"width":80,"height":40}},
+			{"type":"link","text":"Help","bbox":{"x":500,"y":500,"width":40,"height":20}}
+		]}`, 1, 1, 1},
+		{"unparsable scores as empty", `not json`, 0, 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &entity.AnnotationTask{ID: uuid.New(), ImageID: image.ID, Gold: true}
+			u.scoreGold(context.Background(), task, datatypes.JSON(tt.annotation))
+
+			require.Contains(t, taskRepo.goldScores, task.ID)
+			var score entity.EvaluationScore
+			require.NoError(t, json.Unmarshal(taskRepo.goldScores[task.ID], &score))
+			assert.Equal(t, []int{tt.tp, tt.fp, tt.fn}, []int{score.TP, score.FP, score.FN})
+		})
+	}
+}
+
+func TestScoreGold_WithoutGroundTruth(t *testing.T) {
+	image := &entity.Image{ID: uuid.New(), Gold: true}
+	taskRepo := &fakeTaskRepo{}
+	u := &TaskUseCaseImpl{taskRepo: taskRepo, imageRepo: newFakeImageRepo(image)}
+
+	u.scoreGold(context.Background(), &entity.AnnotationTask{ID: uuid.New(), ImageID: image.ID}, datatypes.JSON(goldGroundTruth))
+	u.scoreGold(context.Background(), &entity.AnnotationTask{ID: uuid.New(), ImageID: uuid.New()}, datatypes.JSON(goldGroundTruth))
+	assert.Empty(t, taskRepo.goldScores, "nothing to score against")
+}
+
+func TestGetAnnotatorAccuracy(t *testing.T) {
+	project := &entity.Project{ID: uuid.New()}
+	miou := 0.7
+	taskRepo := &fakeTaskRepo{aggregates: []*entity.AnnotatorScoreAggregate{
+		{Annotator: "ann", ScoreAggregate: entity.ScoreAggregate{
+			Images: 2, TP: 8, FP: 2, FN: 2, Matched: 8, IoUSum: 6.4,
+			MacroPrecision: 0.75, MacroRecall: 0.85, MacroF1: 0.79, MacroMeanIoU: &miou,
+		}},
+		{Annotator: "bob", ScoreAggregate: entity.ScoreAggregate{Images: 1, FN: 3}},
+	}}
+	u := &TaskUseCaseImpl{taskRepo: taskRepo, projectRepo: newFakeProjectRepo(project)}
+
+	accuracy, err := u.GetAnnotatorAccuracy(context.Background(), project.ID)
+	require.NoError(t, err)
+	require.Len(t, accuracy, 2)
+
+	ann := accuracy[0]
+	assert.Equal(t, "ann", ann.Annotator)
+	assert.Equal(t, 2, ann.Tasks)
+	assert.Equal(t, []int{8, 2, 2}, []int{ann.TP, ann.FP, ann.FN})
+	// Micro metrics come from the summed counts, macro ones are read back
+	assert.InDelta(t, 0.8, ann.Micro.Precision, 1e-9)
+	assert.InDelta(t, 0.8, ann.Micro.Recall, 1e-9)
+	assert.InDelta(t, 0.8, ann.Micro.F1, 1e-9)
+	require.NotNil(t, ann.Micro.MeanIoU)
+	assert.InDelta(t, 0.8, *ann.Micro.MeanIoU, 1e-9)
+	assert.Equal(t, entity.Metrics{Precision: 0.75, Recall: 0.85, F1: 0.79, MeanIoU: &miou}, ann.Macro)
+
+	bob := accuracy[1]
+	assert.Zero(t, bob.Micro.Recall)
+	assert.Nil(t, bob.Micro.MeanIoU, "no matched element")
+
+	_, err = u.GetAnnotatorAccuracy(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestRecordSession(t *testing.T) {
+	projectID := uuid.New()
+	task := &entity.AnnotationTask{ID: uuid.New(), ProjectID: &projectID, ClaimedBy: "ann", Status: entity.TaskStatusInProgress}
+	at := func(minute, second int) time.Time {
+		return time.Date(2024, 1, 1, 9, minute, second, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name      string
+		report    domainusecase.SessionReport
+		intervals []entity.Interval
+		active    float64
+	}{
+		{
+			name:      "single interval",
+			report:    domainusecase.SessionReport{Annotator: " ann ", Intervals: []entity.Interval{{Start: at(0, 0), End: at(2, 0)}}, Created: 3, Edited: 1},
+			intervals: []entity.Interval{{Start: at(0, 0), End: at(2, 0)}},
+			active:    120,
+		},
+		{
+			// Heartbeats resend the running interval, extended each time
+			name: "overlapping heartbeats",
+			report: domainusecase.SessionReport{Annotator: "ann", Intervals: []entity.Interval{
+				{Start: at(0, 0), End: at(0, 30)},
+				{Start: at(0, 0), End: at(1, 0)},
+				{Start: at(0, 45), End: at(1, 30)},
+			}},
+			intervals: []entity.Interval{{Start: at(0, 0), End: at(1, 30)}},
+			active:    90,
+		},
+		{
+			// The client closes the interval after an idle timeout and opens
+			// another on the next edit
+			name: "idle time is not counted",
+			report: domainusecase.SessionReport{Annotator: "ann", Intervals: []entity.Interval{
+				{Start: at(10, 0), End: at(11, 0)},
+				{Start: at(0, 0), End: at(1, 0)},
+			}},
+			intervals: []entity.Interval{{Start: at(0, 0), End: at(1, 0)}, {Start: at(10, 0), End: at(11, 0)}},
+			active:    120,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taskRepo := &fakeTaskRepo{tasks: map[uuid.UUID]*entity.AnnotationTask{task.ID: task}}
+			u := &TaskUseCaseImpl{taskRepo: taskRepo}
+
+			session, err := u.RecordSession(context.Background(), task.ID, tt.report)
+			require.NoError(t, err)
+			require.Equal(t, []*entity.AnnotationSession{session}, taskRepo.sessions)
+			assert.Equal(t, task.ID, session.TaskID)
+			assert.Equal(t, &projectID, session.ProjectID)
+			assert.Equal(t, "ann", session.Annotator)
+			assert.Equal(t, tt.intervals[0].Start, session.StartedAt)
+			assert.Equal(t, tt.intervals[len(tt.intervals)-1].End, session.EndedAt)
+			assert.Equal(t, tt.active, session.ActiveSeconds)
+			assert.Equal(t, []int{tt.report.Created, tt.report.Edited, tt.report.Deleted}, []int{session.Created, session.Edited, session.Deleted})
+
+			var intervals []entity.Interval
+			require.NoError(t, json.Unmarshal(session.Intervals, &intervals))
+			assert.Equal(t, tt.intervals, intervals)
+		})
+	}
+}
+
+func TestRecordSession_Rejected(t *testing.T) {
+	task := &entity.AnnotationTask{ID: uuid.New(), ClaimedBy: "ann", Status: entity.TaskStatusSubmitted}
+	valid := []entity.Interval{{Start: time.Unix(0, 0), End: time.Unix(60, 0)}}
+
+	tests := []struct {
+		name   string
+		id     uuid.UUID
+		report domainusecase.SessionReport
+		err    error
+	}{
+		{name: "no annotator", id: task.ID, report: domainusecase.SessionReport{Annotator: " ", Intervals: valid}, err: domainusecase.ErrInvalidInput},
+		{name: "no intervals", id: task.ID, report: domainusecase.SessionReport{Annotator: "ann"}, err: domainusecase.ErrInvalidInput},
+		{name: "interval ends before it starts", id: task.ID, report: domainusecase.SessionReport{Annotator: "ann", Intervals: []entity.Interval{{Start: time.Unix(60, 0), End: time.Unix(60, 0)}}}, err: domainusecase.ErrInvalidInput},
+		{name: "negative count", id: task.ID, report: domainusecase.SessionReport{Annotator: "ann", Intervals: valid, Deleted: -1}, err: domainusecase.ErrInvalidInput},
+		{name: "not the claimant", id: task.ID, report: domainusecase.SessionReport{Annotator: "bob", Intervals: valid}, err: domainusecase.ErrConflict},
+		{name: "unknown task", id: uuid.New(), report: domainusecase.SessionReport{Annotator: "ann", Intervals: valid}, err: gorm.ErrRecordNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taskRepo := &fakeTaskRepo{tasks: map[uuid.UUID]*entity.AnnotationTask{task.ID: task}}
+			u := &TaskUseCaseImpl{taskRepo: taskRepo}
+
+			_, err := u.RecordSession(context.Background(), tt.id, tt.report)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Empty(t, taskRepo.sessions)
+		})
+	}
+}
+
+func TestGetProductivity(t *testing.T) {
+	row := func(annotator string, submitted int, handle, active float64) *entity.ProductivityRow {
+		return &entity.ProductivityRow{Annotator: annotator, Day: "2024-01-01", Submitted: submitted, HandleSeconds: handle, ActiveSeconds: active}
+	}
+	taskRepo := &fakeTaskRepo{productivity: []*entity.ProductivityRow{
+		row("ann", 4, 800, 400),
+		row("bob", 2, 220, 0),
+		row("cat", 5, 600, 0),
+		row("dan", 3, 330, 0),
+		row("eve", 2, 4000, 3000),
+		row("fay", 0, 0, 600),
+	}}
+	u := &TaskUseCaseImpl{taskRepo: taskRepo}
+
+	rows, err := u.GetProductivity(context.Background(), repository.ProductivityFilter{})
+	require.NoError(t, err)
+	perTask := map[string]*float64{}
+	outliers := map[string]bool{}
+	for _, r := range rows {
+		perTask[r.Annotator] = r.SecondsPerTask
+		outliers[r.Annotator] = r.Outlier
+	}
+
+	// Active time when sessions were reported, else the handle time
+	require.NotNil(t, perTask["ann"])
+	assert.Equal(t, 100.0, *perTask["ann"])
+	require.NotNil(t, perTask["bob"])
+	assert.Equal(t, 110.0, *perTask["bob"])
+	assert.Nil(t, perTask["fay"], "no submitted task")
+	assert.True(t, outliers["eve"], "15 times the median")
+	for _, annotator := range []string{"ann", "bob", "cat", "dan", "fay"} {
+		assert.False(t, outliers[annotator], annotator)
+	}
+}
+
+func TestFlagOutliers_TooFewRows(t *testing.T) {
+	fast, slow := 10.0, 1000.0
+	rows := []*entity.ProductivityRow{{SecondsPerTask: &fast}, {SecondsPerTask: &slow}}
+	flagOutliers(rows, []float64{fast, slow})
+	assert.False(t, rows[0].Outlier)
+	assert.False(t, rows[1].Outlier)
+}
+
+func TestMedianOf(t *testing.T) {
+	assert.Equal(t, 2.0, medianOf([]float64{3, 1, 2}))
+	assert.Equal(t, 2.5, medianOf([]float64{4, 1, 3, 2}))
+}
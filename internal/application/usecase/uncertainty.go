@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"github.com/label-platform-backend/internal/application/evaluator"
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// rankImages ranks images by the uncertainty of their usable predictions,
+// most uncertain first. The ensemble is left out as it hides the
+// disagreement of its members.
+func rankImages(images []*entity.Image) []*entity.ImageUncertainty {
+	ranked := make([]*entity.ImageUncertainty, 0, len(images))
+	for _, image := range images {
+		models, elements := usablePredictions(image)
+		predictions := make(map[string][]entity.Element, len(models))
+		for i, model := range models {
+			predictions[model] = elements[i]
+		}
+		u := evaluator.Uncertainty(predictions, evaluator.DefaultIoUThreshold)
+		u.ImageID = image.ID
+		ranked = append(ranked, &u)
+	}
+	evaluator.RankUncertainty(ranked)
+	return ranked
+}
@@ -0,0 +1,180 @@
+// Package watchdog gives up on prediction jobs that are stuck, e.g. because
+// the worker predicting them died, and retries or fails them.
+package watchdog
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
+	"github.com/label-platform-backend/pkg/worker"
+)
+
+// Clock tells the time and waits, so tests can control both
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Jobs is the part of the prediction use case the watchdog works on
+type Jobs interface {
+	GetJobs(ctx context.Context, filter domainusecase.JobFilter) ([]*entity.PredictionJob, error)
+	TimeOutJob(ctx context.Context, jobID string) (bool, error)
+	RetryJob(ctx context.Context, job *entity.PredictionJob) error
+	FailJob(ctx context.Context, job *entity.PredictionJob, reason string) error
+	RecordJobEvent(ctx context.Context, event *entity.JobEvent)
+}
+
+// Policy sets the deadlines of jobs and how often timed-out jobs are retried
+type Policy struct {
+	// Timeout bounds how long a job may run; ModelTimeouts overrides it per
+	// model. A timeout of 0 disables the deadline.
+	Timeout       time.Duration
+	ModelTimeouts map[string]time.Duration
+	// QueueTimeout bounds how long a job may wait in its queue, 0 disables it.
+	// It catches jobs popped by workers that never mark them running.
+	QueueTimeout time.Duration
+	// MaxRetries is how often a timed-out job is dispatched again before its
+	// prediction fails
+	MaxRetries int
+}
+
+// DefaultPolicy returns the policy used when nothing is configured
+func DefaultPolicy() Policy {
+	return Policy{Timeout: 5 * time.Minute, QueueTimeout: 30 * time.Minute, MaxRetries: 2}
+}
+
+// Overdue returns why a job missed its deadline at now, or false when it
+// did not
+func (p Policy) Overdue(job *entity.PredictionJob, now time.Time) (string, bool) {
+	switch {
+	case job.Status == worker.JobRunning && job.StartedAt != nil:
+		timeout := p.Timeout
+		if t, ok := p.ModelTimeouts[job.Model]; ok {
+			timeout = t
+		}
+		if timeout > 0 && now.Sub(*job.StartedAt) > timeout {
+			return fmt.Sprintf("running for longer than %s", timeout), true
+		}
+	case job.Status == worker.JobQueued:
+		if p.QueueTimeout > 0 && now.Sub(job.QueuedAt) > p.QueueTimeout {
+			return fmt.Sprintf("queued for longer than %s", p.QueueTimeout), true
+		}
+	}
+	return "", false
+}
+
+// Watchdog periodically scans the active jobs and times out overdue ones
+type Watchdog struct {
+	jobs     Jobs
+	policy   Policy
+	interval time.Duration
+	clock    Clock
+}
+
+// New creates a watchdog scanning every interval
+func New(jobs Jobs, policy Policy, interval time.Duration) *Watchdog {
+	return &Watchdog{jobs: jobs, policy: policy, interval: interval, clock: realClock{}}
+}
+
+// WithClock replaces the wall clock, for tests
+func (w *Watchdog) WithClock(clock Clock) *Watchdog {
+	w.clock = clock
+	return w
+}
+
+// Run scans the jobs until ctx is cancelled. A scan in progress is finished
+// first, so no job is left timed out without being retried or failed.
+func (w *Watchdog) Run(ctx context.Context) {
+	for {
+		if _, err := w.Scan(context.WithoutCancel(ctx)); err != nil {
+			log.Printf("[watchdog] scan failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.clock.After(w.interval):
+		}
+	}
+}
+
+// Scan times out the overdue jobs, then retries each one or fails its
+// prediction once it ran out of retries. It returns the number of jobs
+// timed out.
+func (w *Watchdog) Scan(ctx context.Context) (int, error) {
+	jobs, err := w.jobs.GetJobs(ctx, domainusecase.JobFilter{})
+	if err != nil {
+		return 0, err
+	}
+
+	now := w.clock.Now()
+	timedOut := 0
+	for _, job := range jobs {
+		reason, overdue := w.policy.Overdue(job, now)
+		if !overdue {
+			continue
+		}
+		// The job may have finished since it was listed
+		ok, err := w.jobs.TimeOutJob(ctx, job.ID)
+		if err != nil {
+			log.Printf("[watchdog] failed to time out job %s: %v", job.ID, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		timedOut++
+		job.Status = worker.JobTimedOut
+		w.record(ctx, entity.JobEventTimedOut, job, reason)
+
+		if job.Attempt < w.policy.MaxRetries {
+			err := w.jobs.RetryJob(ctx, job)
+			if err == nil {
+				w.record(ctx, entity.JobEventRetried, job, reason)
+				continue
+			}
+			reason = fmt.Sprintf("%s, retry failed: %v", reason, err)
+		}
+
+		reason = fmt.Sprintf("timed out after %d attempts: %s", job.Attempt+1, reason)
+		if err := w.jobs.FailJob(ctx, job, reason); err != nil {
+			log.Printf("[watchdog] failed to fail job %s: %v", job.ID, err)
+		}
+		w.record(ctx, entity.JobEventFailed, job, reason)
+	}
+	return timedOut, nil
+}
+
+func (w *Watchdog) record(ctx context.Context, event string, job *entity.PredictionJob, reason string) {
+	w.jobs.RecordJobEvent(ctx, &entity.JobEvent{Type: event, Job: job, Reason: reason, At: w.clock.Now()})
+}
+
+// ParseTimeouts parses per-model timeouts written as "gpt=3m,claude=90s"
+func ParseTimeouts(s string) (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		model, value, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(model) == "" {
+			return nil, fmt.Errorf("invalid timeout %q, expected model=duration", entry)
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout of %s: %w", model, err)
+		}
+		timeouts[strings.TrimSpace(model)] = timeout
+	}
+	return timeouts, nil
+}
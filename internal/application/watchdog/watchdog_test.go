@@ -0,0 +1,256 @@
+package watchdog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	domainusecase "github.com/label-platform-backend/internal/domain/usecase"
+	"github.com/label-platform-backend/pkg/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock only moves when advanced
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	waiting := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			waiting = append(waiting, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = waiting
+}
+
+// waitForWaiter blocks until the watchdog sleeps on the clock
+func (c *fakeClock) waitForWaiter(t *testing.T) {
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.waiters) > 0
+	}, time.Second, time.Millisecond)
+}
+
+// fakeJobs records what the watchdog does to the jobs
+type fakeJobs struct {
+	mu       sync.Mutex
+	jobs     []*entity.PredictionJob
+	finished map[string]bool
+	retryErr error
+	scans    int
+	retried  []string
+	failed   map[string]string
+	events   []string
+}
+
+func newFakeJobs(jobs ...*entity.PredictionJob) *fakeJobs {
+	return &fakeJobs{jobs: jobs, finished: map[string]bool{}, failed: map[string]string{}}
+}
+
+func (f *fakeJobs) GetJobs(ctx context.Context, filter domainusecase.JobFilter) ([]*entity.PredictionJob, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.scans++
+	jobs := make([]*entity.PredictionJob, len(f.jobs))
+	for i, job := range f.jobs {
+		copied := *job
+		jobs[i] = &copied
+	}
+	return jobs, nil
+}
+
+func (f *fakeJobs) TimeOutJob(ctx context.Context, jobID string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.finished[jobID] {
+		return false, nil
+	}
+	for i, job := range f.jobs {
+		if job.ID == jobID {
+			f.jobs = append(f.jobs[:i], f.jobs[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeJobs) RetryJob(ctx context.Context, job *entity.PredictionJob) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.retryErr != nil {
+		return f.retryErr
+	}
+	f.retried = append(f.retried, job.ID)
+	return nil
+}
+
+func (f *fakeJobs) FailJob(ctx context.Context, job *entity.PredictionJob, reason string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failed[job.ID] = reason
+	return nil
+}
+
+func (f *fakeJobs) RecordJobEvent(ctx context.Context, event *entity.JobEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, event.Job.ID+":"+event.Type)
+}
+
+func (f *fakeJobs) scanCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.scans
+}
+
+func runningJob(id, model string, startedAt time.Time, attempt int) *entity.PredictionJob {
+	return &entity.PredictionJob{ID: id, ImageID: "img-" + id, Model: model, Status: worker.JobRunning, Attempt: attempt, QueuedAt: startedAt, StartedAt: &startedAt}
+}
+
+func TestPolicy_Overdue(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	policy := Policy{Timeout: 5 * time.Minute, ModelTimeouts: map[string]time.Duration{"gpt": time.Minute, "local": 0}, QueueTimeout: 30 * time.Minute}
+
+	reason, overdue := policy.Overdue(runningJob("a", "claude", now.Add(-6*time.Minute), 0), now)
+	assert.True(t, overdue)
+	assert.Equal(t, "running for longer than 5m0s", reason)
+
+	_, overdue = policy.Overdue(runningJob("b", "claude", now.Add(-4*time.Minute), 0), now)
+	assert.False(t, overdue)
+
+	_, overdue = policy.Overdue(runningJob("c", "gpt", now.Add(-2*time.Minute), 0), now)
+	assert.True(t, overdue, "per-model timeout")
+
+	_, overdue = policy.Overdue(runningJob("d", "local", now.Add(-time.Hour), 0), now)
+	assert.False(t, overdue, "0 disables the deadline")
+
+	queued := &entity.PredictionJob{ID: "e", Model: "gpt", Status: worker.JobQueued, QueuedAt: now.Add(-31 * time.Minute)}
+	reason, overdue = policy.Overdue(queued, now)
+	assert.True(t, overdue)
+	assert.Equal(t, "queued for longer than 30m0s", reason)
+
+	policy.QueueTimeout = 0
+	_, overdue = policy.Overdue(queued, now)
+	assert.False(t, overdue)
+}
+
+func TestWatchdog_ScanRetriesThenFails(t *testing.T) {
+	clock := newFakeClock()
+	started := clock.Now()
+	jobs := newFakeJobs(
+		runningJob("fresh", "gpt", started, 0),
+		runningJob("stuck", "gpt", started, 0),
+		runningJob("last", "gpt", started, 2),
+		runningJob("done", "gpt", started, 0),
+	)
+	jobs.jobs[0].StartedAt = nil
+	jobs.jobs[0].Status = worker.JobQueued
+	jobs.finished["done"] = true
+	w := New(jobs, Policy{Timeout: time.Minute, MaxRetries: 2}, time.Second).WithClock(clock)
+
+	n, err := w.Scan(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n, "nothing is overdue yet")
+
+	clock.Advance(2 * time.Minute)
+	n, err = w.Scan(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n, "the finished job is not timed out")
+	assert.Equal(t, []string{"stuck"}, jobs.retried)
+	assert.Equal(t, map[string]string{"last": "timed out after 3 attempts: running for longer than 1m0s"}, jobs.failed)
+	assert.Equal(t, []string{"stuck:timed_out", "stuck:retried", "last:timed_out", "last:failed"}, jobs.events)
+}
+
+func TestWatchdog_ScanFailsWhenRetryFails(t *testing.T) {
+	clock := newFakeClock()
+	jobs := newFakeJobs(runningJob("stuck", "gpt", clock.Now(), 0))
+	jobs.retryErr = errors.New("run is cancelled")
+	w := New(jobs, Policy{Timeout: time.Minute, MaxRetries: 1}, time.Second).WithClock(clock)
+
+	clock.Advance(time.Hour)
+	_, err := w.Scan(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "timed out after 1 attempts: running for longer than 1m0s, retry failed: run is cancelled", jobs.failed["stuck"])
+	assert.Equal(t, []string{"stuck:timed_out", "stuck:failed"}, jobs.events)
+}
+
+func TestWatchdog_RunScansEveryIntervalUntilCancelled(t *testing.T) {
+	clock := newFakeClock()
+	jobs := newFakeJobs(runningJob("stuck", "gpt", clock.Now(), 0))
+	w := New(jobs, Policy{Timeout: time.Minute}, 30*time.Second).WithClock(clock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+
+	clock.waitForWaiter(t)
+	assert.Equal(t, 1, jobs.scanCount(), "scans immediately")
+
+	clock.Advance(30 * time.Second)
+	clock.waitForWaiter(t)
+	assert.Equal(t, 2, jobs.scanCount())
+	assert.Empty(t, jobs.failed)
+
+	clock.Advance(31 * time.Second)
+	clock.waitForWaiter(t)
+	assert.Equal(t, 3, jobs.scanCount())
+	assert.Contains(t, jobs.failed, "stuck", "no retries left")
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("watchdog did not stop")
+	}
+}
+
+func TestParseTimeouts(t *testing.T) {
+	timeouts, err := ParseTimeouts(" gpt=3m, claude=90s ,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{"gpt": 3 * time.Minute, "claude": 90 * time.Second}, timeouts)
+
+	_, err = ParseTimeouts("gpt")
+	assert.Error(t, err)
+	_, err = ParseTimeouts("gpt=soon")
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package entity
+
+import "github.com/google/uuid"
+
+// PairAgreement measures how much two annotators agree on the images they
+// both annotated. Elements are matched by IoU like predictions to ground
+// truth; Table counts the matched pairs by the class each annotator gave,
+// with Background for elements only one of them drew.
+type PairAgreement struct {
+	A         string `json:"a"`
+	B         string `json:"b"`
+	Images    int    `json:"images"`
+	ElementsA int    `json:"elements_a"`
+	ElementsB int    `json:"elements_b"`
+	Matched   int    `json:"matched"`
+	SameClass int    `json:"same_class"`
+	// MeanIoU is the mean IoU of the matched boxes, nil without matches
+	MeanIoU *float64 `json:"mean_iou"`
+	// ClassAgreement is the share of matched boxes given the same class
+	ClassAgreement float64 `json:"class_agreement"`
+	// F1 is the share of elements both annotators drew with the same class:
+	// 2 * SameClass / (ElementsA + ElementsB)
+	F1 float64 `json:"f1"`
+	// Kappa is Cohen's kappa on the classes of Table, nil when undefined
+	Kappa  *float64        `json:"kappa"`
+	IoUSum float64         `json:"-"`
+	Table  ConfusionMatrix `json:"-"`
+}
+
+// ImageAgreement is the agreement of the annotators of one image.
+// Agreement is the mean F1 of the annotator pairs.
+type ImageAgreement struct {
+	ImageID    uuid.UUID `json:"image_id"`
+	Annotators []string  `json:"annotators"`
+	Agreement  float64   `json:"agreement"`
+	MeanIoU    *float64  `json:"mean_iou"`
+	// Alpha is Krippendorff's alpha on the element classes, nil when
+	// undefined, e.g. when every element got the same class
+	Alpha             *float64        `json:"alpha"`
+	NeedsAdjudication bool            `json:"needs_adjudication"`
+	Pairs             []PairAgreement `json:"pairs"`
+}
+
+// AgreementReport is the inter-annotator agreement of a project over its
+// images annotated by two or more annotators. Images are sorted by
+// increasing agreement; those below MinAgreement need adjudication.
+type AgreementReport struct {
+	ProjectID    uuid.UUID        `json:"project_id"`
+	MinAgreement float64          `json:"min_agreement"`
+	Annotated    int              `json:"annotated"`
+	Overlapping  int              `json:"overlapping"`
+	Adjudication int              `json:"needs_adjudication"`
+	Agreement    float64          `json:"agreement"`
+	MeanIoU      *float64         `json:"mean_iou"`
+	Alpha        *float64         `json:"alpha"`
+	Pairs        []PairAgreement  `json:"pairs"`
+	Images       []ImageAgreement `json:"images"`
+}
@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Interval is a span of active work reported by the annotation client
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// MergeIntervals sorts intervals and merges the overlapping ones, so time
+// reported twice is only counted once
+func MergeIntervals(intervals []Interval) []Interval {
+	sorted := append([]Interval(nil), intervals...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	var merged []Interval
+	for _, interval := range sorted {
+		last := len(merged) - 1
+		if last >= 0 && !interval.Start.After(merged[last].End) {
+			if interval.End.After(merged[last].End) {
+				merged[last].End = interval.End
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
+
+// ActiveDuration returns the time covered by intervals
+func ActiveDuration(intervals []Interval) time.Duration {
+	var total time.Duration
+	for _, interval := range MergeIntervals(intervals) {
+		total += interval.End.Sub(interval.Start)
+	}
+	return total
+}
+
+// AnnotationSession is a stretch of work of an annotator on a task as
+// reported by the client: its active editing intervals and how many
+// elements were created, edited and deleted
+type AnnotationSession struct {
+	ID            uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	TaskID        uuid.UUID      `json:"task_id" gorm:"type:uuid;not null;index"`
+	ProjectID     *uuid.UUID     `json:"project_id" gorm:"type:uuid;index"`
+	Annotator     string         `json:"annotator" gorm:"type:text;not null;index"`
+	StartedAt     time.Time      `json:"started_at" gorm:"not null;index"`
+	EndedAt       time.Time      `json:"ended_at" gorm:"not null"`
+	ActiveSeconds float64        `json:"active_seconds" gorm:"not null"`
+	Intervals     datatypes.JSON `json:"intervals" gorm:"type:jsonb"`
+	Created       int            `json:"created" gorm:"not null;default:0"`
+	Edited        int            `json:"edited" gorm:"not null;default:0"`
+	Deleted       int            `json:"deleted" gorm:"not null;default:0"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"default:now()"`
+}
+
+// TableName specifies the table name for GORM
+func (AnnotationSession) TableName() string {
+	return "annotation_sessions"
+}
+
+// ProductivityRow is the work of an annotator on a project during one day.
+// HandleSeconds sums the time from claim to submit of the tasks submitted
+// that day, ActiveSeconds the active editing time of the sessions started
+// that day.
+type ProductivityRow struct {
+	Annotator      string     `json:"annotator"`
+	ProjectID      *uuid.UUID `json:"project_id"`
+	Day            string     `json:"day"`
+	Submitted      int        `json:"submitted"`
+	HandleSeconds  float64    `json:"handle_seconds"`
+	Sessions       int        `json:"sessions"`
+	ActiveSeconds  float64    `json:"active_seconds"`
+	Created        int        `json:"created"`
+	Edited         int        `json:"edited"`
+	Deleted        int        `json:"deleted"`
+	SecondsPerTask *float64   `json:"seconds_per_task"`
+	Outlier        bool       `json:"outlier"`
+}
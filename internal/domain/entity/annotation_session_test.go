@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeIntervals(t *testing.T) {
+	at := func(minute int) time.Time {
+		return time.Date(2024, 1, 1, 9, minute, 0, 0, time.UTC)
+	}
+	intervals := []Interval{
+		{Start: at(30), End: at(40)},
+		{Start: at(0), End: at(10)},
+		{Start: at(5), End: at(15)},
+		{Start: at(15), End: at(20)},
+		{Start: at(32), End: at(35)},
+	}
+
+	assert.Equal(t, []Interval{{Start: at(0), End: at(20)}, {Start: at(30), End: at(40)}}, MergeIntervals(intervals))
+	assert.Equal(t, 30*time.Minute, ActiveDuration(intervals))
+	assert.Zero(t, ActiveDuration(nil))
+}
@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Annotation task statuses
+const (
+	TaskStatusTodo       = "todo"
+	TaskStatusInProgress = "in_progress"
+	TaskStatusSubmitted  = "submitted"
+	TaskStatusApproved   = "approved"
+	TaskStatusRejected   = "rejected"
+)
+
+// AnnotationTask asks an annotator to label an image. Tasks without
+// Assignee form a pool any annotator can claim. A claim holds a lease until
+// LeaseExpiresAt; once it expires the task can be claimed by someone else.
+// HandleSeconds sums the time from claim to submit over the submissions.
+// Tasks with a higher Priority are served first, then the oldest ones.
+// An image has at most one task per assignee, so several annotators can
+// label the same image independently. Submitted annotations are reviewed;
+// approving one makes it the ground truth of the image. Gold tasks are on
+// images with trusted ground truth: their submissions are scored against it
+// in GoldScore instead. Neither is shown to annotators.
+type AnnotationTask struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ProjectID      *uuid.UUID     `json:"project_id" gorm:"type:uuid;index"`
+	ImageID        uuid.UUID      `json:"image_id" gorm:"type:uuid;not null;uniqueIndex:idx_task_image_assignee"`
+	Assignee       string         `json:"assignee" gorm:"type:text;not null;default:'';uniqueIndex:idx_task_image_assignee"`
+	Status         string         `json:"status" gorm:"type:text;not null;index"`
+	Priority       float64        `json:"priority" gorm:"not null;default:0"`
+	ClaimedBy      string         `json:"claimed_by" gorm:"type:text;not null;default:''"`
+	ClaimedAt      *time.Time     `json:"claimed_at"`
+	LeaseExpiresAt *time.Time     `json:"lease_expires_at"`
+	Annotation     datatypes.JSON `json:"annotation" gorm:"type:jsonb"`
+	SubmittedAt    *time.Time     `json:"submitted_at"`
+	HandleSeconds  float64        `json:"handle_seconds" gorm:"not null;default:0"`
+	Reviewer       string         `json:"reviewer" gorm:"type:text"`
+	ReviewComment  string         `json:"review_comment" gorm:"type:text"`
+	ReviewedAt     *time.Time     `json:"reviewed_at"`
+	Gold           bool           `json:"-" gorm:"not null;default:false"`
+	GoldScore      datatypes.JSON `json:"-" gorm:"type:jsonb"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"default:now()"`
+	UpdatedAt      time.Time      `json:"updated_at" gorm:"default:now()"`
+}
+
+// TableName specifies the table name for GORM
+func (AnnotationTask) TableName() string {
+	return "annotation_tasks"
+}
+
+// AnnotatorAccuracy scores an annotator on the gold tasks it submitted,
+// like the leaderboard scores models: micro metrics from the summed counts,
+// macro metrics averaging the per-task metrics
+type AnnotatorAccuracy struct {
+	Annotator string  `json:"annotator"`
+	Tasks     int     `json:"tasks"`
+	TP        int     `json:"tp"`
+	FP        int     `json:"fp"`
+	FN        int     `json:"fn"`
+	Micro     Metrics `json:"micro"`
+	Macro     Metrics `json:"macro"`
+}
+
+// Annotation task actions recorded in the task history
+const (
+	TaskActionAssigned  = "assigned"
+	TaskActionClaimed   = "claimed"
+	TaskActionReleased  = "released"
+	TaskActionSubmitted = "submitted"
+	TaskActionApproved  = "approved"
+	TaskActionEdited    = "edited"
+	TaskActionRejected  = "rejected"
+)
+
+// TaskEvent records an action on an annotation task and the status change
+// it caused
+type TaskEvent struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	TaskID     uuid.UUID `json:"task_id" gorm:"type:uuid;not null;index"`
+	Action     string    `json:"action" gorm:"type:text;not null"`
+	FromStatus string    `json:"from_status" gorm:"type:text"`
+	ToStatus   string    `json:"to_status" gorm:"type:text;not null"`
+	Actor      string    `json:"actor" gorm:"type:text"`
+	Comment    string    `json:"comment,omitempty" gorm:"type:text"`
+	CreatedAt  time.Time `json:"created_at" gorm:"default:now()"`
+}
+
+// TableName specifies the table name for GORM
+func (TaskEvent) TableName() string {
+	return "annotation_task_events"
+}
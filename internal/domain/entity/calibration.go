@@ -0,0 +1,29 @@
+package entity
+
+// ReliabilityBin groups the predictions whose confidence falls in
+// [Lower, Upper) and compares their mean confidence with their accuracy
+type ReliabilityBin struct {
+	Lower          float64 `json:"lower"`
+	Upper          float64 `json:"upper"`
+	Count          int     `json:"count"`
+	MeanConfidence float64 `json:"mean_confidence"`
+	Accuracy       float64 `json:"accuracy"`
+}
+
+// CalibrationReport measures how well the confidences of a model and prompt
+// version match the rate at which its predictions are correct. A prediction
+// is correct when it matches a ground truth element of the same class at
+// IoU 0.5; predictions without a confidence are only counted.
+type CalibrationReport struct {
+	Model             string           `json:"model"`
+	PromptVersion     string           `json:"prompt_version"`
+	Samples           int              `json:"samples"`
+	WithoutConfidence int              `json:"without_confidence"`
+	Accuracy          float64          `json:"accuracy"`
+	MeanConfidence    float64          `json:"mean_confidence"`
+	ECE               float64          `json:"ece"`
+	MCE               float64          `json:"mce"`
+	Brier             float64          `json:"brier"`
+	Miscalibrated     bool             `json:"miscalibrated"`
+	Bins              []ReliabilityBin `json:"bins"`
+}
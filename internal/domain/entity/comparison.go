@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// ImageScore is the stored evaluation score of one model on one image
+type ImageScore struct {
+	ImageID uuid.UUID
+	Model   string
+	Score   datatypes.JSON
+}
+
+// MetricComparison is the paired comparison of one metric between two
+// systems. Difference is A - B over all paired images; the confidence
+// interval and p-value come from paired bootstrap resampling of images, the
+// sign test from the per-image differences.
+type MetricComparison struct {
+	Metric         string   `json:"metric"`
+	A              float64  `json:"a"`
+	B              float64  `json:"b"`
+	Difference     float64  `json:"difference"`
+	CILower        float64  `json:"ci_lower"`
+	CIUpper        float64  `json:"ci_upper"`
+	BootstrapP     float64  `json:"bootstrap_p"`
+	SignTest       SignTest `json:"sign_test"`
+	Significant    bool     `json:"significant"`
+	ComparedImages int      `json:"compared_images"`
+}
+
+// SignTest counts the images where A scores higher (Wins) or lower (Losses)
+// than B, with the two-sided exact binomial p-value over non-tied images
+type SignTest struct {
+	Wins   int     `json:"wins"`
+	Losses int     `json:"losses"`
+	Ties   int     `json:"ties"`
+	P      float64 `json:"p"`
+}
+
+// ModelComparison compares two models or prompt versions on the images
+// scored for both
+type ModelComparison struct {
+	A          string             `json:"a"`
+	B          string             `json:"b"`
+	Images     int                `json:"images"`
+	Samples    int                `json:"samples"`
+	Confidence float64            `json:"confidence"`
+	Seed       int64              `json:"seed"`
+	Metrics    []MetricComparison `json:"metrics"`
+}
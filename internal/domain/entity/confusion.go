@@ -0,0 +1,84 @@
+package entity
+
+import "sort"
+
+// Background is the confusion matrix class standing for "no element": ground
+// truth elements predicted as Background were missed, predictions with a
+// Background ground truth are spurious.
+const Background = "background"
+
+// ConfusionMatrix counts elements by ground truth class (row) and predicted
+// class (column)
+type ConfusionMatrix map[string]map[string]int
+
+// Add adds n elements of class gt predicted as pred
+func (m ConfusionMatrix) Add(gt, pred string, n int) {
+	row, ok := m[gt]
+	if !ok {
+		row = map[string]int{}
+		m[gt] = row
+	}
+	row[pred] += n
+}
+
+// Classes returns the classes appearing in the matrix, sorted, with
+// Background last
+func (m ConfusionMatrix) Classes() []string {
+	seen := map[string]bool{}
+	for gt, row := range m {
+		seen[gt] = true
+		for pred := range row {
+			seen[pred] = true
+		}
+	}
+	hasBackground := seen[Background]
+	delete(seen, Background)
+
+	classes := make([]string, 0, len(seen)+1)
+	for class := range seen {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	if hasBackground {
+		classes = append(classes, Background)
+	}
+	return classes
+}
+
+// ConfusionCell is one summed cell of the confusion matrices of a model and
+// prompt version over a set of images
+type ConfusionCell struct {
+	Model         string
+	PromptVersion string
+	GroundTruth   string
+	Predicted     string
+	Count         int
+}
+
+// ClassMetrics breaks down the errors on one class. Missed elements were not
+// predicted at all, mislabeled ones were predicted as another class and
+// spurious predictions have no ground truth element.
+type ClassMetrics struct {
+	Class      string  `json:"class"`
+	Support    int     `json:"support"`
+	Predicted  int     `json:"predicted"`
+	TP         int     `json:"tp"`
+	FP         int     `json:"fp"`
+	FN         int     `json:"fn"`
+	Missed     int     `json:"missed"`
+	Mislabeled int     `json:"mislabeled"`
+	Spurious   int     `json:"spurious"`
+	Precision  float64 `json:"precision"`
+	Recall     float64 `json:"recall"`
+	F1         float64 `json:"f1"`
+}
+
+// ConfusionReport is the confusion matrix and per-class metrics of a model
+// and prompt version over a dataset
+type ConfusionReport struct {
+	Model         string          `json:"model"`
+	PromptVersion string          `json:"prompt_version"`
+	Classes       []string        `json:"classes"`
+	Matrix        ConfusionMatrix `json:"matrix"`
+	PerClass      []ClassMetrics  `json:"per_class"`
+}
@@ -0,0 +1,34 @@
+package entity
+
+import "github.com/google/uuid"
+
+// DiffEntry is one line of a prediction diff. Pairs hold both elements and
+// their IoU, false positives only the predicted element and false negatives
+// only the ground truth one. Indexes refer to the element lists of the
+// ground truth and the prediction.
+type DiffEntry struct {
+	GTIndex   *int     `json:"gt_index,omitempty"`
+	GT        *Element `json:"gt,omitempty"`
+	PredIndex *int     `json:"pred_index,omitempty"`
+	Pred      *Element `json:"pred,omitempty"`
+	IoU       *float64 `json:"iou,omitempty"`
+	// TextDistance is the edit distance between the texts of a text mismatch
+	TextDistance *int `json:"text_distance,omitempty"`
+}
+
+// PredictionDiff lists what a model got right and wrong on an image, with
+// the matching used by the evaluator. Matched holds the pairs of the same
+// class and ClassMismatches the others; TextMismatches are the pairs of
+// either kind whose texts differ. FalsePositives and FalseNegatives are the
+// elements left unmatched.
+type PredictionDiff struct {
+	ImageID         uuid.UUID       `json:"image_id"`
+	Model           string          `json:"model"`
+	PromptVersion   string          `json:"prompt_version,omitempty"`
+	Score           EvaluationScore `json:"score"`
+	Matched         []DiffEntry     `json:"matched"`
+	ClassMismatches []DiffEntry     `json:"class_mismatches"`
+	TextMismatches  []DiffEntry     `json:"text_mismatches"`
+	FalsePositives  []DiffEntry     `json:"false_positives"`
+	FalseNegatives  []DiffEntry     `json:"false_negatives"`
+}
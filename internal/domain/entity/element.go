@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"math"
+	"time"
+)
+
+// BBox is an element bounding box in pixels, x/y being the top-left corner
+type BBox struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Area returns the box area
+func (b BBox) Area() float64 {
+	return math.Max(b.Width, 0) * math.Max(b.Height, 0)
+}
+
+// IoU returns the intersection over union of two boxes
+func (b BBox) IoU(o BBox) float64 {
+	w := math.Min(b.X+b.Width, o.X+o.Width) - math.Max(b.X, o.X)
+	h := math.Min(b.Y+b.Height, o.Y+o.Height) - math.Max(b.Y, o.Y)
+	if w <= 0 || h <= 0 {
+		return 0
+	}
+	inter := w * h
+	union := b.Area() + o.Area() - inter
+	if union <= 0 {
+		return 0
+	}
+	return inter / union
+}
+
+// Element is a single UI element of a screenshot, either annotated or
+// predicted. Source names the model a pre-labeled element was taken from; it
+// is empty for elements drawn by annotators.
+type Element struct {
+	Type       string  `json:"type"`
+	Text       string  `json:"text,omitempty"`
+	BBox       BBox    `json:"bbox"`
+	Confidence float64 `json:"confidence,omitempty"`
+	Source     string  `json:"source,omitempty"`
+}
+
+// Prediction is the normalized result of one model stored under
+// predicted_labels[model]. Raw always holds the model output as reported;
+// ensemble predictions have no output and list the Members they combine
+// instead.
+type Prediction struct {
+	Raw           string    `json:"raw"`
+	Elements      []Element `json:"elements"`
+	Members       []string  `json:"members,omitempty"`
+	PromptVersion string    `json:"prompt_version,omitempty"`
+	Parser        string    `json:"parser,omitempty"`
+	Repairs       []string  `json:"repairs,omitempty"`
+	Warnings      []string  `json:"warnings,omitempty"`
+	ParseError    string    `json:"parse_error,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	Cached        bool      `json:"cached,omitempty"`
+}
+
+// EnsembleModel is the synthetic model whose prediction is the consensus of
+// the other models' predictions of an image, and the source of draft
+// elements combined from several models
+const EnsembleModel = "ensemble"
+
+// Draft is a ground truth proposal built from model predictions, from which
+// annotators start instead of drawing from scratch
+type Draft struct {
+	Models    []string  `json:"models"`
+	MinVotes  int       `json:"min_votes,omitempty"`
+	Elements  []Element `json:"elements"`
+	CreatedAt time.Time `json:"created_at"`
+}
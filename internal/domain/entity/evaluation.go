@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+// EvaluationScore is the evaluation of one model's prediction against the
+// ground truth of an image, stored under evaluation_scores[model].
+// Predicted and ground truth elements are matched one-to-one by IoU; a
+// matched pair counts as a true positive only when the types agree.
+type EvaluationScore struct {
+	PromptVersion string          `json:"prompt_version,omitempty"`
+	TP            int             `json:"tp"`
+	FP            int             `json:"fp"`
+	FN            int             `json:"fn"`
+	Precision     float64         `json:"precision"`
+	Recall        float64         `json:"recall"`
+	F1            float64         `json:"f1"`
+	Matched       int             `json:"matched"`
+	IoUSum        float64         `json:"iou_sum"`
+	MeanIoU       *float64        `json:"miou,omitempty"`
+	IoUThreshold  float64         `json:"iou_threshold"`
+	Confusion     ConfusionMatrix `json:"confusion,omitempty"`
+	Detections    []Detection     `json:"detections,omitempty"`
+	Text          *TextScore      `json:"text,omitempty"`
+	EvaluatedAt   time.Time       `json:"evaluated_at"`
+}
+
+// TextMetrics measures how well a model read the text of matched elements:
+// the share of exact matches, the mean normalized edit distance and the
+// character error rate
+type TextMetrics struct {
+	Pairs      int      `json:"pairs"`
+	ExactMatch float64  `json:"exact_match"`
+	NED        float64  `json:"ned"`
+	CER        *float64 `json:"cer"`
+}
+
+// TextScore is the text accuracy of one image, with the sums needed to
+// aggregate it over a dataset
+type TextScore struct {
+	TextMetrics
+	ExactMatches int     `json:"exact_matches"`
+	EditDistance int     `json:"edit_distance"`
+	GTChars      int     `json:"gt_chars"`
+	NEDSum       float64 `json:"ned_sum"`
+}
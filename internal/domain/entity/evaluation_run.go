@@ -0,0 +1,110 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Evaluation run statuses
+const (
+	RunStatusCreated   = "created"
+	RunStatusRunning   = "running"
+	RunStatusCompleted = "completed"
+	RunStatusCancelled = "cancelled"
+)
+
+// Run result statuses
+const (
+	RunResultPending   = "pending"
+	RunResultQueued    = "queued"
+	RunResultPredicted = "predicted"
+	RunResultFailed    = "failed"
+)
+
+// EvaluationRun applies one model, prompt version and set of parameters to a
+// snapshot of images. The snapshot is the set of its RunResults, fixed when
+// the run is created.
+type EvaluationRun struct {
+	ID            uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ProjectID     *uuid.UUID     `json:"project_id" gorm:"type:uuid;index"`
+	BatchID       *uuid.UUID     `json:"batch_id,omitempty" gorm:"type:uuid;index"`
+	Name          string         `json:"name" gorm:"type:text"`
+	Model         string         `json:"model" gorm:"type:text;not null;index"`
+	PromptVersion string         `json:"prompt_version" gorm:"type:text"`
+	Parameters    datatypes.JSON `json:"parameters" gorm:"type:jsonb"`
+	Filter        datatypes.JSON `json:"filter" gorm:"type:jsonb"`
+	Status        string         `json:"status" gorm:"type:text;not null"`
+	ImageCount    int            `json:"image_count"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"default:now()"`
+	UpdatedAt     time.Time      `json:"updated_at" gorm:"default:now()"`
+	Summary       *RunSummary    `json:"summary,omitempty" gorm:"-"`
+}
+
+// TableName specifies the table name for GORM
+func (EvaluationRun) TableName() string {
+	return "evaluation_runs"
+}
+
+// RunResult is the prediction and score of one image in a run
+type RunResult struct {
+	RunID      uuid.UUID      `json:"run_id" gorm:"type:uuid;primaryKey"`
+	ImageID    uuid.UUID      `json:"image_id" gorm:"type:uuid;primaryKey;index"`
+	Status     string         `json:"status" gorm:"type:text;not null;index"`
+	Prediction datatypes.JSON `json:"prediction" gorm:"type:jsonb"`
+	Score      datatypes.JSON `json:"score" gorm:"type:jsonb"`
+	UpdatedAt  time.Time      `json:"updated_at" gorm:"default:now()"`
+}
+
+// TableName specifies the table name for GORM
+func (RunResult) TableName() string {
+	return "run_results"
+}
+
+// RunProgress counts the results of a run by status. Queued results have
+// been dispatched and wait for the model.
+type RunProgress struct {
+	Total     int `json:"total"`
+	Pending   int `json:"pending"`
+	Queued    int `json:"queued"`
+	Predicted int `json:"predicted"`
+	Failed    int `json:"failed"`
+	Scored    int `json:"scored"`
+}
+
+// RunSummary is the progress and the aggregated metrics of a run
+type RunSummary struct {
+	RunProgress
+	TP    int     `json:"tp"`
+	FP    int     `json:"fp"`
+	FN    int     `json:"fn"`
+	Micro Metrics `json:"micro"`
+	Macro Metrics `json:"macro"`
+}
+
+// RunComparison puts two runs side by side: their summaries, the per-image
+// scores on the images both scored, and the significance of the differences
+type RunComparison struct {
+	A       *EvaluationRun     `json:"a"`
+	B       *EvaluationRun     `json:"b"`
+	Images  []RunImageScores   `json:"images"`
+	Metrics []MetricComparison `json:"metrics"`
+}
+
+// RunImageScores holds the scores of two runs on one image
+type RunImageScores struct {
+	ImageID uuid.UUID        `json:"image_id"`
+	A       *EvaluationScore `json:"a"`
+	B       *EvaluationScore `json:"b"`
+}
+
+// Add adds the counts of other to the progress
+func (p *RunProgress) Add(other RunProgress) {
+	p.Total += other.Total
+	p.Pending += other.Pending
+	p.Queued += other.Queued
+	p.Predicted += other.Predicted
+	p.Failed += other.Failed
+	p.Scored += other.Scored
+}
@@ -7,16 +7,27 @@ import (
 	"gorm.io/datatypes"
 )
 
-// Image represents the core domain entity for images
+// Image represents the core domain entity for images. Gold images have
+// trusted ground truth used to score annotators; whether an image is gold is
+// not serialized, so annotators cannot tell. Draft holds the
+// pre-labeled elements proposed to annotators of images without ground truth.
 type Image struct {
-	ID               uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Name             string         `json:"name" gorm:"type:text;not null"`
-	MinioPath        string         `json:"minio_path" gorm:"type:text;not null"`
-	GroundTruth      datatypes.JSON `json:"ground_truth" gorm:"type:jsonb"`
-	PredictedLabels  datatypes.JSON `json:"predicted_labels" gorm:"type:jsonb"`
-	EvaluationScores datatypes.JSON `json:"evaluation_scores" gorm:"type:jsonb"`
-	CreatedAt        time.Time      `json:"created_at" gorm:"default:now()"`
-	UpdatedAt        time.Time      `json:"updated_at" gorm:"default:now()"`
+	ID               uuid.UUID                   `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ProjectID        *uuid.UUID                  `json:"project_id" gorm:"type:uuid;index"`
+	Name             string                      `json:"name" gorm:"type:text;not null"`
+	MinioPath        string                      `json:"minio_path" gorm:"type:text;not null"`
+	Width            int                         `json:"width"`
+	Height           int                         `json:"height"`
+	ContentHash      string                      `json:"content_hash" gorm:"type:text;index"`
+	Tags             datatypes.JSONSlice[string] `json:"tags" gorm:"type:jsonb"`
+	Split            string                      `json:"split" gorm:"type:text;index"`
+	Gold             bool                        `json:"-" gorm:"not null;default:false;index"`
+	GroundTruth      datatypes.JSON              `json:"ground_truth" gorm:"type:jsonb"`
+	Draft            datatypes.JSON              `json:"draft" gorm:"type:jsonb"`
+	PredictedLabels  datatypes.JSON              `json:"predicted_labels" gorm:"type:jsonb"`
+	EvaluationScores datatypes.JSON              `json:"evaluation_scores" gorm:"type:jsonb"`
+	CreatedAt        time.Time                   `json:"created_at" gorm:"default:now()"`
+	UpdatedAt        time.Time                   `json:"updated_at" gorm:"default:now()"`
 }
 
 // TableName specifies the table name for GORM
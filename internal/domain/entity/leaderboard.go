@@ -0,0 +1,56 @@
+package entity
+
+// ScoreAggregate sums the per-image evaluation scores of one model and prompt
+// version over a set of images
+type ScoreAggregate struct {
+	Model          string
+	PromptVersion  string
+	Images         int
+	TP             int
+	FP             int
+	FN             int
+	Matched        int
+	IoUSum         float64 `gorm:"column:iou_sum"`
+	MacroPrecision float64
+	MacroRecall    float64
+	MacroF1        float64
+	MacroMeanIoU   *float64 `gorm:"column:macro_miou"`
+
+	TextPairs           int
+	TextExactMatches    int
+	TextEditDistance    int
+	TextGTChars         int     `gorm:"column:text_gt_chars"`
+	TextNEDSum          float64 `gorm:"column:text_ned_sum"`
+	MacroTextExactMatch *float64
+	MacroTextNED        *float64 `gorm:"column:macro_text_ned"`
+	MacroTextCER        *float64 `gorm:"column:macro_text_cer"`
+}
+
+// AnnotatorScoreAggregate sums the gold scores of one annotator
+type AnnotatorScoreAggregate struct {
+	Annotator string
+	ScoreAggregate
+}
+
+// Metrics holds averaged detection metrics
+type Metrics struct {
+	Precision float64      `json:"precision"`
+	Recall    float64      `json:"recall"`
+	F1        float64      `json:"f1"`
+	MeanIoU   *float64     `json:"miou"`
+	Text      *TextMetrics `json:"text,omitempty"`
+}
+
+// LeaderboardEntry ranks a model and prompt version over a dataset. Micro
+// metrics are computed from the summed counts, macro metrics average the
+// per-image metrics.
+type LeaderboardEntry struct {
+	Model         string  `json:"model"`
+	PromptVersion string  `json:"prompt_version"`
+	Images        int     `json:"images"`
+	TP            int     `json:"tp"`
+	FP            int     `json:"fp"`
+	FN            int     `json:"fn"`
+	Micro         Metrics `json:"micro"`
+	Macro         Metrics `json:"macro"`
+}
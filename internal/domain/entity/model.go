@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Model providers
+const (
+	// ProviderOpenAI runs in-process against an OpenAI-compatible chat-completions API
+	ProviderOpenAI = "openai"
+)
+
+// Model represents a registered prediction model and its provider
+// configuration. APIKey is stored in plaintext in the database; APIKeyEnv
+// instead names an environment variable of the backend holding the key, so
+// the secret stays out of the database.
+type Model struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name        string    `json:"name" gorm:"type:text;not null;uniqueIndex"`
+	Provider    string    `json:"provider" gorm:"type:text;not null"`
+	BaseURL     string    `json:"base_url" gorm:"type:text"`
+	ModelName   string    `json:"model_name" gorm:"type:text"`
+	APIKey      string    `json:"-" gorm:"type:text"`
+	APIKeyEnv   string    `json:"api_key_env" gorm:"type:text"`
+	MaxTokens   int       `json:"max_tokens"`
+	Temperature float64   `json:"temperature"`
+	Enabled     bool      `json:"enabled"`
+	CreatedAt   time.Time `json:"created_at" gorm:"default:now()"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"default:now()"`
+}
+
+// TableName specifies the table name for GORM
+func (Model) TableName() string {
+	return "models"
+}
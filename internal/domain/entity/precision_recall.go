@@ -0,0 +1,54 @@
+package entity
+
+// Detection is a predicted element scored COCO-style: TPMask has bit i set
+// when the element is a true positive at the i-th IoU threshold of
+// 0.5, 0.55, ..., 0.95
+type Detection struct {
+	Class      string  `json:"class"`
+	Confidence float64 `json:"confidence"`
+	TPMask     int     `json:"tp_mask"`
+}
+
+// DetectionRecord is a stored detection of a model and prompt version
+type DetectionRecord struct {
+	Model         string
+	PromptVersion string
+	Class         string
+	Confidence    float64
+	TPMask        int `gorm:"column:tp_mask"`
+}
+
+// PRPoint is the precision and recall obtained by keeping the predictions
+// with a confidence of at least Threshold
+type PRPoint struct {
+	Threshold float64 `json:"threshold"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+}
+
+// ClassAP is the average precision of one class. Curve is the PR curve at
+// IoU 0.5.
+type ClassAP struct {
+	Class       string    `json:"class"`
+	GroundTruth int       `json:"ground_truth"`
+	Detections  int       `json:"detections"`
+	AP          float64   `json:"ap"`
+	AP50        float64   `json:"ap50"`
+	AP75        float64   `json:"ap75"`
+	Curve       []PRPoint `json:"curve"`
+}
+
+// PrecisionRecallReport is the threshold sweep of a model and prompt version
+// over a dataset. MAP is the COCO mAP@[.5:.95]; Curve pools all classes at
+// IoU 0.5 and BestThreshold is its F1-optimal point.
+type PrecisionRecallReport struct {
+	Model         string    `json:"model"`
+	PromptVersion string    `json:"prompt_version"`
+	MAP           float64   `json:"map"`
+	MAP50         float64   `json:"map50"`
+	MAP75         float64   `json:"map75"`
+	BestThreshold *PRPoint  `json:"best_threshold"`
+	Curve         []PRPoint `json:"curve"`
+	Classes       []ClassAP `json:"classes"`
+}
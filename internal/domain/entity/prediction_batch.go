@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Prediction batch statuses
+const (
+	BatchStatusRunning         = "running"
+	BatchStatusBudgetExhausted = "budget_exhausted"
+	BatchStatusCancelled       = "cancelled"
+	BatchStatusCompleted       = "completed"
+)
+
+// PredictionBatch predicts every image of a project matching a filter with
+// the selected models. It creates one evaluation run per model over the
+// same snapshot and dispatches their pending images in the background, with
+// at most Concurrency predictions in flight and at most Budget predictions
+// dispatched in total (0 means no limit).
+type PredictionBatch struct {
+	ID            uuid.UUID                   `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ProjectID     uuid.UUID                   `json:"project_id" gorm:"type:uuid;not null;index"`
+	Name          string                      `json:"name" gorm:"type:text"`
+	Models        datatypes.JSONSlice[string] `json:"models" gorm:"type:jsonb"`
+	PromptVersion string                      `json:"prompt_version" gorm:"type:text"`
+	Filter        datatypes.JSON              `json:"filter" gorm:"type:jsonb"`
+	Force         bool                        `json:"force"`
+	Priority      string                      `json:"priority" gorm:"type:text"`
+	Concurrency   int                         `json:"concurrency"`
+	Budget        int                         `json:"budget"`
+	Dispatched    int                         `json:"dispatched"`
+	Status        string                      `json:"status" gorm:"type:text;not null;index"`
+	CreatedAt     time.Time                   `json:"created_at" gorm:"default:now()"`
+	UpdatedAt     time.Time                   `json:"updated_at" gorm:"default:now()"`
+	Progress      *RunProgress                `json:"progress,omitempty" gorm:"-"`
+	Runs          []*EvaluationRun            `json:"runs,omitempty" gorm:"-"`
+}
+
+// TableName specifies the table name for GORM
+func (PredictionBatch) TableName() string {
+	return "prediction_batches"
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// PredictionCacheEntry stores the normalized prediction of a model for a
+// screenshot content hash and prompt version, so identical screenshots are
+// not sent to the providers again
+type PredictionCacheEntry struct {
+	ContentHash   string         `json:"content_hash" gorm:"type:text;primaryKey"`
+	Model         string         `json:"model" gorm:"type:text;primaryKey"`
+	PromptVersion string         `json:"prompt_version" gorm:"type:text;primaryKey"`
+	Prediction    datatypes.JSON `json:"prediction" gorm:"type:jsonb;not null"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"default:now()"`
+}
+
+// TableName specifies the table name for GORM
+func (PredictionCacheEntry) TableName() string {
+	return "prediction_cache"
+}
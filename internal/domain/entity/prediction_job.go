@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// PredictionJob is an active prediction of an image by one model. Queued
+// jobs wait in a model queue; running jobs are being predicted by a worker
+// or in-process. The job is tracked in Redis, not in the database.
+type PredictionJob struct {
+	ID            string     `json:"id"`
+	ImageID       string     `json:"image_id"`
+	Model         string     `json:"model"`
+	RunID         string     `json:"run_id,omitempty"`
+	PromptVersion string     `json:"prompt_version,omitempty"`
+	Priority      string     `json:"priority"`
+	Status        string     `json:"status"`
+	Attempt       int        `json:"attempt"`
+	QueuedAt      time.Time  `json:"queued_at"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+}
+
+// Job events emitted by the stuck-job watchdog
+const (
+	JobEventTimedOut = "timed_out"
+	JobEventRetried  = "retried"
+	JobEventFailed   = "failed"
+)
+
+// JobEvent records what happened to a prediction job
+type JobEvent struct {
+	Type   string         `json:"event"`
+	Job    *PredictionJob `json:"job"`
+	Reason string         `json:"reason,omitempty"`
+	At     time.Time      `json:"at"`
+}
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Project groups the images of a labeling dataset. When PrelabelModels is
+// set, images without ground truth get a draft built from the predictions of
+// these models; with several models, an element is kept when at least
+// PrelabelMinVotes of them found it.
+type Project struct {
+	ID               uuid.UUID                   `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name             string                      `json:"name" gorm:"type:text;not null"`
+	Description      string                      `json:"description" gorm:"type:text"`
+	PrelabelModels   datatypes.JSONSlice[string] `json:"prelabel_models" gorm:"type:jsonb"`
+	PrelabelMinVotes int                         `json:"prelabel_min_votes" gorm:"not null;default:0"`
+	CreatedAt        time.Time                   `json:"created_at" gorm:"default:now()"`
+	UpdatedAt        time.Time                   `json:"updated_at" gorm:"default:now()"`
+}
+
+// TableName specifies the table name for GORM
+func (Project) TableName() string {
+	return "projects"
+}
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PromptTemplate is an immutable, versioned prompt used by a model. At most one
+// version per model is active, enforced by a partial unique index; it is used
+// when a predict request does not ask for a specific version.
+type PromptTemplate struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Model       string    `json:"model" gorm:"type:text;not null;uniqueIndex:idx_prompt_model_version;uniqueIndex:idx_prompt_active_model,where:active"`
+	Version     string    `json:"version" gorm:"type:text;not null;uniqueIndex:idx_prompt_model_version"`
+	Template    string    `json:"template" gorm:"type:text;not null"`
+	Description string    `json:"description" gorm:"type:text"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at" gorm:"default:now()"`
+}
+
+// TableName specifies the table name for GORM
+func (PromptTemplate) TableName() string {
+	return "prompt_templates"
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "github.com/google/uuid"
+
+// ImageUncertainty ranks an image for annotation by how unsure the models
+// are about it. Disagreement, the lack of confidence and Density are each in
+// [0, 1] and combined into Score; unknown signals are nil.
+type ImageUncertainty struct {
+	ImageID uuid.UUID `json:"image_id"`
+	Models  []string  `json:"models"`
+	// Elements is the mean number of elements predicted per model
+	Elements float64 `json:"elements"`
+	// Disagreement is one minus the mean F1 of the model pairs, nil with
+	// fewer than two models
+	Disagreement *float64 `json:"disagreement"`
+	// MeanConfidence averages the confidence of the predicted elements that
+	// have one
+	MeanConfidence *float64 `json:"mean_confidence"`
+	// Density is Elements relative to the densest image ranked
+	Density float64 `json:"density"`
+	Score   float64 `json:"score"`
+}
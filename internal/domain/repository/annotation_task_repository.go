@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"gorm.io/datatypes"
+)
+
+// TaskFilter selects annotation tasks. Zero fields are ignored.
+type TaskFilter struct {
+	ProjectID *uuid.UUID
+	ImageID   *uuid.UUID
+	Assignee  *string
+	Status    string
+	Gold      *bool
+}
+
+// ProductivityFilter selects the work reported by ProductivityReport. Zero
+// fields are ignored.
+type ProductivityFilter struct {
+	ProjectID *uuid.UUID
+	Annotator string
+	From      *time.Time
+	To        *time.Time
+}
+
+// ClaimedTask is a task leased by ClaimNext with its status and claimant
+// before the claim
+type ClaimedTask struct {
+	Task             *entity.AnnotationTask
+	PreviousStatus   string
+	PreviousClaimant string
+}
+
+// AnnotationTaskRepository defines the interface for annotation task data operations
+type AnnotationTaskRepository interface {
+	// Create saves new tasks, skipping images that already have a task for
+	// the same assignee. It returns the number of tasks created.
+	Create(ctx context.Context, tasks []*entity.AnnotationTask) (int64, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.AnnotationTask, error)
+	List(ctx context.Context, filter TaskFilter) ([]*entity.AnnotationTask, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByImage(ctx context.Context, imageID uuid.UUID) error
+	// Assign gives an open task to an assignee, dropping its claim
+	Assign(ctx context.Context, id uuid.UUID, assignee string) (bool, error)
+	// Claim leases an open task, or a task rejected from the annotator, to
+	// the annotator until lease. It returns false when the task is not
+	// claimable, is assigned to someone else, is leased by someone else or
+	// the annotator already has another task for the image.
+	Claim(ctx context.Context, id uuid.UUID, annotator string, lease time.Time) (bool, error)
+	// ClaimNext leases the next task of an annotator: the tasks it holds or
+	// got rejected, then tasks assigned to it, then pool tasks, oldest first.
+	// It returns nil when no task is left.
+	ClaimNext(ctx context.Context, annotator string, projectID *uuid.UUID, lease time.Time) (*ClaimedTask, error)
+	// Release gives up the claim of an annotator on an in-progress task
+	Release(ctx context.Context, id uuid.UUID, annotator string) (bool, error)
+	// Submit stores the annotation of a task claimed by the annotator
+	Submit(ctx context.Context, id uuid.UUID, annotator string, annotation datatypes.JSON) (bool, error)
+	// Propose stores an annotation as submitted, on the task of the image and
+	// assignee if there is one
+	Propose(ctx context.Context, task *entity.AnnotationTask) error
+	// Review approves or rejects a submitted task. A non-nil annotation
+	// replaces the submitted one.
+	Review(ctx context.Context, id uuid.UUID, status, reviewer, comment string, annotation datatypes.JSON) (bool, error)
+	SetGoldScore(ctx context.Context, id uuid.UUID, score datatypes.JSON) error
+	// AggregateGoldScores sums the gold scores of the tasks of a project by
+	// the annotator who submitted them
+	AggregateGoldScores(ctx context.Context, projectID uuid.UUID) ([]*entity.AnnotatorScoreAggregate, error)
+	AddSession(ctx context.Context, session *entity.AnnotationSession) error
+	// ProductivityReport sums the submissions and sessions of the annotators
+	// by annotator, project and day
+	ProductivityReport(ctx context.Context, filter ProductivityFilter) ([]*entity.ProductivityRow, error)
+	AddEvent(ctx context.Context, event *entity.TaskEvent) error
+	// GetEvents returns the history of a task, oldest first
+	GetEvents(ctx context.Context, taskID uuid.UUID) ([]*entity.TaskEvent, error)
+}
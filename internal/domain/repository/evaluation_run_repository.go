@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"gorm.io/datatypes"
+)
+
+// EvaluationRunRepository defines the interface for evaluation run data operations
+type EvaluationRunRepository interface {
+	// Create stores a run together with a pending result per image
+	Create(ctx context.Context, run *entity.EvaluationRun, imageIDs []uuid.UUID) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.EvaluationRun, error)
+	GetAll(ctx context.Context, projectID *uuid.UUID) ([]*entity.EvaluationRun, error)
+	GetByBatch(ctx context.Context, batchID uuid.UUID) ([]*entity.EvaluationRun, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status string) error
+	// Delete removes a run and its results
+	Delete(ctx context.Context, id uuid.UUID) error
+	// SetPrediction stores the prediction of an image of the run. It returns
+	// gorm.ErrRecordNotFound when the image is not part of the run.
+	SetPrediction(ctx context.Context, runID, imageID uuid.UUID, status string, prediction, score datatypes.JSON) error
+	SetScore(ctx context.Context, runID, imageID uuid.UUID, score datatypes.JSON) error
+	// ClaimPending marks up to limit pending results of a run queued and
+	// returns their images. Concurrent claims never return the same image.
+	ClaimPending(ctx context.Context, runID uuid.UUID, limit int) ([]uuid.UUID, error)
+	// ReleaseQueued sets the result of an image pending again if it is queued
+	ReleaseQueued(ctx context.Context, runID, imageID uuid.UUID) error
+	// RequeueFailed sets the failed results of a run pending again
+	RequeueFailed(ctx context.Context, runID uuid.UUID) (int64, error)
+	// CompleteIfDone marks a running run completed once no result is pending
+	// or queued
+	CompleteIfDone(ctx context.Context, id uuid.UUID) error
+	GetResults(ctx context.Context, runID uuid.UUID, status string) ([]*entity.RunResult, error)
+	GetResultsByImage(ctx context.Context, imageID uuid.UUID) ([]*entity.RunResult, error)
+	DeleteResultsByImage(ctx context.Context, imageID uuid.UUID) error
+	GetProgress(ctx context.Context, runID uuid.UUID) (*entity.RunProgress, error)
+	AggregateScores(ctx context.Context, runID uuid.UUID) (*entity.ScoreAggregate, error)
+}
@@ -2,11 +2,25 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/label-platform-backend/internal/domain/entity"
+	"gorm.io/datatypes"
 )
 
+// ImageFilter selects images of a dataset. Zero fields are ignored; Tags
+// matches images carrying all of the given tags.
+type ImageFilter struct {
+	IDs       []uuid.UUID `json:"ids,omitempty"`
+	ProjectID *uuid.UUID  `json:"project_id,omitempty"`
+	Tags      []string    `json:"tags,omitempty"`
+	Split     string      `json:"split,omitempty"`
+	Gold      *bool       `json:"gold,omitempty"`
+	From      *time.Time  `json:"from,omitempty"`
+	To        *time.Time  `json:"to,omitempty"`
+}
+
 // ImageRepository defines the interface for image data operations
 type ImageRepository interface {
 	Create(ctx context.Context, image *entity.Image) error
@@ -14,4 +28,18 @@ type ImageRepository interface {
 	GetAll(ctx context.Context) ([]*entity.Image, error)
 	Update(ctx context.Context, image *entity.Image) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	SetPredictedLabel(ctx context.Context, id uuid.UUID, model string, value datatypes.JSON) error
+	SetDraft(ctx context.Context, id uuid.UUID, draft datatypes.JSON) error
+	SetGroundTruth(ctx context.Context, id uuid.UUID, groundTruth datatypes.JSON) error
+	SetGold(ctx context.Context, id uuid.UUID, gold bool) error
+	UpdateMetadata(ctx context.Context, id uuid.UUID, projectID *uuid.UUID, tags []string, split string) error
+	MergeEvaluationScores(ctx context.Context, id uuid.UUID, scores datatypes.JSON) error
+	UpdateDimensions(ctx context.Context, id uuid.UUID, width, height int) error
+	UpdateContentHash(ctx context.Context, id uuid.UUID, contentHash string) error
+	AggregateScores(ctx context.Context, filter ImageFilter) ([]*entity.ScoreAggregate, error)
+	AggregateConfusion(ctx context.Context, filter ImageFilter, model string) ([]*entity.ConfusionCell, error)
+	ListDetections(ctx context.Context, filter ImageFilter, model string) ([]*entity.DetectionRecord, error)
+	ListScores(ctx context.Context, filter ImageFilter, models []string) ([]*entity.ImageScore, error)
+	ListIDs(ctx context.Context, filter ImageFilter) ([]uuid.UUID, error)
+	List(ctx context.Context, filter ImageFilter) ([]*entity.Image, error)
 }
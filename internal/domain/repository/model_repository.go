@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// ModelRepository defines the interface for registered model data operations
+type ModelRepository interface {
+	Create(ctx context.Context, model *entity.Model) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Model, error)
+	GetAll(ctx context.Context) ([]*entity.Model, error)
+	GetEnabled(ctx context.Context) ([]*entity.Model, error)
+	Update(ctx context.Context, model *entity.Model) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
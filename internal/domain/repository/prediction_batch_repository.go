@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// PredictionBatchRepository defines the interface for prediction batch data operations
+type PredictionBatchRepository interface {
+	Create(ctx context.Context, batch *entity.PredictionBatch) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.PredictionBatch, error)
+	GetAll(ctx context.Context, projectID *uuid.UUID) ([]*entity.PredictionBatch, error)
+	GetByStatus(ctx context.Context, status string) ([]*entity.PredictionBatch, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	// UpdateStatus moves a batch from one of the from statuses to status. It
+	// returns false when the batch is in none of them.
+	UpdateStatus(ctx context.Context, id uuid.UUID, status string, from ...string) (bool, error)
+	UpdateBudget(ctx context.Context, id uuid.UUID, budget int) error
+	AddDispatched(ctx context.Context, id uuid.UUID, n int) error
+}
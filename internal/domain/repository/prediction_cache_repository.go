@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// PredictionCacheRepository defines the interface for cached prediction data operations
+type PredictionCacheRepository interface {
+	Get(ctx context.Context, contentHash, model, promptVersion string) (*entity.PredictionCacheEntry, error)
+	Put(ctx context.Context, entry *entity.PredictionCacheEntry) error
+	CountByModel(ctx context.Context) (map[string]int64, error)
+	Delete(ctx context.Context, model string) (int64, error)
+}
+
+// PredictionCacheStatsRepository defines the interface for the per-model
+// counters of prediction cache outcomes
+type PredictionCacheStatsRepository interface {
+	Incr(ctx context.Context, model, outcome string) error
+	GetAll(ctx context.Context) (map[string]map[string]int64, error)
+	Reset(ctx context.Context, model string) error
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// ProjectRepository defines the interface for project data operations
+type ProjectRepository interface {
+	Create(ctx context.Context, project *entity.Project) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Project, error)
+	GetAll(ctx context.Context) ([]*entity.Project, error)
+	Update(ctx context.Context, project *entity.Project) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
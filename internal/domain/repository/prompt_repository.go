@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// PromptRepository defines the interface for prompt template data operations
+type PromptRepository interface {
+	// Create saves a new prompt template, made active when its model has no
+	// active prompt
+	Create(ctx context.Context, prompt *entity.PromptTemplate) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.PromptTemplate, error)
+	GetByVersion(ctx context.Context, model, version string) (*entity.PromptTemplate, error)
+	GetActive(ctx context.Context, model string) (*entity.PromptTemplate, error)
+	GetAll(ctx context.Context, model string) ([]*entity.PromptTemplate, error)
+	Activate(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
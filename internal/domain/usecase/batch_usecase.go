@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+)
+
+// BatchRequest describes a prediction batch over the images of a project
+// matching Filter. Empty Models selects every queue and enabled in-process
+// model. Batch jobs have low priority unless Priority says otherwise.
+type BatchRequest struct {
+	Name           string
+	Models         []string
+	PromptVersion  string
+	PromptVersions map[string]string
+	Parameters     map[string]any
+	Force          bool
+	Priority       string
+	Concurrency    int
+	Budget         int
+	Filter         repository.ImageFilter
+}
+
+// BatchUseCase defines the interface for prediction batch business logic
+type BatchUseCase interface {
+	CreateBatch(ctx context.Context, projectID uuid.UUID, req BatchRequest) (*entity.PredictionBatch, error)
+	// GetBatch returns a batch with its runs and progress
+	GetBatch(ctx context.Context, id uuid.UUID) (*entity.PredictionBatch, error)
+	GetAllBatches(ctx context.Context, projectID *uuid.UUID) ([]*entity.PredictionBatch, error)
+	// CancelBatch stops dispatching the pending images of a batch and
+	// cancels its queued and running jobs
+	CancelBatch(ctx context.Context, id uuid.UUID) (*entity.PredictionBatch, error)
+	// ResumeBatch dispatches the pending images of a stopped batch again,
+	// optionally with a new budget and retrying failed images
+	ResumeBatch(ctx context.Context, id uuid.UUID, budget *int, retryFailed bool) (*entity.PredictionBatch, error)
+	// Dispatch runs one dispatch pass over the running batches
+	Dispatch(ctx context.Context) error
+}
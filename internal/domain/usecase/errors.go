@@ -0,0 +1,10 @@
+package usecase
+
+import "errors"
+
+// ErrInvalidInput is wrapped by use case errors caused by invalid client input
+var ErrInvalidInput = errors.New("invalid input")
+
+// ErrConflict is wrapped by use case errors caused by a conflicting state,
+// e.g. a task claimed by someone else
+var ErrConflict = errors.New("conflict")
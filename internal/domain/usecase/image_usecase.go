@@ -9,13 +9,61 @@ import (
 	"github.com/label-platform-backend/internal/domain/entity"
 )
 
+// ImageMetadata holds the dataset attributes of an image
+type ImageMetadata struct {
+	ProjectID *uuid.UUID
+	Tags      []string
+	Split     string
+}
+
+// PredictionReport is a prediction result reported by a model. RunID is set
+// when the prediction belongs to an evaluation run. CacheVersion is the
+// prompt version the prediction was requested with, which keys the
+// prediction cache; PromptVersion may differ when a model used its own
+// prompt. Reports without CacheVersion are not cached.
+type PredictionReport struct {
+	ImageID       uuid.UUID
+	Model         string
+	PromptVersion string
+	CacheVersion  *string
+	RunID         *uuid.UUID
+	Result        string
+	Error         string
+}
+
+// PrelabelRequest selects the models a draft ground truth is built from.
+// MinVotes applies to several models; zero means a majority of them.
+type PrelabelRequest struct {
+	Models   []string
+	MinVotes int
+}
+
 // ImageUseCase defines the interface for image business logic
 type ImageUseCase interface {
-	UploadImage(ctx context.Context, file *multipart.FileHeader, groundTruth map[string]any) (*entity.Image, error)
+	UploadImage(ctx context.Context, file *multipart.FileHeader, metadata ImageMetadata) (*entity.Image, error)
 	GetImageByID(ctx context.Context, id uuid.UUID) (*entity.Image, error)
 	GetAllImages(ctx context.Context) ([]*entity.Image, error)
 	UpdateImage(ctx context.Context, id uuid.UUID, predictedLabels map[string]any, evaluationScores map[string]any) (*entity.Image, error)
 	UpdateGroundTruth(ctx context.Context, id uuid.UUID, groundTruth map[string]any) (*entity.Image, error)
+	UpdateImageMetadata(ctx context.Context, id uuid.UUID, metadata ImageMetadata) (*entity.Image, error)
+	// SetGold marks an image with ground truth as gold, or unmarks it
+	SetGold(ctx context.Context, id uuid.UUID, gold bool) (*entity.Image, error)
+	// DraftGroundTruth builds the draft ground truth of an image from model
+	// predictions, using the pre-labeling setting of its project when the
+	// request names no model
+	DraftGroundTruth(ctx context.Context, id uuid.UUID, request PrelabelRequest) (*entity.Image, error)
+	EvaluateImage(ctx context.Context, id uuid.UUID) (*entity.Image, error)
+	// RenderImage draws layers of elements over an image and returns it as a
+	// PNG. Layers are "gt", "draft" or the name of a model.
+	RenderImage(ctx context.Context, id uuid.UUID, layers []string) ([]byte, error)
+	// ComparePrediction diffs the prediction of a model with the ground truth
+	// of an image
+	ComparePrediction(ctx context.Context, id uuid.UUID, model string) (*entity.PredictionDiff, error)
 	DeleteImage(ctx context.Context, id uuid.UUID) error
 	GetImageURL(ctx context.Context, minioPath string, expiry time.Duration) (string, error)
+	SavePrediction(ctx context.Context, report PredictionReport) error
+	StorePrediction(ctx context.Context, id uuid.UUID, model string, prediction *entity.Prediction, runID *uuid.UUID) error
+	// CombinePredictions stores the consensus of the models' predictions of
+	// an image as the prediction of the ensemble model
+	CombinePredictions(ctx context.Context, id uuid.UUID) (*entity.Prediction, error)
 }
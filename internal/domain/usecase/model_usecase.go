@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// ModelUseCase defines the interface for registered model business logic
+type ModelUseCase interface {
+	CreateModel(ctx context.Context, model *entity.Model) error
+	GetModelByID(ctx context.Context, id uuid.UUID) (*entity.Model, error)
+	GetAllModels(ctx context.Context) ([]*entity.Model, error)
+	UpdateModel(ctx context.Context, model *entity.Model) error
+	DeleteModel(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// Prediction modes
+const (
+	// ModeQueue means the job was pushed to an external worker queue
+	ModeQueue = "queue"
+	// ModeInProcess means the model runs inside the backend
+	ModeInProcess = "in_process"
+	// ModeCache means the prediction was served from the prediction cache
+	ModeCache = "cache"
+)
+
+// PredictOptions tunes a predict request
+type PredictOptions struct {
+	// PromptVersion selects the prompt of every model
+	PromptVersion string
+	// PromptVersions overrides PromptVersion per model
+	PromptVersions map[string]string
+	// Force bypasses the prediction cache
+	Force bool
+	// Models restricts the prediction to these models; empty means all
+	Models []string
+	// RunID attributes the predictions to an evaluation run, whose model,
+	// prompt version and parameters replace the other options
+	RunID *uuid.UUID
+	// Parameters are passed to the models, e.g. temperature
+	Parameters map[string]any
+	// Priority of the queue jobs: high, normal (default) or low
+	Priority string
+	// Attempt numbers the retries of a timed-out job; 0 is the first attempt
+	Attempt int
+}
+
+// JobFilter selects active prediction jobs. Zero fields are ignored.
+type JobFilter struct {
+	ImageID *uuid.UUID
+	Model   string
+	RunIDs  []uuid.UUID
+}
+
+// ModelDispatch describes how a model was asked to predict an image
+type ModelDispatch struct {
+	Model         string `json:"model"`
+	JobID         string `json:"job_id,omitempty"`
+	Mode          string `json:"mode"`
+	PromptVersion string `json:"prompt_version,omitempty"`
+}
+
+// CacheStats reports the prediction cache usage of a model
+type CacheStats struct {
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	Bypassed int64   `json:"bypassed"`
+	HitRate  float64 `json:"hit_rate"`
+	Entries  int64   `json:"entries"`
+}
+
+// PredictionUseCase defines the interface for dispatching predictions to models
+type PredictionUseCase interface {
+	// PredictImage sends an image to every queue worker and enabled in-process
+	// model, serving cached predictions when available. Models use their
+	// active prompt unless opts selects a version.
+	PredictImage(ctx context.Context, id uuid.UUID, opts PredictOptions) ([]ModelDispatch, error)
+	GetCacheStats(ctx context.Context) (map[string]*CacheStats, error)
+	// GetJobs lists the queued and running jobs matching the filter
+	GetJobs(ctx context.Context, filter JobFilter) ([]*entity.PredictionJob, error)
+	// CancelJobs cancels the queued and running jobs matching the filter.
+	// Run results waiting for a cancelled job become pending again.
+	CancelJobs(ctx context.Context, filter JobFilter) ([]*entity.PredictionJob, error)
+	// CompleteJob marks a job done when its result is reported and returns
+	// the job, nil when its status expired. It returns false when the job was
+	// cancelled and its result must be discarded.
+	CompleteJob(ctx context.Context, jobID string) (*entity.PredictionJob, bool, error)
+	// TimeOutJob gives up on an active job. It returns false when the job is
+	// no longer active.
+	TimeOutJob(ctx context.Context, jobID string) (bool, error)
+	// RetryJob dispatches a timed-out job again as its next attempt
+	RetryJob(ctx context.Context, job *entity.PredictionJob) error
+	// FailJob stores the failure of a job that will not be retried
+	FailJob(ctx context.Context, job *entity.PredictionJob, reason string) error
+	// RecordJobEvent counts a job event per model and forwards it to the webhook
+	RecordJobEvent(ctx context.Context, event *entity.JobEvent)
+	// GetJobStats returns the job event counters per model
+	GetJobStats(ctx context.Context) (map[string]map[string]int64, error)
+	ClearCache(ctx context.Context, model string) (int64, error)
+}
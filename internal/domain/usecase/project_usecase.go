@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+)
+
+// ScoreSelector selects the scores of a model, optionally restricted to one
+// prompt version
+type ScoreSelector struct {
+	Model         string
+	PromptVersion string
+}
+
+// String formats the selector as model or model@prompt_version
+func (s ScoreSelector) String() string {
+	if s.PromptVersion == "" {
+		return s.Model
+	}
+	return s.Model + "@" + s.PromptVersion
+}
+
+// CompareOptions configures a significance test between two models or
+// prompt versions
+type CompareOptions struct {
+	A          ScoreSelector
+	B          ScoreSelector
+	Samples    int
+	Confidence float64
+	Seed       int64
+}
+
+// ProjectUseCase defines the interface for project business logic
+type ProjectUseCase interface {
+	CreateProject(ctx context.Context, project *entity.Project) error
+	GetProjectByID(ctx context.Context, id uuid.UUID) (*entity.Project, error)
+	GetAllProjects(ctx context.Context) ([]*entity.Project, error)
+	UpdateProject(ctx context.Context, project *entity.Project) error
+	DeleteProject(ctx context.Context, id uuid.UUID) error
+	GetLeaderboard(ctx context.Context, projectID uuid.UUID, filter repository.ImageFilter) ([]*entity.LeaderboardEntry, error)
+	GetConfusion(ctx context.Context, projectID uuid.UUID, model string, filter repository.ImageFilter) ([]*entity.ConfusionReport, error)
+	GetPrecisionRecall(ctx context.Context, projectID uuid.UUID, model string, filter repository.ImageFilter) ([]*entity.PrecisionRecallReport, error)
+	GetCalibration(ctx context.Context, projectID uuid.UUID, model string, filter repository.ImageFilter, bins int, maxECE float64) ([]*entity.CalibrationReport, error)
+	CompareModels(ctx context.Context, projectID uuid.UUID, filter repository.ImageFilter, opts CompareOptions) (*entity.ModelComparison, error)
+	// GetUncertainImages ranks the project's images without ground truth
+	// matching the filter by the uncertainty of the models, most uncertain
+	// first, and returns the first limit
+	GetUncertainImages(ctx context.Context, projectID uuid.UUID, filter repository.ImageFilter, limit int) ([]*entity.ImageUncertainty, error)
+	// Prelabel drafts the ground truth of the project's images without ground
+	// truth from its pre-labeling models
+	Prelabel(ctx context.Context, projectID uuid.UUID) (drafted, skipped int, err error)
+}
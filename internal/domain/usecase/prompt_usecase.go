@@ -0,0 +1,18 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// PromptUseCase defines the interface for prompt template business logic
+type PromptUseCase interface {
+	CreatePrompt(ctx context.Context, prompt *entity.PromptTemplate) error
+	GetPromptByID(ctx context.Context, id uuid.UUID) (*entity.PromptTemplate, error)
+	GetAllPrompts(ctx context.Context, model string) ([]*entity.PromptTemplate, error)
+	ActivatePrompt(ctx context.Context, id uuid.UUID) (*entity.PromptTemplate, error)
+	DeletePrompt(ctx context.Context, id uuid.UUID) error
+	ResolvePrompt(ctx context.Context, model, version string) (*entity.PromptTemplate, error)
+}
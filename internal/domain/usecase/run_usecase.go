@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+)
+
+// RunRequest describes a new evaluation run. The images are either listed
+// explicitly or selected by the filter when the run is created.
+type RunRequest struct {
+	ProjectID     *uuid.UUID
+	BatchID       *uuid.UUID
+	Name          string
+	Model         string
+	PromptVersion string
+	Parameters    map[string]any
+	Filter        repository.ImageFilter
+	ImageIDs      []uuid.UUID
+}
+
+// RunUseCase defines the interface for evaluation run business logic
+type RunUseCase interface {
+	CreateRun(ctx context.Context, req RunRequest) (*entity.EvaluationRun, error)
+	// GetRun returns a run with its progress and aggregated metrics
+	GetRun(ctx context.Context, id uuid.UUID) (*entity.EvaluationRun, error)
+	GetAllRuns(ctx context.Context, projectID *uuid.UUID) ([]*entity.EvaluationRun, error)
+	GetRunResults(ctx context.Context, id uuid.UUID, status string) ([]*entity.RunResult, error)
+	DeleteRun(ctx context.Context, id uuid.UUID) error
+	// CancelRun stops a run and cancels its queued and running jobs
+	CancelRun(ctx context.Context, id uuid.UUID) (*entity.EvaluationRun, error)
+	// CompareRuns compares two runs on the images both scored
+	CompareRuns(ctx context.Context, a, b uuid.UUID, samples int, confidence float64, seed int64) (*entity.RunComparison, error)
+}
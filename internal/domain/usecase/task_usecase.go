@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+)
+
+// AssignRequest creates annotation tasks for the images of a project listed
+// in ImageIDs or matching Filter. The images are dealt round-robin to the
+// Annotators, PerImage annotators each (default 1). Overlap restricts
+// PerImage to that share of the images, spread evenly, the others getting a
+// single annotator; 0 applies it to all images. Gold adds that many tasks on
+// gold images of the project to each annotator. Without annotators the tasks
+// go to the pool. Order is one of the AssignOrder values, empty meaning
+// AssignOrderCreated.
+type AssignRequest struct {
+	Annotators []string
+	PerImage   int
+	Overlap    float64
+	Gold       int
+	Order      string
+	Filter     repository.ImageFilter
+	ImageIDs   []uuid.UUID
+}
+
+// Task orders of AssignRequest. AssignOrderCreated serves the tasks oldest
+// first, AssignOrderUncertainty the images the models are most unsure about
+// first.
+const (
+	AssignOrderCreated     = "created"
+	AssignOrderUncertainty = "uncertainty"
+)
+
+// SessionReport is a work session on a task reported by the annotation
+// client: the active editing intervals and the number of elements created,
+// edited and deleted
+type SessionReport struct {
+	Annotator string
+	Intervals []entity.Interval
+	Created   int
+	Edited    int
+	Deleted   int
+}
+
+// TaskUseCase defines the interface for annotation task business logic
+type TaskUseCase interface {
+	// AssignTasks creates the tasks of a project and returns how many were
+	// created; images that already have a task for an annotator are skipped
+	AssignTasks(ctx context.Context, projectID uuid.UUID, req AssignRequest) (int64, error)
+	GetTask(ctx context.Context, id uuid.UUID) (*entity.AnnotationTask, error)
+	ListTasks(ctx context.Context, filter repository.TaskFilter) ([]*entity.AnnotationTask, error)
+	// AssignTask gives an open task to an assignee, or to the pool when empty
+	AssignTask(ctx context.Context, id uuid.UUID, assignee string) (*entity.AnnotationTask, error)
+	DeleteTask(ctx context.Context, id uuid.UUID) error
+	// NextTask claims the next task of an annotator, nil when none is left
+	NextTask(ctx context.Context, annotator string, projectID *uuid.UUID) (*entity.AnnotationTask, error)
+	// ClaimTask leases a task to an annotator; claiming again renews the lease
+	ClaimTask(ctx context.Context, id uuid.UUID, annotator string) (*entity.AnnotationTask, error)
+	// ReleaseTask gives a claimed task back
+	ReleaseTask(ctx context.Context, id uuid.UUID, annotator string) (*entity.AnnotationTask, error)
+	// SubmitTask stores the annotation of a claimed task
+	SubmitTask(ctx context.Context, id uuid.UUID, annotator string, annotation map[string]any) (*entity.AnnotationTask, error)
+	// ProposeGroundTruth submits ground truth of an image for review
+	ProposeGroundTruth(ctx context.Context, imageID uuid.UUID, annotator string, groundTruth map[string]any) (*entity.AnnotationTask, error)
+	// ApproveTask makes the annotation of a submitted task the ground truth
+	// of its image; a non-nil annotation is the reviewer's edit of it
+	ApproveTask(ctx context.Context, id uuid.UUID, reviewer, comment string, annotation map[string]any) (*entity.AnnotationTask, error)
+	// RejectTask sends a submitted task back to its annotator
+	RejectTask(ctx context.Context, id uuid.UUID, reviewer, comment string) (*entity.AnnotationTask, error)
+	GetTaskHistory(ctx context.Context, id uuid.UUID) ([]*entity.TaskEvent, error)
+	// GetAgreement measures the agreement of the annotators of a project on
+	// the images they annotated independently; images below minAgreement
+	// are flagged for adjudication
+	GetAgreement(ctx context.Context, projectID uuid.UUID, minAgreement float64) (*entity.AgreementReport, error)
+	// RecordSession stores a work session of the annotator holding a task
+	RecordSession(ctx context.Context, id uuid.UUID, report SessionReport) (*entity.AnnotationSession, error)
+	// GetProductivity reports the work of the annotators by annotator,
+	// project and day, flagging unusual time per task
+	GetProductivity(ctx context.Context, filter repository.ProductivityFilter) ([]*entity.ProductivityRow, error)
+	// GetAnnotatorAccuracy scores the annotators of a project on their gold
+	// tasks
+	GetAnnotatorAccuracy(ctx context.Context, projectID uuid.UUID) ([]*entity.AnnotatorAccuracy, error)
+}
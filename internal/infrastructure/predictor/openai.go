@@ -0,0 +1,191 @@
+package predictor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/label-platform-backend/pkg/worker"
+)
+
+// PromptVersion identifies the built-in prompt, used when the model has no
+// stored prompt
+const PromptVersion = "builtin-v1"
+
+// Prompt asks the model to return the UI elements of a screenshot as JSON
+const Prompt = `You are labeling UI design screenshots.
+Detect every UI element (button, input, link, text, image, checkbox, icon, ...) in the screenshot.
+Respond with a single JSON object and nothing else, in the form:
+{"elements": [{"type": "button", "text": "Submit", "bbox": {"x": 100, "y": 200, "width": 80, "height": 32}, "confidence": 0.95}]}
+Coordinates are in pixels, x/y being the top-left corner of the element.`
+
+// OpenAIConfig configures an OpenAI-compatible chat-completions endpoint
+type OpenAIConfig struct {
+	BaseURL     string
+	Model       string
+	APIKey      string
+	MaxTokens   int
+	Temperature float64
+	Timeout     time.Duration
+}
+
+// OpenAIPredictor implements worker.Predictor against any OpenAI-compatible
+// chat-completions API that accepts image inputs
+type OpenAIPredictor struct {
+	cfg    OpenAIConfig
+	client *http.Client
+}
+
+// NewOpenAIPredictor creates a new OpenAI-compatible predictor
+func NewOpenAIPredictor(cfg OpenAIConfig) (*OpenAIPredictor, error) {
+	if cfg.BaseURL == "" {
+		return nil, errors.New("base URL is required")
+	}
+	if cfg.Model == "" {
+		return nil, errors.New("model name is required")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 2 * time.Minute
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+
+	return &OpenAIPredictor{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+type chatContentPart struct {
+	Type     string        `json:"type"`
+	Text     string        `json:"text,omitempty"`
+	ImageURL *chatImageURL `json:"image_url,omitempty"`
+}
+
+type chatImageURL struct {
+	URL string `json:"url"`
+}
+
+type chatRequest struct {
+	Model          string            `json:"model"`
+	Messages       []chatMessage     `json:"messages"`
+	MaxTokens      int               `json:"max_tokens,omitempty"`
+	Temperature    float64           `json:"temperature"`
+	ResponseFormat map[string]string `json:"response_format,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Predict sends the screenshot and the prompt to the chat-completions endpoint
+// and parses the returned elements. When the answer is not valid element JSON
+// only the raw content is returned.
+func (p *OpenAIPredictor) Predict(ctx context.Context, req *worker.Request) (*worker.Result, error) {
+	prompt, promptVersion := req.Prompt, req.PromptVersion
+	if prompt == "" {
+		prompt, promptVersion = Prompt, PromptVersion
+	}
+
+	dataURL := fmt.Sprintf("data:%s;base64,%s", http.DetectContentType(req.Image), base64.StdEncoding.EncodeToString(req.Image))
+
+	body, err := json.Marshal(chatRequest{
+		Model: p.cfg.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: prompt},
+			{Role: "user", Content: []chatContentPart{
+				{Type: "text", Text: "Label the UI elements in this screenshot."},
+				{Type: "image_url", ImageURL: &chatImageURL{URL: dataURL}},
+			}},
+		},
+		MaxTokens:      intParameter(req.Parameters, "max_tokens", p.cfg.MaxTokens),
+		Temperature:    floatParameter(req.Parameters, "temperature", p.cfg.Temperature),
+		ResponseFormat: map[string]string{"type": "json_object"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.cfg.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.cfg.APIKey)
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("chat completion request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var completion chatResponse
+	if err := json.Unmarshal(respBody, &completion); err != nil {
+		return nil, fmt.Errorf("invalid chat completion response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if completion.Error != nil {
+			return nil, fmt.Errorf("chat completion returned %d: %s", resp.StatusCode, completion.Error.Message)
+		}
+		return nil, fmt.Errorf("chat completion returned %d", resp.StatusCode)
+	}
+	if len(completion.Choices) == 0 {
+		return nil, errors.New("chat completion returned no choices")
+	}
+
+	content := completion.Choices[0].Message.Content
+	result := &worker.Result{Raw: content, PromptVersion: promptVersion}
+	var parsed struct {
+		Elements []worker.Element `json:"elements"`
+	}
+	if err := json.Unmarshal([]byte(content), &parsed); err == nil {
+		result.Elements = parsed.Elements
+	}
+	return result, nil
+}
+
+// floatParameter returns the numeric parameter name, or def when it is not set
+func floatParameter(params map[string]any, name string, def float64) float64 {
+	switch v := params[name].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+	return def
+}
+
+func intParameter(params map[string]any, name string, def int) int {
+	switch v := params[name].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return def
+}
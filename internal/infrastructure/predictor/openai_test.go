@@ -0,0 +1,123 @@
+package predictor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/label-platform-backend/pkg/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStubServer(t *testing.T, status int, content string, check func(r *http.Request, body chatRequest)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body chatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if check != nil {
+			check(r, body)
+		}
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"message": content}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{"message": map[string]any{"role": "assistant", "content": content}}},
+		})
+	}))
+}
+
+func TestOpenAIPredictor_Predict(t *testing.T) {
+	content := `{"elements":[{"type":"button","text":"Submit","bbox":{"x":100,"y":200,"width":80,"height":32},"confidence":0.95}]}`
+	srv := newStubServer(t, http.StatusOK, content, func(r *http.Request, body chatRequest) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "gpt-4o", body.Model)
+		require.Len(t, body.Messages, 2)
+		assert.Equal(t, Prompt, body.Messages[0].Content)
+
+		parts, _ := json.Marshal(body.Messages[1].Content)
+		assert.Contains(t, string(parts), "data:image/png;base64,")
+	})
+	defer srv.Close()
+
+	p, err := NewOpenAIPredictor(OpenAIConfig{BaseURL: srv.URL + "/v1/", Model: "gpt-4o", APIKey: "secret"})
+	require.NoError(t, err)
+
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+	result, err := p.Predict(context.Background(), &worker.Request{ImageID: "img", Image: png})
+	require.NoError(t, err)
+	assert.Equal(t, content, result.Raw)
+	assert.Equal(t, PromptVersion, result.PromptVersion)
+	assert.Equal(t, []worker.Element{{
+		Type:       "button",
+		Text:       "Submit",
+		BBox:       worker.BBox{X: 100, Y: 200, Width: 80, Height: 32},
+		Confidence: 0.95,
+	}}, result.Elements)
+}
+
+func TestOpenAIPredictor_PredictUsesRequestedPrompt(t *testing.T) {
+	srv := newStubServer(t, http.StatusOK, `{"elements":[]}`, func(r *http.Request, body chatRequest) {
+		assert.Equal(t, "Find the buttons.", body.Messages[0].Content)
+	})
+	defer srv.Close()
+
+	p, err := NewOpenAIPredictor(OpenAIConfig{BaseURL: srv.URL, Model: "m"})
+	require.NoError(t, err)
+
+	result, err := p.Predict(context.Background(), &worker.Request{Image: []byte("x"), Prompt: "Find the buttons.", PromptVersion: "v2"})
+	require.NoError(t, err)
+	assert.Equal(t, "v2", result.PromptVersion)
+	assert.Empty(t, result.Elements)
+}
+
+func TestOpenAIPredictor_PredictAppliesParameters(t *testing.T) {
+	srv := newStubServer(t, http.StatusOK, `{"elements":[]}`, func(r *http.Request, body chatRequest) {
+		assert.Equal(t, 0.7, body.Temperature)
+		assert.Equal(t, 1024, body.MaxTokens)
+	})
+	defer srv.Close()
+
+	p, err := NewOpenAIPredictor(OpenAIConfig{BaseURL: srv.URL, Model: "m", MaxTokens: 4096})
+	require.NoError(t, err)
+
+	params := map[string]any{"temperature": 0.7, "max_tokens": float64(1024)}
+	_, err = p.Predict(context.Background(), &worker.Request{Image: []byte("x"), Parameters: params})
+	require.NoError(t, err)
+}
+
+func TestOpenAIPredictor_PredictKeepsUnparsableContent(t *testing.T) {
+	srv := newStubServer(t, http.StatusOK, "Sorry, I cannot help with that.", nil)
+	defer srv.Close()
+
+	p, err := NewOpenAIPredictor(OpenAIConfig{BaseURL: srv.URL, Model: "m"})
+	require.NoError(t, err)
+
+	result, err := p.Predict(context.Background(), &worker.Request{Image: []byte("x")})
+	require.NoError(t, err)
+	assert.Equal(t, "Sorry, I cannot help with that.", result.Raw)
+	assert.Nil(t, result.Elements)
+}
+
+func TestOpenAIPredictor_PredictAPIError(t *testing.T) {
+	srv := newStubServer(t, http.StatusUnauthorized, "invalid api key", nil)
+	defer srv.Close()
+
+	p, err := NewOpenAIPredictor(OpenAIConfig{BaseURL: srv.URL, Model: "m"})
+	require.NoError(t, err)
+
+	_, err = p.Predict(context.Background(), &worker.Request{Image: []byte("x")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid api key")
+}
+
+func TestNewOpenAIPredictor_RequiresConfig(t *testing.T) {
+	_, err := NewOpenAIPredictor(OpenAIConfig{Model: "m"})
+	assert.Error(t, err)
+	_, err = NewOpenAIPredictor(OpenAIConfig{BaseURL: "http://localhost"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,36 @@
+// Package predictor contains the in-process model adapters used for
+// registered models, as opposed to external queue workers.
+package predictor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/pkg/worker"
+)
+
+// New builds the predictor configured for a registered model. The API key is
+// read from the environment variable named by the model, if any, so that
+// rotating it does not require updating the model.
+func New(model *entity.Model) (worker.Predictor, error) {
+	apiKey := model.APIKey
+	if model.APIKeyEnv != "" {
+		if apiKey = os.Getenv(model.APIKeyEnv); apiKey == "" {
+			return nil, fmt.Errorf("environment variable %s holding the API key is not set", model.APIKeyEnv)
+		}
+	}
+
+	switch model.Provider {
+	case entity.ProviderOpenAI:
+		return NewOpenAIPredictor(OpenAIConfig{
+			BaseURL:     model.BaseURL,
+			Model:       model.ModelName,
+			APIKey:      apiKey,
+			MaxTokens:   model.MaxTokens,
+			Temperature: model.Temperature,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported provider %q", model.Provider)
+	}
+}
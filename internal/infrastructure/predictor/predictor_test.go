@@ -0,0 +1,29 @@
+package predictor
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/pkg/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_APIKeyEnv(t *testing.T) {
+	srv := newStubServer(t, http.StatusOK, `{"elements":[]}`, func(r *http.Request, body chatRequest) {
+		assert.Equal(t, "Bearer from-env", r.Header.Get("Authorization"))
+	})
+	defer srv.Close()
+
+	model := &entity.Model{Provider: entity.ProviderOpenAI, BaseURL: srv.URL, ModelName: "gpt-4o", APIKeyEnv: "TEST_PREDICTOR_API_KEY"}
+	_, err := New(model)
+	assert.ErrorContains(t, err, "TEST_PREDICTOR_API_KEY", "unset variable")
+
+	t.Setenv("TEST_PREDICTOR_API_KEY", "from-env")
+	p, err := New(model)
+	require.NoError(t, err)
+	_, err = p.Predict(context.Background(), &worker.Request{ImageID: "img", Image: []byte("\x89PNG\r\n\x1a\n0000")})
+	require.NoError(t, err)
+}
@@ -0,0 +1,98 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// cacheStatsPrefix is the prefix of the per-model prediction cache counters
+const cacheStatsPrefix = "predict-cache:stats:"
+
+// cacheStatsModelsKey is the set of models with prediction cache counters
+const cacheStatsModelsKey = "predict-cache:stats-models"
+
+// Prediction cache outcomes counted per model
+const (
+	CacheHit    = "hits"
+	CacheMiss   = "misses"
+	CacheBypass = "bypassed"
+)
+
+// IncrCacheStat increments a prediction cache counter of a model
+func IncrCacheStat(ctx context.Context, model, outcome string) error {
+	return incrModelCounter(ctx, cacheStatsModelsKey, cacheStatsPrefix, model, outcome)
+}
+
+// GetCacheStats returns the prediction cache counters of every model
+func GetCacheStats(ctx context.Context) (map[string]map[string]int64, error) {
+	return getModelCounters(ctx, cacheStatsModelsKey, cacheStatsPrefix, CacheHit, CacheMiss, CacheBypass)
+}
+
+// ResetCacheStats clears the prediction cache counters of a model, or of all
+// models when model is empty
+func ResetCacheStats(ctx context.Context, model string) error {
+	if model != "" {
+		_, err := RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, cacheStatsPrefix+model)
+			pipe.SRem(ctx, cacheStatsModelsKey, model)
+			return nil
+		})
+		return err
+	}
+	models, err := RedisClient.SMembers(ctx, cacheStatsModelsKey).Result()
+	if err != nil {
+		return err
+	}
+	keys := []string{cacheStatsModelsKey}
+	for _, m := range models {
+		keys = append(keys, cacheStatsPrefix+m)
+	}
+	return RedisClient.Del(ctx, keys...).Err()
+}
+
+// incrModelCounter increments a field of the counters hash of a model and
+// records the model in the set listing the hashes, so they are read without
+// scanning the keyspace
+func incrModelCounter(ctx context.Context, modelsKey, prefix, model, field string) error {
+	_, err := RedisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, prefix+model, field, 1)
+		pipe.SAdd(ctx, modelsKey, model)
+		return nil
+	})
+	return err
+}
+
+// getModelCounters reads the counters hashes of the models in the set
+// modelsKey. The given fields default to zero.
+func getModelCounters(ctx context.Context, modelsKey, prefix string, fields ...string) (map[string]map[string]int64, error) {
+	models, err := RedisClient.SMembers(ctx, modelsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	cmds := make([]*redis.MapStringStringCmd, len(models))
+	_, err = RedisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, model := range models {
+			cmds[i] = pipe.HGetAll(ctx, prefix+model)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]map[string]int64, len(models))
+	for i, cmd := range cmds {
+		counters := make(map[string]int64, len(fields))
+		for _, field := range fields {
+			counters[field] = 0
+		}
+		for field, v := range cmd.Val() {
+			counters[field], _ = strconv.ParseInt(v, 10, 64)
+		}
+		stats[models[i]] = counters
+	}
+	return stats, nil
+}
@@ -0,0 +1,23 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// jobStatsPrefix is the prefix of the per-model prediction job counters
+const jobStatsPrefix = "predict-jobs:stats:"
+
+// jobStatsModelsKey is the set of models with prediction job counters
+const jobStatsModelsKey = "predict-jobs:stats-models"
+
+// IncrJobStat increments the counter of a job event of a model
+func IncrJobStat(ctx context.Context, model, event string) error {
+	return incrModelCounter(ctx, jobStatsModelsKey, jobStatsPrefix, model, event)
+}
+
+// GetJobStats returns the job event counters of every model
+func GetJobStats(ctx context.Context) (map[string]map[string]int64, error) {
+	return getModelCounters(ctx, jobStatsModelsKey, jobStatsPrefix, entity.JobEventTimedOut, entity.JobEventRetried, entity.JobEventFailed)
+}
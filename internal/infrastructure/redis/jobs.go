@@ -0,0 +1,158 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/pkg/worker"
+	"github.com/redis/go-redis/v9"
+)
+
+// jobRetention is how long the status of a finished or cancelled job is kept
+const jobRetention = 24 * time.Hour
+
+// stopJob sets a queued or running job cancelled or timed out and removes it
+// from the active jobs
+var stopJob = redis.NewScript(`
+local status = redis.call('HGET', KEYS[1], 'status')
+if status ~= 'queued' and status ~= 'running' then return 0 end
+redis.call('HSET', KEYS[1], 'status', ARGV[3])
+redis.call('ZREM', KEYS[2], ARGV[1])
+redis.call('EXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
+// finishJob marks a job done unless it was cancelled or timed out, returning
+// 0 in that case
+var finishJob = redis.NewScript(`
+local status = redis.call('HGET', KEYS[1], 'status')
+if status == 'cancelled' or status == 'timed_out' then return 0 end
+if status then
+	redis.call('HSET', KEYS[1], 'status', 'done')
+	redis.call('EXPIRE', KEYS[1], ARGV[2])
+end
+redis.call('ZREM', KEYS[2], ARGV[1])
+return 1
+`)
+
+// EnqueueJob records a job as queued and pushes its payload onto the list of
+// its priority. A job without queue only records the job, for in-process
+// models.
+func EnqueueJob(ctx context.Context, job *entity.PredictionJob, queue string, payload []byte) error {
+	if job.QueuedAt.IsZero() {
+		job.QueuedAt = time.Now()
+	}
+	_, err := RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, worker.JobKey(job.ID), jobFields(job))
+		pipe.ZAdd(ctx, worker.ActiveJobsKey, redis.Z{Score: float64(job.QueuedAt.UnixMilli()), Member: job.ID})
+		if queue != "" {
+			pipe.RPush(ctx, worker.QueueName(queue, job.Priority), payload)
+		}
+		return nil
+	})
+	return err
+}
+
+// ActiveJobs returns the queued and running jobs, oldest first
+func ActiveJobs(ctx context.Context) ([]*entity.PredictionJob, error) {
+	ids, err := RedisClient.ZRange(ctx, worker.ActiveJobsKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	cmds := make([]*redis.MapStringStringCmd, len(ids))
+	_, err = RedisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = pipe.HGetAll(ctx, worker.JobKey(id))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*entity.PredictionJob, 0, len(ids))
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		if len(fields) == 0 {
+			// The status expired, drop the dangling entry
+			RedisClient.ZRem(ctx, worker.ActiveJobsKey, ids[i])
+			continue
+		}
+		jobs = append(jobs, parseJob(ids[i], fields))
+	}
+	return jobs, nil
+}
+
+// CancelJob cancels a queued or running job. It returns false when the job
+// is not active.
+func CancelJob(ctx context.Context, id string) (bool, error) {
+	return stop(ctx, id, worker.JobCancelled)
+}
+
+// TimeOutJob gives up on a queued or running job. It returns false when the
+// job is not active.
+func TimeOutJob(ctx context.Context, id string) (bool, error) {
+	return stop(ctx, id, worker.JobTimedOut)
+}
+
+func stop(ctx context.Context, id, status string) (bool, error) {
+	stopped, err := stopJob.Run(ctx, RedisClient, []string{worker.JobKey(id), worker.ActiveJobsKey}, id, int(jobRetention.Seconds()), status).Int()
+	return stopped == 1, err
+}
+
+// FinishJob marks a job done. It returns false when the job was cancelled or
+// timed out, in which case its result must be discarded.
+func FinishJob(ctx context.Context, id string) (bool, error) {
+	finished, err := finishJob.Run(ctx, RedisClient, []string{worker.JobKey(id), worker.ActiveJobsKey}, id, int(jobRetention.Seconds())).Int()
+	return finished == 1, err
+}
+
+// GetJob returns a job, or nil when it is unknown or its status expired
+func GetJob(ctx context.Context, id string) (*entity.PredictionJob, error) {
+	fields, err := RedisClient.HGetAll(ctx, worker.JobKey(id)).Result()
+	if err != nil || len(fields) == 0 {
+		return nil, err
+	}
+	return parseJob(id, fields), nil
+}
+
+func jobFields(job *entity.PredictionJob) map[string]any {
+	fields := map[string]any{
+		"image_id":       job.ImageID,
+		"model":          job.Model,
+		"run_id":         job.RunID,
+		"prompt_version": job.PromptVersion,
+		"priority":       job.Priority,
+		"status":         job.Status,
+		"attempt":        job.Attempt,
+		"queued_at":      strconv.FormatInt(job.QueuedAt.UnixMilli(), 10),
+	}
+	if job.StartedAt != nil {
+		fields["started_at"] = strconv.FormatInt(job.StartedAt.UnixMilli(), 10)
+	}
+	return fields
+}
+
+func parseJob(id string, fields map[string]string) *entity.PredictionJob {
+	job := &entity.PredictionJob{
+		ID:            id,
+		ImageID:       fields["image_id"],
+		Model:         fields["model"],
+		RunID:         fields["run_id"],
+		PromptVersion: fields["prompt_version"],
+		Priority:      fields["priority"],
+		Status:        fields["status"],
+	}
+	job.Attempt, _ = strconv.Atoi(fields["attempt"])
+	if ms, err := strconv.ParseInt(fields["queued_at"], 10, 64); err == nil {
+		job.QueuedAt = time.UnixMilli(ms)
+	}
+	if ms, err := strconv.ParseInt(fields["started_at"], 10, 64); err == nil {
+		startedAt := time.UnixMilli(ms)
+		job.StartedAt = &startedAt
+	}
+	return job
+}
@@ -15,6 +15,13 @@ var (
 	QueueResult = "label-platform-queue-result"
 )
 
+// ModelQueues maps the names of the queue-backed models to their queue
+var ModelQueues = map[string]string{
+	"gpt":    QueueGPT,
+	"claude": QueueClaude,
+	"gemini": QueueGemini,
+}
+
 // RedisClient wraps the go-redis client
 var RedisClient *redis.Client
 
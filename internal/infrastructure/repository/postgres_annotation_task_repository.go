@@ -0,0 +1,343 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// claimableTask matches the tasks t an annotator may claim: open or
+// rejected from the annotator, assigned to nobody or to the annotator, not
+// leased by anyone else, and on an image the annotator has no other task for
+const claimableTask = `(t.status IN (@todo, @in_progress) OR (t.status = @rejected AND @annotator IN (t.assignee, t.claimed_by)))
+	AND t.assignee IN ('', @annotator)
+	AND (t.claimed_by IN ('', @annotator) OR t.lease_expires_at < @now)
+	AND NOT EXISTS (
+		SELECT 1 FROM annotation_tasks o
+		WHERE o.image_id = t.image_id AND o.id <> t.id AND @annotator IN (o.assignee, o.claimed_by))`
+
+// PostgresAnnotationTaskRepository implements the AnnotationTaskRepository interface
+type PostgresAnnotationTaskRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresAnnotationTaskRepository creates a new PostgreSQL annotation task repository
+func NewPostgresAnnotationTaskRepository(db *gorm.DB) repository.AnnotationTaskRepository {
+	return &PostgresAnnotationTaskRepository{db: db}
+}
+
+// Create saves tasks in batches, skipping existing image and assignee pairs
+func (r *PostgresAnnotationTaskRepository) Create(ctx context.Context, tasks []*entity.AnnotationTask) (int64, error) {
+	if len(tasks) == 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "image_id"}, {Name: "assignee"}}, DoNothing: true}).
+		CreateInBatches(tasks, 500)
+	return result.RowsAffected, result.Error
+}
+
+// GetByID retrieves a task by its ID
+func (r *PostgresAnnotationTaskRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.AnnotationTask, error) {
+	var task entity.AnnotationTask
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&task).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// List retrieves the tasks matching the filter, oldest first
+func (r *PostgresAnnotationTaskRepository) List(ctx context.Context, filter repository.TaskFilter) ([]*entity.AnnotationTask, error) {
+	query := r.db.WithContext(ctx).Order("created_at, id")
+	if filter.ProjectID != nil {
+		query = query.Where("project_id = ?", *filter.ProjectID)
+	}
+	if filter.ImageID != nil {
+		query = query.Where("image_id = ?", *filter.ImageID)
+	}
+	if filter.Assignee != nil {
+		query = query.Where("assignee = ?", *filter.Assignee)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Gold != nil {
+		query = query.Where("gold = ?", *filter.Gold)
+	}
+
+	var tasks []*entity.AnnotationTask
+	if err := query.Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// Delete removes a task, its history and its sessions
+func (r *PostgresAnnotationTaskRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("task_id = ?", id).Delete(&entity.TaskEvent{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("task_id = ?", id).Delete(&entity.AnnotationSession{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&entity.AnnotationTask{}).Error
+	})
+}
+
+// DeleteByImage removes the tasks of an image, their history and their
+// sessions
+func (r *PostgresAnnotationTaskRepository) DeleteByImage(ctx context.Context, imageID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tasks := tx.Model(&entity.AnnotationTask{}).Select("id").Where("image_id = ?", imageID)
+		if err := tx.Where("task_id IN (?)", tasks).Delete(&entity.TaskEvent{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("task_id IN (?)", tasks).Delete(&entity.AnnotationSession{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("image_id = ?", imageID).Delete(&entity.AnnotationTask{}).Error
+	})
+}
+
+// Assign gives an open task to an assignee and puts it back to todo
+func (r *PostgresAnnotationTaskRepository) Assign(ctx context.Context, id uuid.UUID, assignee string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entity.AnnotationTask{}).
+		Where("id = ? AND status IN ?", id, []string{entity.TaskStatusTodo, entity.TaskStatusInProgress}).
+		Updates(map[string]any{
+			"assignee":         assignee,
+			"status":           entity.TaskStatusTodo,
+			"claimed_by":       "",
+			"lease_expires_at": nil,
+			"updated_at":       time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Claim leases a task to an annotator if the annotator may claim it. The
+// check and the update are a single statement, so concurrent claims of the
+// same task cannot both succeed.
+func (r *PostgresAnnotationTaskRepository) Claim(ctx context.Context, id uuid.UUID, annotator string, lease time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Exec(`UPDATE annotation_tasks t
+		SET status = @in_progress, claimed_by = @annotator, lease_expires_at = @lease, updated_at = @now,
+			claimed_at = CASE WHEN t.status = @in_progress AND t.claimed_by = @annotator THEN COALESCE(t.claimed_at, @now) ELSE @now END
+		WHERE t.id = @id AND `+claimableTask,
+		claimArgs(annotator, lease, sql.Named("id", id))...)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ClaimNext leases the first claimable task. Tasks locked by concurrent
+// claims are skipped, so two annotators never get the same task.
+func (r *PostgresAnnotationTaskRepository) ClaimNext(ctx context.Context, annotator string, projectID *uuid.UUID, lease time.Time) (*repository.ClaimedTask, error) {
+	inProject := ""
+	args := claimArgs(annotator, lease)
+	if projectID != nil {
+		inProject = " AND t.project_id = @project"
+		args = append(args, sql.Named("project", *projectID))
+	}
+
+	var claimed []struct {
+		ID        uuid.UUID
+		Status    string
+		ClaimedBy string
+	}
+	err := r.db.WithContext(ctx).Raw(`WITH picked AS (
+			SELECT t.id, t.status, t.claimed_by FROM annotation_tasks t
+			WHERE `+claimableTask+inProject+`
+			ORDER BY t.claimed_by = @annotator DESC, t.assignee = @annotator DESC, t.priority DESC, t.created_at, t.id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED)
+		UPDATE annotation_tasks a
+		SET status = @in_progress, claimed_by = @annotator, lease_expires_at = @lease, updated_at = @now,
+			claimed_at = CASE WHEN a.status = @in_progress AND a.claimed_by = @annotator THEN COALESCE(a.claimed_at, @now) ELSE @now END
+		FROM picked WHERE a.id = picked.id
+		RETURNING a.id, picked.status, picked.claimed_by`, args...).
+		Scan(&claimed).Error
+	if err != nil || len(claimed) == 0 {
+		return nil, err
+	}
+	task, err := r.GetByID(ctx, claimed[0].ID)
+	if err != nil {
+		return nil, err
+	}
+	return &repository.ClaimedTask{Task: task, PreviousStatus: claimed[0].Status, PreviousClaimant: claimed[0].ClaimedBy}, nil
+}
+
+// Release puts a task claimed by the annotator back to todo
+func (r *PostgresAnnotationTaskRepository) Release(ctx context.Context, id uuid.UUID, annotator string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entity.AnnotationTask{}).
+		Where("id = ? AND status = ? AND claimed_by = ?", id, entity.TaskStatusInProgress, annotator).
+		Updates(map[string]any{
+			"status":           entity.TaskStatusTodo,
+			"claimed_by":       "",
+			"lease_expires_at": nil,
+			"updated_at":       time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Submit stores the annotation of a task claimed by the annotator and adds
+// the time since the claim to its handle time. A task whose lease expired
+// can still be submitted until someone else claims it.
+func (r *PostgresAnnotationTaskRepository) Submit(ctx context.Context, id uuid.UUID, annotator string, annotation datatypes.JSON) (bool, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).Model(&entity.AnnotationTask{}).
+		Where("id = ? AND status = ? AND claimed_by = ?", id, entity.TaskStatusInProgress, annotator).
+		Updates(map[string]any{
+			"status":           entity.TaskStatusSubmitted,
+			"annotation":       annotation,
+			"submitted_at":     now,
+			"handle_seconds":   gorm.Expr("handle_seconds + COALESCE(EXTRACT(EPOCH FROM (? - claimed_at)), 0)", now),
+			"lease_expires_at": nil,
+			"updated_at":       now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Propose upserts a submitted task for the image and assignee
+func (r *PostgresAnnotationTaskRepository) Propose(ctx context.Context, task *entity.AnnotationTask) error {
+	now := time.Now()
+	task.Status = entity.TaskStatusSubmitted
+	task.SubmittedAt = &now
+	task.LeaseExpiresAt = nil
+	task.UpdatedAt = now
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "image_id"}, {Name: "assignee"}},
+			DoUpdates: clause.AssignmentColumns([]string{"status", "claimed_by", "annotation", "submitted_at", "lease_expires_at", "reviewer", "review_comment", "reviewed_at", "updated_at"}),
+		}).
+		Create(task).Error
+}
+
+// Review sets a submitted task approved or rejected
+func (r *PostgresAnnotationTaskRepository) Review(ctx context.Context, id uuid.UUID, status, reviewer, comment string, annotation datatypes.JSON) (bool, error) {
+	now := time.Now()
+	fields := map[string]any{
+		"status":         status,
+		"reviewer":       reviewer,
+		"review_comment": comment,
+		"reviewed_at":    now,
+		"updated_at":     now,
+	}
+	if annotation != nil {
+		fields["annotation"] = annotation
+	}
+	result := r.db.WithContext(ctx).Model(&entity.AnnotationTask{}).
+		Where("id = ? AND status = ?", id, entity.TaskStatusSubmitted).
+		Updates(fields)
+	return result.RowsAffected > 0, result.Error
+}
+
+// SetGoldScore stores the score of a gold task submission
+func (r *PostgresAnnotationTaskRepository) SetGoldScore(ctx context.Context, id uuid.UUID, score datatypes.JSON) error {
+	return r.db.WithContext(ctx).Model(&entity.AnnotationTask{}).
+		Where("id = ?", id).
+		Update("gold_score", score).Error
+}
+
+// AggregateGoldScores sums the scored gold tasks of a project by annotator
+func (r *PostgresAnnotationTaskRepository) AggregateGoldScores(ctx context.Context, projectID uuid.UUID) ([]*entity.AnnotatorScoreAggregate, error) {
+	var aggregates []*entity.AnnotatorScoreAggregate
+	err := r.db.WithContext(ctx).Table("annotation_tasks AS t").
+		Select("t.claimed_by AS annotator, COUNT(*) AS images, "+scoreAggregateColumns("t.gold_score")).
+		Where("t.project_id = ? AND t.gold AND t.gold_score IS NOT NULL", projectID).
+		Group("t.claimed_by").
+		Order("t.claimed_by").
+		Scan(&aggregates).Error
+	if err != nil {
+		return nil, err
+	}
+	return aggregates, nil
+}
+
+// AddSession saves a work session reported by the client
+func (r *PostgresAnnotationTaskRepository) AddSession(ctx context.Context, session *entity.AnnotationSession) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+// ProductivityReport sums the submitted tasks, by the day they were last
+// submitted, and the sessions, by the day they started. Days are UTC.
+func (r *PostgresAnnotationTaskRepository) ProductivityReport(ctx context.Context, filter repository.ProductivityFilter) ([]*entity.ProductivityRow, error) {
+	tasks, sessions, args := productivityConditions(filter)
+
+	var rows []*entity.ProductivityRow
+	err := r.db.WithContext(ctx).Raw(`SELECT annotator, project_id, day,
+			SUM(submitted) AS submitted, SUM(handle_seconds) AS handle_seconds,
+			SUM(sessions) AS sessions, SUM(active_seconds) AS active_seconds,
+			SUM(created) AS created, SUM(edited) AS edited, SUM(deleted) AS deleted
+		FROM (
+			SELECT t.claimed_by AS annotator, t.project_id, to_char(t.submitted_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day,
+				1 AS submitted, t.handle_seconds, 0 AS sessions, 0 AS active_seconds, 0 AS created, 0 AS edited, 0 AS deleted
+			FROM annotation_tasks t WHERE `+tasks+`
+			UNION ALL
+			SELECT s.annotator, s.project_id, to_char(s.started_at AT TIME ZONE 'UTC', 'YYYY-MM-DD'),
+				0, 0, 1, s.active_seconds, s.created, s.edited, s.deleted
+			FROM annotation_sessions s WHERE `+sessions+`
+		) w
+		GROUP BY annotator, project_id, day
+		ORDER BY day, annotator, project_id`, args...).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// productivityConditions returns the conditions on the submitted tasks and
+// on the sessions selected by a productivity filter
+func productivityConditions(filter repository.ProductivityFilter) (tasks, sessions string, args []any) {
+	tasks, sessions = "t.submitted_at IS NOT NULL", "TRUE"
+	if filter.ProjectID != nil {
+		tasks += " AND t.project_id = @project"
+		sessions += " AND s.project_id = @project"
+		args = append(args, sql.Named("project", *filter.ProjectID))
+	}
+	if filter.Annotator != "" {
+		tasks += " AND t.claimed_by = @annotator"
+		sessions += " AND s.annotator = @annotator"
+		args = append(args, sql.Named("annotator", filter.Annotator))
+	}
+	if filter.From != nil {
+		tasks += " AND t.submitted_at >= @from"
+		sessions += " AND s.started_at >= @from"
+		args = append(args, sql.Named("from", *filter.From))
+	}
+	if filter.To != nil {
+		tasks += " AND t.submitted_at < @to"
+		sessions += " AND s.started_at < @to"
+		args = append(args, sql.Named("to", *filter.To))
+	}
+	return tasks, sessions, args
+}
+
+// AddEvent appends an event to the history of a task
+func (r *PostgresAnnotationTaskRepository) AddEvent(ctx context.Context, event *entity.TaskEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// GetEvents returns the history of a task, oldest first
+func (r *PostgresAnnotationTaskRepository) GetEvents(ctx context.Context, taskID uuid.UUID) ([]*entity.TaskEvent, error) {
+	var events []*entity.TaskEvent
+	if err := r.db.WithContext(ctx).Where("task_id = ?", taskID).Order("created_at, id").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func claimArgs(annotator string, lease time.Time, extra ...any) []any {
+	return append([]any{
+		sql.Named("todo", entity.TaskStatusTodo),
+		sql.Named("in_progress", entity.TaskStatusInProgress),
+		sql.Named("rejected", entity.TaskStatusRejected),
+		sql.Named("annotator", annotator),
+		sql.Named("lease", lease),
+		sql.Named("now", time.Now()),
+	}, extra...)
+}
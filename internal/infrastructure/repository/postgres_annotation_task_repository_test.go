@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProductivityConditions(t *testing.T) {
+	tasks, sessions, args := productivityConditions(repository.ProductivityFilter{})
+	assert.Equal(t, "t.submitted_at IS NOT NULL", tasks)
+	assert.Equal(t, "TRUE", sessions)
+	assert.Empty(t, args)
+
+	project := uuid.New()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	tasks, sessions, args = productivityConditions(repository.ProductivityFilter{ProjectID: &project, Annotator: "ann", From: &from, To: &to})
+	assert.Equal(t, "t.submitted_at IS NOT NULL AND t.project_id = @project AND t.claimed_by = @annotator AND t.submitted_at >= @from AND t.submitted_at < @to", tasks)
+	// Sessions count on the day they started
+	assert.Equal(t, "TRUE AND s.project_id = @project AND s.annotator = @annotator AND s.started_at >= @from AND s.started_at < @to", sessions)
+	assert.Equal(t, []any{
+		sql.Named("project", project),
+		sql.Named("annotator", "ann"),
+		sql.Named("from", from),
+		sql.Named("to", to),
+	}, args)
+}
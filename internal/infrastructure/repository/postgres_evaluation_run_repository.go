@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// runResultBatchSize bounds the rows inserted per statement when creating a run
+const runResultBatchSize = 500
+
+// PostgresEvaluationRunRepository implements the EvaluationRunRepository interface
+type PostgresEvaluationRunRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresEvaluationRunRepository creates a new PostgreSQL evaluation run repository
+func NewPostgresEvaluationRunRepository(db *gorm.DB) repository.EvaluationRunRepository {
+	return &PostgresEvaluationRunRepository{db: db}
+}
+
+// Create saves a run and a pending result for every image of its snapshot
+func (r *PostgresEvaluationRunRepository) Create(ctx context.Context, run *entity.EvaluationRun, imageIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(run).Error; err != nil {
+			return err
+		}
+		if len(imageIDs) == 0 {
+			return nil
+		}
+		results := make([]*entity.RunResult, len(imageIDs))
+		for i, id := range imageIDs {
+			results[i] = &entity.RunResult{RunID: run.ID, ImageID: id, Status: entity.RunResultPending, UpdatedAt: run.CreatedAt}
+		}
+		return tx.CreateInBatches(results, runResultBatchSize).Error
+	})
+}
+
+// GetByID retrieves a run by its ID
+func (r *PostgresEvaluationRunRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.EvaluationRun, error) {
+	var run entity.EvaluationRun
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&run).Error
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// GetAll retrieves all runs, newest first, optionally of a single project
+func (r *PostgresEvaluationRunRepository) GetAll(ctx context.Context, projectID *uuid.UUID) ([]*entity.EvaluationRun, error) {
+	var runs []*entity.EvaluationRun
+	query := r.db.WithContext(ctx).Order("created_at DESC")
+	if projectID != nil {
+		query = query.Where("project_id = ?", *projectID)
+	}
+	if err := query.Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// GetByBatch retrieves the runs of a prediction batch ordered by model
+func (r *PostgresEvaluationRunRepository) GetByBatch(ctx context.Context, batchID uuid.UUID) ([]*entity.EvaluationRun, error) {
+	var runs []*entity.EvaluationRun
+	if err := r.db.WithContext(ctx).Where("batch_id = ?", batchID).Order("model").Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// UpdateStatus sets the status of a run
+func (r *PostgresEvaluationRunRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
+	return r.db.WithContext(ctx).Model(&entity.EvaluationRun{}).Where("id = ?", id).Updates(map[string]any{
+		"status":     status,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// Delete removes a run and its results
+func (r *PostgresEvaluationRunRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("run_id = ?", id).Delete(&entity.RunResult{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&entity.EvaluationRun{}).Error
+	})
+}
+
+// SetPrediction stores the prediction and score of an image of a run
+func (r *PostgresEvaluationRunRepository) SetPrediction(ctx context.Context, runID, imageID uuid.UUID, status string, prediction, score datatypes.JSON) error {
+	result := r.db.WithContext(ctx).Model(&entity.RunResult{}).Where("run_id = ? AND image_id = ?", runID, imageID).Updates(map[string]any{
+		"status":     status,
+		"prediction": prediction,
+		"score":      score,
+		"updated_at": time.Now(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetScore replaces the score of an image of a run
+func (r *PostgresEvaluationRunRepository) SetScore(ctx context.Context, runID, imageID uuid.UUID, score datatypes.JSON) error {
+	return r.db.WithContext(ctx).Model(&entity.RunResult{}).Where("run_id = ? AND image_id = ?", runID, imageID).Update("score", score).Error
+}
+
+// ClaimPending marks up to limit pending results of a run queued, skipping
+// rows locked by concurrent claims
+func (r *PostgresEvaluationRunRepository) ClaimPending(ctx context.Context, runID uuid.UUID, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Raw(`UPDATE run_results SET status = ?, updated_at = ?
+		WHERE run_id = ? AND image_id IN (
+			SELECT image_id FROM run_results
+			WHERE run_id = ? AND status = ?
+			ORDER BY image_id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED)
+		RETURNING image_id`,
+		entity.RunResultQueued, time.Now(), runID, runID, entity.RunResultPending, limit).
+		Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ReleaseQueued sets the result of an image pending again if it is queued
+func (r *PostgresEvaluationRunRepository) ReleaseQueued(ctx context.Context, runID, imageID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&entity.RunResult{}).
+		Where("run_id = ? AND image_id = ? AND status = ?", runID, imageID, entity.RunResultQueued).
+		Updates(map[string]any{
+			"status":     entity.RunResultPending,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// RequeueFailed sets the failed results of a run pending again, keeping
+// their last prediction until a new one is stored
+func (r *PostgresEvaluationRunRepository) RequeueFailed(ctx context.Context, runID uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&entity.RunResult{}).
+		Where("run_id = ? AND status = ?", runID, entity.RunResultFailed).
+		Updates(map[string]any{
+			"status":     entity.RunResultPending,
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// CompleteIfDone marks a running run completed when none of its results is
+// pending or queued
+func (r *PostgresEvaluationRunRepository) CompleteIfDone(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&entity.EvaluationRun{}).
+		Where("id = ? AND status = ?", id, entity.RunStatusRunning).
+		Where("NOT EXISTS (SELECT 1 FROM run_results WHERE run_id = ? AND status IN ?)", id, []string{entity.RunResultPending, entity.RunResultQueued}).
+		Updates(map[string]any{
+			"status":     entity.RunStatusCompleted,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// GetResults retrieves the results of a run ordered by image, optionally of a
+// single status
+func (r *PostgresEvaluationRunRepository) GetResults(ctx context.Context, runID uuid.UUID, status string) ([]*entity.RunResult, error) {
+	var results []*entity.RunResult
+	query := r.db.WithContext(ctx).Where("run_id = ?", runID).Order("image_id")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetResultsByImage retrieves the results of an image in every run
+func (r *PostgresEvaluationRunRepository) GetResultsByImage(ctx context.Context, imageID uuid.UUID) ([]*entity.RunResult, error) {
+	var results []*entity.RunResult
+	if err := r.db.WithContext(ctx).Where("image_id = ?", imageID).Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// DeleteResultsByImage removes the results of an image from every run
+func (r *PostgresEvaluationRunRepository) DeleteResultsByImage(ctx context.Context, imageID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("image_id = ?", imageID).Delete(&entity.RunResult{}).Error
+}
+
+// GetProgress counts the results of a run by status
+func (r *PostgresEvaluationRunRepository) GetProgress(ctx context.Context, runID uuid.UUID) (*entity.RunProgress, error) {
+	var progress entity.RunProgress
+	err := r.db.WithContext(ctx).Model(&entity.RunResult{}).
+		Select(`COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE status = ?) AS pending,
+			COUNT(*) FILTER (WHERE status = ?) AS queued,
+			COUNT(*) FILTER (WHERE status = ?) AS predicted,
+			COUNT(*) FILTER (WHERE status = ?) AS failed,
+			COUNT(score) AS scored`, entity.RunResultPending, entity.RunResultQueued, entity.RunResultPredicted, entity.RunResultFailed).
+		Where("run_id = ?", runID).
+		Scan(&progress).Error
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// AggregateScores sums the scores of a run
+func (r *PostgresEvaluationRunRepository) AggregateScores(ctx context.Context, runID uuid.UUID) (*entity.ScoreAggregate, error) {
+	var agg entity.ScoreAggregate
+	err := r.db.WithContext(ctx).Table("run_results AS r").
+		Select("COUNT(*) AS images, "+scoreAggregateColumns("r.score")).
+		Where("r.run_id = ? AND r.score IS NOT NULL", runID).
+		Scan(&agg).Error
+	if err != nil {
+		return nil, err
+	}
+	return &agg, nil
+}
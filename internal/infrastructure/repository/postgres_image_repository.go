@@ -2,10 +2,14 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/label-platform-backend/internal/domain/entity"
 	"github.com/label-platform-backend/internal/domain/repository"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -53,3 +57,242 @@ func (r *PostgresImageRepository) Update(ctx context.Context, image *entity.Imag
 func (r *PostgresImageRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&entity.Image{}).Error
 }
+
+// SetPredictedLabel atomically sets the prediction of a single model without
+// overwriting the predictions concurrently reported by other models
+func (r *PostgresImageRepository) SetPredictedLabel(ctx context.Context, id uuid.UUID, model string, value datatypes.JSON) error {
+	result := r.db.WithContext(ctx).Model(&entity.Image{}).Where("id = ?", id).Updates(map[string]any{
+		"predicted_labels": gorm.Expr("COALESCE(predicted_labels, '{}'::jsonb) || jsonb_build_object(?::text, ?::jsonb)", model, string(value)),
+		"updated_at":       time.Now(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetDraft stores the draft ground truth of an image
+func (r *PostgresImageRepository) SetDraft(ctx context.Context, id uuid.UUID, draft datatypes.JSON) error {
+	return r.db.WithContext(ctx).Model(&entity.Image{}).Where("id = ?", id).Update("draft", draft).Error
+}
+
+// SetGroundTruth replaces the ground truth of an image, leaving the columns
+// written concurrently by predictions untouched
+func (r *PostgresImageRepository) SetGroundTruth(ctx context.Context, id uuid.UUID, groundTruth datatypes.JSON) error {
+	return r.updateColumns(ctx, id, map[string]any{"ground_truth": groundTruth})
+}
+
+// SetGold marks or unmarks an image as gold
+func (r *PostgresImageRepository) SetGold(ctx context.Context, id uuid.UUID, gold bool) error {
+	return r.updateColumns(ctx, id, map[string]any{"gold": gold})
+}
+
+// UpdateMetadata replaces the project, tags and split of an image
+func (r *PostgresImageRepository) UpdateMetadata(ctx context.Context, id uuid.UUID, projectID *uuid.UUID, tags []string, split string) error {
+	return r.updateColumns(ctx, id, map[string]any{
+		"project_id": projectID,
+		"tags":       datatypes.JSONSlice[string](tags),
+		"split":      split,
+	})
+}
+
+// updateColumns updates the given columns and updated_at of an image
+func (r *PostgresImageRepository) updateColumns(ctx context.Context, id uuid.UUID, columns map[string]any) error {
+	columns["updated_at"] = time.Now()
+	result := r.db.WithContext(ctx).Model(&entity.Image{}).Where("id = ?", id).Updates(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateDimensions stores the pixel size of an image
+func (r *PostgresImageRepository) UpdateDimensions(ctx context.Context, id uuid.UUID, width, height int) error {
+	return r.db.WithContext(ctx).Model(&entity.Image{}).Where("id = ?", id).Updates(map[string]any{
+		"width":  width,
+		"height": height,
+	}).Error
+}
+
+// UpdateContentHash stores the SHA-256 of an image's content
+func (r *PostgresImageRepository) UpdateContentHash(ctx context.Context, id uuid.UUID, contentHash string) error {
+	return r.db.WithContext(ctx).Model(&entity.Image{}).Where("id = ?", id).Update("content_hash", contentHash).Error
+}
+
+// MergeEvaluationScores merges per-model scores into evaluation_scores,
+// keeping the scores of models not present in scores
+func (r *PostgresImageRepository) MergeEvaluationScores(ctx context.Context, id uuid.UUID, scores datatypes.JSON) error {
+	return r.db.WithContext(ctx).Model(&entity.Image{}).Where("id = ?", id).
+		Update("evaluation_scores", gorm.Expr("COALESCE(evaluation_scores, '{}'::jsonb) || ?::jsonb", string(scores))).Error
+}
+
+// AggregateScores sums the per-model evaluation scores of the images with
+// ground truth matching the filter, grouped by model and prompt version
+func (r *PostgresImageRepository) AggregateScores(ctx context.Context, filter repository.ImageFilter) ([]*entity.ScoreAggregate, error) {
+	query := r.db.WithContext(ctx).
+		Table("images AS i, LATERAL jsonb_each(CASE WHEN jsonb_typeof(i.evaluation_scores) = 'object' THEN i.evaluation_scores ELSE '{}'::jsonb END) AS s").
+		Select(`s.key AS model,
+			COALESCE(s.value->>'prompt_version', '') AS prompt_version,
+			COUNT(*) AS images, ` + scoreAggregateColumns("s.value")).
+		Where("i.ground_truth IS NOT NULL").
+		Where("jsonb_typeof(s.value) = 'object' AND s.value->'tp' IS NOT NULL").
+		Group("1, 2").
+		Order("1, 2")
+	query = applyImageFilter(query, "i.", filter)
+
+	var aggregates []*entity.ScoreAggregate
+	if err := query.Scan(&aggregates).Error; err != nil {
+		return nil, err
+	}
+	return aggregates, nil
+}
+
+// AggregateConfusion sums the per-image confusion matrices of the images with
+// ground truth matching the filter, by model and prompt version. An empty
+// model aggregates every model.
+func (r *PostgresImageRepository) AggregateConfusion(ctx context.Context, filter repository.ImageFilter, model string) ([]*entity.ConfusionCell, error) {
+	query := r.db.WithContext(ctx).
+		Table(`images AS i,
+			LATERAL jsonb_each(CASE WHEN jsonb_typeof(i.evaluation_scores) = 'object' THEN i.evaluation_scores ELSE '{}'::jsonb END) AS s,
+			LATERAL jsonb_each(CASE WHEN jsonb_typeof(s.value->'confusion') = 'object' THEN s.value->'confusion' ELSE '{}'::jsonb END) AS gt,
+			LATERAL jsonb_each_text(CASE WHEN jsonb_typeof(gt.value) = 'object' THEN gt.value ELSE '{}'::jsonb END) AS cell`).
+		Select(`s.key AS model,
+			COALESCE(s.value->>'prompt_version', '') AS prompt_version,
+			gt.key AS ground_truth,
+			cell.key AS predicted,
+			SUM(cell.value::int) AS count`).
+		Where("i.ground_truth IS NOT NULL").
+		Group("1, 2, 3, 4").
+		Order("1, 2, 3, 4")
+	if model != "" {
+		query = query.Where("s.key = ?", model)
+	}
+	query = applyImageFilter(query, "i.", filter)
+
+	var cells []*entity.ConfusionCell
+	if err := query.Scan(&cells).Error; err != nil {
+		return nil, err
+	}
+	return cells, nil
+}
+
+// ListDetections returns the scored detections of the images with ground
+// truth matching the filter. An empty model lists every model.
+func (r *PostgresImageRepository) ListDetections(ctx context.Context, filter repository.ImageFilter, model string) ([]*entity.DetectionRecord, error) {
+	query := r.db.WithContext(ctx).
+		Table(`images AS i,
+			LATERAL jsonb_each(CASE WHEN jsonb_typeof(i.evaluation_scores) = 'object' THEN i.evaluation_scores ELSE '{}'::jsonb END) AS s,
+			LATERAL jsonb_array_elements(CASE WHEN jsonb_typeof(s.value->'detections') = 'array' THEN s.value->'detections' ELSE '[]'::jsonb END) AS d`).
+		Select(`s.key AS model,
+			COALESCE(s.value->>'prompt_version', '') AS prompt_version,
+			d.value->>'class' AS class,
+			COALESCE((d.value->>'confidence')::float8, 0) AS confidence,
+			COALESCE((d.value->>'tp_mask')::int, 0) AS tp_mask`).
+		Where("i.ground_truth IS NOT NULL").
+		Order("1, 2")
+	if model != "" {
+		query = query.Where("s.key = ?", model)
+	}
+	query = applyImageFilter(query, "i.", filter)
+
+	var detections []*entity.DetectionRecord
+	if err := query.Scan(&detections).Error; err != nil {
+		return nil, err
+	}
+	return detections, nil
+}
+
+// ListScores returns the evaluation scores of the given models on the images
+// with ground truth matching the filter, without their confusion matrices and
+// detections
+func (r *PostgresImageRepository) ListScores(ctx context.Context, filter repository.ImageFilter, models []string) ([]*entity.ImageScore, error) {
+	query := r.db.WithContext(ctx).
+		Table("images AS i, LATERAL jsonb_each(CASE WHEN jsonb_typeof(i.evaluation_scores) = 'object' THEN i.evaluation_scores ELSE '{}'::jsonb END) AS s").
+		Select("i.id AS image_id, s.key AS model, s.value - 'confusion' - 'detections' AS score").
+		Where("i.ground_truth IS NOT NULL AND s.key IN ?", models).
+		Where("jsonb_typeof(s.value) = 'object' AND s.value->'tp' IS NOT NULL").
+		Order("i.id")
+	query = applyImageFilter(query, "i.", filter)
+
+	var scores []*entity.ImageScore
+	if err := query.Scan(&scores).Error; err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+// scoreAggregateColumns selects the sums and averages of ScoreAggregate over
+// the evaluation scores in the JSONB expression score
+func scoreAggregateColumns(score string) string {
+	return strings.ReplaceAll(`SUM(({score}->>'tp')::int) AS tp,
+		SUM(({score}->>'fp')::int) AS fp,
+		SUM(({score}->>'fn')::int) AS fn,
+		SUM(({score}->>'matched')::int) AS matched,
+		SUM(({score}->>'iou_sum')::float8) AS iou_sum,
+		AVG(({score}->>'precision')::float8) AS macro_precision,
+		AVG(({score}->>'recall')::float8) AS macro_recall,
+		AVG(({score}->>'f1')::float8) AS macro_f1,
+		AVG(({score}->>'miou')::float8) AS macro_miou,
+		SUM(COALESCE(({score}->'text'->>'pairs')::int, 0)) AS text_pairs,
+		SUM(COALESCE(({score}->'text'->>'exact_matches')::int, 0)) AS text_exact_matches,
+		SUM(COALESCE(({score}->'text'->>'edit_distance')::int, 0)) AS text_edit_distance,
+		SUM(COALESCE(({score}->'text'->>'gt_chars')::int, 0)) AS text_gt_chars,
+		SUM(COALESCE(({score}->'text'->>'ned_sum')::float8, 0)) AS text_ned_sum,
+		AVG(({score}->'text'->>'exact_match')::float8) AS macro_text_exact_match,
+		AVG(({score}->'text'->>'ned')::float8) AS macro_text_ned,
+		AVG(({score}->'text'->>'cer')::float8) AS macro_text_cer`, "{score}", score)
+}
+
+// ListIDs returns the IDs of the images matching the filter, oldest first
+func (r *PostgresImageRepository) ListIDs(ctx context.Context, filter repository.ImageFilter) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	query := applyImageFilter(r.db.WithContext(ctx).Model(&entity.Image{}), "", filter).Order("created_at, id")
+	if err := query.Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// List retrieves the images matching the filter, oldest first
+func (r *PostgresImageRepository) List(ctx context.Context, filter repository.ImageFilter) ([]*entity.Image, error) {
+	var images []*entity.Image
+	query := applyImageFilter(r.db.WithContext(ctx), "", filter).Order("created_at, id")
+	if err := query.Find(&images).Error; err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// applyImageFilter adds the conditions of filter to a query on images,
+// prefix being the images table alias
+func applyImageFilter(query *gorm.DB, prefix string, filter repository.ImageFilter) *gorm.DB {
+	if len(filter.IDs) > 0 {
+		query = query.Where(prefix+"id IN ?", filter.IDs)
+	}
+	if filter.ProjectID != nil {
+		query = query.Where(prefix+"project_id = ?", *filter.ProjectID)
+	}
+	if len(filter.Tags) > 0 {
+		tags, _ := json.Marshal(filter.Tags)
+		query = query.Where(prefix+"tags @> ?::jsonb", string(tags))
+	}
+	if filter.Split != "" {
+		query = query.Where(prefix+"split = ?", filter.Split)
+	}
+	if filter.Gold != nil {
+		query = query.Where(prefix+"gold = ?", *filter.Gold)
+	}
+	if filter.From != nil {
+		query = query.Where(prefix+"created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where(prefix+"created_at < ?", *filter.To)
+	}
+	return query
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// PostgresModelRepository implements the ModelRepository interface
+type PostgresModelRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresModelRepository creates a new PostgreSQL model repository
+func NewPostgresModelRepository(db *gorm.DB) repository.ModelRepository {
+	return &PostgresModelRepository{db: db}
+}
+
+// Create saves a new model to the database
+func (r *PostgresModelRepository) Create(ctx context.Context, model *entity.Model) error {
+	return r.db.WithContext(ctx).Create(model).Error
+}
+
+// GetByID retrieves a model by its ID
+func (r *PostgresModelRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Model, error) {
+	var model entity.Model
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&model).Error
+	if err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+// GetAll retrieves all models
+func (r *PostgresModelRepository) GetAll(ctx context.Context) ([]*entity.Model, error) {
+	var models []*entity.Model
+	err := r.db.WithContext(ctx).Order("name").Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+// GetEnabled retrieves all enabled models
+func (r *PostgresModelRepository) GetEnabled(ctx context.Context) ([]*entity.Model, error) {
+	var models []*entity.Model
+	err := r.db.WithContext(ctx).Where("enabled = ?", true).Order("name").Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+// Update updates an existing model
+func (r *PostgresModelRepository) Update(ctx context.Context, model *entity.Model) error {
+	return r.db.WithContext(ctx).Save(model).Error
+}
+
+// Delete removes a model by its ID
+func (r *PostgresModelRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&entity.Model{}).Error
+}
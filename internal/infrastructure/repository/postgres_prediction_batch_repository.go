@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// PostgresPredictionBatchRepository implements the PredictionBatchRepository interface
+type PostgresPredictionBatchRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresPredictionBatchRepository creates a new PostgreSQL prediction batch repository
+func NewPostgresPredictionBatchRepository(db *gorm.DB) repository.PredictionBatchRepository {
+	return &PostgresPredictionBatchRepository{db: db}
+}
+
+// Create saves a new batch
+func (r *PostgresPredictionBatchRepository) Create(ctx context.Context, batch *entity.PredictionBatch) error {
+	return r.db.WithContext(ctx).Create(batch).Error
+}
+
+// GetByID retrieves a batch by its ID
+func (r *PostgresPredictionBatchRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.PredictionBatch, error) {
+	var batch entity.PredictionBatch
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&batch).Error
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// GetAll retrieves all batches, newest first, optionally of a single project
+func (r *PostgresPredictionBatchRepository) GetAll(ctx context.Context, projectID *uuid.UUID) ([]*entity.PredictionBatch, error) {
+	var batches []*entity.PredictionBatch
+	query := r.db.WithContext(ctx).Order("created_at DESC")
+	if projectID != nil {
+		query = query.Where("project_id = ?", *projectID)
+	}
+	if err := query.Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+// GetByStatus retrieves the batches with a status, oldest first
+func (r *PostgresPredictionBatchRepository) GetByStatus(ctx context.Context, status string) ([]*entity.PredictionBatch, error) {
+	var batches []*entity.PredictionBatch
+	if err := r.db.WithContext(ctx).Where("status = ?", status).Order("created_at").Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+// Delete removes a batch by its ID
+func (r *PostgresPredictionBatchRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&entity.PredictionBatch{}).Error
+}
+
+// UpdateStatus moves a batch to status if it is in one of the from statuses
+func (r *PostgresPredictionBatchRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status string, from ...string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entity.PredictionBatch{}).
+		Where("id = ? AND status IN ?", id, from).
+		Updates(map[string]any{
+			"status":     status,
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdateBudget sets the prediction budget of a batch
+func (r *PostgresPredictionBatchRepository) UpdateBudget(ctx context.Context, id uuid.UUID, budget int) error {
+	return r.db.WithContext(ctx).Model(&entity.PredictionBatch{}).Where("id = ?", id).Updates(map[string]any{
+		"budget":     budget,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// AddDispatched counts n more predictions dispatched by a batch
+func (r *PostgresPredictionBatchRepository) AddDispatched(ctx context.Context, id uuid.UUID, n int) error {
+	return r.db.WithContext(ctx).Model(&entity.PredictionBatch{}).Where("id = ?", id).
+		Update("dispatched", gorm.Expr("dispatched + ?", n)).Error
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresPredictionCacheRepository implements the PredictionCacheRepository interface
+type PostgresPredictionCacheRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresPredictionCacheRepository creates a new PostgreSQL prediction cache repository
+func NewPostgresPredictionCacheRepository(db *gorm.DB) repository.PredictionCacheRepository {
+	return &PostgresPredictionCacheRepository{db: db}
+}
+
+// Get retrieves the cached prediction of a model for a content hash and prompt version
+func (r *PostgresPredictionCacheRepository) Get(ctx context.Context, contentHash, model, promptVersion string) (*entity.PredictionCacheEntry, error) {
+	var entry entity.PredictionCacheEntry
+	err := r.db.WithContext(ctx).
+		Where("content_hash = ? AND model = ? AND prompt_version = ?", contentHash, model, promptVersion).
+		First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Put stores a prediction, replacing any previous entry for the same key
+func (r *PostgresPredictionCacheRepository) Put(ctx context.Context, entry *entity.PredictionCacheEntry) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(entry).Error
+}
+
+// CountByModel returns the number of cached predictions per model
+func (r *PostgresPredictionCacheRepository) CountByModel(ctx context.Context) (map[string]int64, error) {
+	var rows []struct {
+		Model string
+		Count int64
+	}
+	err := r.db.WithContext(ctx).Model(&entity.PredictionCacheEntry{}).
+		Select("model, COUNT(*) AS count").
+		Group("model").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Model] = row.Count
+	}
+	return counts, nil
+}
+
+// Delete removes the cached predictions of a model, or all of them when model is empty
+func (r *PostgresPredictionCacheRepository) Delete(ctx context.Context, model string) (int64, error) {
+	query := r.db.WithContext(ctx)
+	if model != "" {
+		query = query.Where("model = ?", model)
+	} else {
+		query = query.Where("1 = 1")
+	}
+	result := query.Delete(&entity.PredictionCacheEntry{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// PostgresProjectRepository implements the ProjectRepository interface
+type PostgresProjectRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresProjectRepository creates a new PostgreSQL project repository
+func NewPostgresProjectRepository(db *gorm.DB) repository.ProjectRepository {
+	return &PostgresProjectRepository{db: db}
+}
+
+// Create saves a new project to the database
+func (r *PostgresProjectRepository) Create(ctx context.Context, project *entity.Project) error {
+	return r.db.WithContext(ctx).Create(project).Error
+}
+
+// GetByID retrieves a project by its ID
+func (r *PostgresProjectRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Project, error) {
+	var project entity.Project
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&project).Error
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// GetAll retrieves all projects
+func (r *PostgresProjectRepository) GetAll(ctx context.Context) ([]*entity.Project, error) {
+	var projects []*entity.Project
+	err := r.db.WithContext(ctx).Order("created_at").Find(&projects).Error
+	if err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// Update updates an existing project
+func (r *PostgresProjectRepository) Update(ctx context.Context, project *entity.Project) error {
+	return r.db.WithContext(ctx).Save(project).Error
+}
+
+// Delete removes a project by its ID, in one transaction with its
+// prediction batches. Its images, runs, tasks and annotation sessions are
+// kept without project.
+func (r *PostgresProjectRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, model := range []any{&entity.Image{}, &entity.EvaluationRun{}, &entity.AnnotationTask{}, &entity.AnnotationSession{}} {
+			if err := tx.Model(model).Where("project_id = ?", id).Update("project_id", nil).Error; err != nil {
+				return err
+			}
+		}
+		batches := tx.Model(&entity.PredictionBatch{}).Select("id").Where("project_id = ?", id)
+		if err := tx.Model(&entity.EvaluationRun{}).Where("batch_id IN (?)", batches).Update("batch_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", id).Delete(&entity.PredictionBatch{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("id = ?", id).Delete(&entity.Project{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// PostgresPromptRepository implements the PromptRepository interface
+type PostgresPromptRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresPromptRepository creates a new PostgreSQL prompt repository
+func NewPostgresPromptRepository(db *gorm.DB) repository.PromptRepository {
+	return &PostgresPromptRepository{db: db}
+}
+
+// Create saves a new prompt template to the database. It is made active
+// when its model has no active prompt, under a lock on the model so that
+// concurrent creations cannot both become active.
+func (r *PostgresPromptRepository) Create(ctx context.Context, prompt *entity.PromptTemplate) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockModel(tx, prompt.Model); err != nil {
+			return err
+		}
+		var active int64
+		if err := tx.Model(&entity.PromptTemplate{}).Where("model = ? AND active = ?", prompt.Model, true).Count(&active).Error; err != nil {
+			return err
+		}
+		prompt.Active = active == 0
+		return tx.Create(prompt).Error
+	})
+}
+
+// GetByID retrieves a prompt template by its ID
+func (r *PostgresPromptRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.PromptTemplate, error) {
+	var prompt entity.PromptTemplate
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&prompt).Error
+	if err != nil {
+		return nil, err
+	}
+	return &prompt, nil
+}
+
+// GetByVersion retrieves a specific prompt version of a model
+func (r *PostgresPromptRepository) GetByVersion(ctx context.Context, model, version string) (*entity.PromptTemplate, error) {
+	var prompt entity.PromptTemplate
+	err := r.db.WithContext(ctx).Where("model = ? AND version = ?", model, version).First(&prompt).Error
+	if err != nil {
+		return nil, err
+	}
+	return &prompt, nil
+}
+
+// GetActive retrieves the active prompt of a model
+func (r *PostgresPromptRepository) GetActive(ctx context.Context, model string) (*entity.PromptTemplate, error) {
+	var prompt entity.PromptTemplate
+	err := r.db.WithContext(ctx).Where("model = ? AND active = ?", model, true).First(&prompt).Error
+	if err != nil {
+		return nil, err
+	}
+	return &prompt, nil
+}
+
+// GetAll retrieves all prompt templates, optionally filtered by model
+func (r *PostgresPromptRepository) GetAll(ctx context.Context, model string) ([]*entity.PromptTemplate, error) {
+	var prompts []*entity.PromptTemplate
+	query := r.db.WithContext(ctx).Order("model, created_at")
+	if model != "" {
+		query = query.Where("model = ?", model)
+	}
+	if err := query.Find(&prompts).Error; err != nil {
+		return nil, err
+	}
+	return prompts, nil
+}
+
+// Activate makes a prompt the active version of its model, deactivating the others
+func (r *PostgresPromptRepository) Activate(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var prompt entity.PromptTemplate
+		if err := tx.Where("id = ?", id).First(&prompt).Error; err != nil {
+			return err
+		}
+		if err := lockModel(tx, prompt.Model); err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.PromptTemplate{}).Where("model = ? AND id <> ?", prompt.Model, id).Update("active", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entity.PromptTemplate{}).Where("id = ?", id).Update("active", true).Error
+	})
+}
+
+// lockModel serializes the transactions changing the active prompt of a
+// model until tx ends
+func lockModel(tx *gorm.DB, model string) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "prompt:"+model).Error
+}
+
+// Delete removes a prompt template by its ID
+func (r *PostgresPromptRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&entity.PromptTemplate{}).Error
+}
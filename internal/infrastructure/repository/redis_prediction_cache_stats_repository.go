@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/label-platform-backend/internal/domain/repository"
+	"github.com/label-platform-backend/internal/infrastructure/redis"
+)
+
+// RedisPredictionCacheStatsRepository implements the
+// PredictionCacheStatsRepository interface with Redis counters
+type RedisPredictionCacheStatsRepository struct{}
+
+// NewRedisPredictionCacheStatsRepository creates a new Redis prediction cache stats repository
+func NewRedisPredictionCacheStatsRepository() repository.PredictionCacheStatsRepository {
+	return &RedisPredictionCacheStatsRepository{}
+}
+
+// Incr counts a cache outcome of a model
+func (r *RedisPredictionCacheStatsRepository) Incr(ctx context.Context, model, outcome string) error {
+	return redis.IncrCacheStat(ctx, model, outcome)
+}
+
+// GetAll returns the cache counters of every model
+func (r *RedisPredictionCacheStatsRepository) GetAll(ctx context.Context) (map[string]map[string]int64, error) {
+	return redis.GetCacheStats(ctx)
+}
+
+// Reset clears the cache counters of a model, or of every model when model
+// is empty
+func (r *RedisPredictionCacheStatsRepository) Reset(ctx context.Context, model string) error {
+	return redis.ResetCacheStats(ctx, model)
+}
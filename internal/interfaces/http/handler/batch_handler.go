@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/usecase"
+	"gorm.io/gorm"
+)
+
+// BatchHandler handles HTTP requests for prediction batches
+type BatchHandler struct {
+	batchUseCase usecase.BatchUseCase
+}
+
+// NewBatchHandler creates a new prediction batch handler
+func NewBatchHandler(batchUseCase usecase.BatchUseCase) *BatchHandler {
+	return &BatchHandler{
+		batchUseCase: batchUseCase,
+	}
+}
+
+// batchRequest is the body accepted when creating a batch
+type batchRequest struct {
+	Name           string            `json:"name"`
+	Models         []string          `json:"models"`
+	PromptVersion  string            `json:"prompt_version"`
+	PromptVersions map[string]string `json:"prompt_versions"`
+	Parameters     map[string]any    `json:"parameters"`
+	Force          bool              `json:"force"`
+	Priority       string            `json:"priority"`
+	Concurrency    int               `json:"concurrency"`
+	Budget         int               `json:"budget"`
+	Tags           []string          `json:"tags"`
+	Split          string            `json:"split"`
+	From           string            `json:"from"`
+	To             string            `json:"to"`
+}
+
+// resumeRequest is the optional body accepted when resuming a batch
+type resumeRequest struct {
+	Budget      *int `json:"budget"`
+	RetryFailed bool `json:"retry_failed"`
+}
+
+// CreateBatch handles POST /api/v1/projects/:id/predict
+func (h *BatchHandler) CreateBatch(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var request batchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+	filter, err := imageFilter(request.Tags, request.Split, request.From, request.To)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	batch, err := h.batchUseCase.CreateBatch(c.Request.Context(), id, usecase.BatchRequest{
+		Name:           request.Name,
+		Models:         request.Models,
+		PromptVersion:  request.PromptVersion,
+		PromptVersions: request.PromptVersions,
+		Parameters:     request.Parameters,
+		Force:          request.Force,
+		Priority:       request.Priority,
+		Concurrency:    request.Concurrency,
+		Budget:         request.Budget,
+		Filter:         filter,
+	})
+	if err != nil {
+		writeBatchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, batch)
+}
+
+// GetAllBatches handles GET /api/v1/batches, optionally filtered by project_id
+func (h *BatchHandler) GetAllBatches(c *gin.Context) {
+	var projectID *uuid.UUID
+	if value := c.Query("project_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project_id format"})
+			return
+		}
+		projectID = &id
+	}
+
+	batches, err := h.batchUseCase.GetAllBatches(c.Request.Context(), projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, batches)
+}
+
+// GetBatchByID handles GET /api/v1/batches/:id
+func (h *BatchHandler) GetBatchByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	batch, err := h.batchUseCase.GetBatch(c.Request.Context(), id)
+	if err != nil {
+		writeBatchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
+
+// CancelBatch handles POST /api/v1/batches/:id/cancel
+func (h *BatchHandler) CancelBatch(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	batch, err := h.batchUseCase.CancelBatch(c.Request.Context(), id)
+	if err != nil {
+		writeBatchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
+
+// ResumeBatch handles POST /api/v1/batches/:id/resume
+func (h *BatchHandler) ResumeBatch(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var request resumeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+			return
+		}
+	}
+
+	batch, err := h.batchUseCase.ResumeBatch(c.Request.Context(), id, request.Budget, request.RetryFailed)
+	if err != nil {
+		writeBatchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
+
+func writeBatchError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Batch or project not found"})
+	case errors.Is(err, usecase.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/label-platform-backend/internal/domain/entity"
+)
+
+// writeCSV writes rows as a CSV attachment
+func writeCSV(c *gin.Context, filename string, rows [][]string) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.WriteAll(rows)
+}
+
+// confusionCSV lays out confusion matrices with one row per model, prompt
+// version and ground truth class, and one column per predicted class
+func confusionCSV(reports []*entity.ConfusionReport) [][]string {
+	// Every matrix gets the same columns so the CSV stays rectangular
+	combined := entity.ConfusionMatrix{}
+	for _, report := range reports {
+		for gt, row := range report.Matrix {
+			for pred, n := range row {
+				combined.Add(gt, pred, n)
+			}
+		}
+	}
+	columns := combined.Classes()
+
+	header := []string{"model", "prompt_version", "ground_truth"}
+	rows := [][]string{append(header, columns...)}
+	for _, report := range reports {
+		for _, gt := range report.Classes {
+			row := []string{report.Model, report.PromptVersion, gt}
+			for _, pred := range columns {
+				row = append(row, strconv.Itoa(report.Matrix[gt][pred]))
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// classMetricsCSV lays out per-class metrics with one row per model, prompt
+// version and class
+func classMetricsCSV(reports []*entity.ConfusionReport) [][]string {
+	rows := [][]string{{
+		"model", "prompt_version", "class", "support", "predicted", "tp", "fp", "fn",
+		"missed", "mislabeled", "spurious", "precision", "recall", "f1",
+	}}
+	for _, report := range reports {
+		for _, m := range report.PerClass {
+			rows = append(rows, []string{
+				report.Model, report.PromptVersion, m.Class,
+				strconv.Itoa(m.Support), strconv.Itoa(m.Predicted),
+				strconv.Itoa(m.TP), strconv.Itoa(m.FP), strconv.Itoa(m.FN),
+				strconv.Itoa(m.Missed), strconv.Itoa(m.Mislabeled), strconv.Itoa(m.Spurious),
+				formatFloat(m.Precision), formatFloat(m.Recall), formatFloat(m.F1),
+			})
+		}
+	}
+	return rows
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
@@ -1,36 +1,42 @@
 package handler
 
 import (
-	"encoding/base64"
 	"encoding/json"
-	"io"
+	"errors"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
 	"github.com/label-platform-backend/internal/domain/usecase"
 	"github.com/label-platform-backend/internal/infrastructure"
 	"github.com/label-platform-backend/internal/infrastructure/redis"
-	"github.com/label-platform-backend/internal/infrastructure/storage"
-	"github.com/minio/minio-go/v7"
+	"github.com/label-platform-backend/pkg/worker"
+	"gorm.io/gorm"
 )
 
 // ImageHandler handles HTTP requests for images
 type ImageHandler struct {
-	imageUseCase usecase.ImageUseCase
+	imageUseCase      usecase.ImageUseCase
+	predictionUseCase usecase.PredictionUseCase
+	taskUseCase       usecase.TaskUseCase
 }
 
 // NewImageHandler creates a new image handler
-func NewImageHandler(imageUseCase usecase.ImageUseCase) *ImageHandler {
+func NewImageHandler(imageUseCase usecase.ImageUseCase, predictionUseCase usecase.PredictionUseCase, taskUseCase usecase.TaskUseCase) *ImageHandler {
 	return &ImageHandler{
-		imageUseCase: imageUseCase,
+		imageUseCase:      imageUseCase,
+		predictionUseCase: predictionUseCase,
+		taskUseCase:       taskUseCase,
 	}
 }
 
-// UploadImage handles image upload requests
+// UploadImage handles image upload requests. Ground truth uploaded with the
+// image is submitted for review on the annotator's task, like edits.
 func (h *ImageHandler) UploadImage(c *gin.Context) {
 	// Check if file is present in the request
 	file, err := c.FormFile("image")
@@ -73,11 +79,26 @@ func (h *ImageHandler) UploadImage(c *gin.Context) {
 			})
 			return
 		}
+		if strings.TrimSpace(c.PostForm("annotator")) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "annotator is required with ground truth"})
+			return
+		}
+	}
+
+	// Parse dataset metadata from form data
+	metadata, err := parseImageMetadata(c.PostForm("project_id"), splitTags(c.PostForm("tags")), c.PostForm("split"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	// Upload image
-	image, err := h.imageUseCase.UploadImage(c.Request.Context(), file, groundTruth)
+	image, err := h.imageUseCase.UploadImage(c.Request.Context(), file, metadata)
 	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to upload image",
 			"details": err.Error(),
@@ -85,6 +106,20 @@ func (h *ImageHandler) UploadImage(c *gin.Context) {
 		return
 	}
 
+	// Submit the ground truth for review
+	var task *entity.AnnotationTask
+	if groundTruth != nil {
+		task, err = h.taskUseCase.ProposeGroundTruth(c.Request.Context(), image.ID, c.PostForm("annotator"), groundTruth)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Image uploaded but its ground truth could not be submitted for review",
+				"details": err.Error(),
+				"id":      image.ID,
+			})
+			return
+		}
+	}
+
 	// Generate signed URL for the uploaded image
 	signedURL, err := h.imageUseCase.GetImageURL(c.Request.Context(), image.MinioPath, time.Hour)
 	if err != nil {
@@ -96,11 +131,15 @@ func (h *ImageHandler) UploadImage(c *gin.Context) {
 	}
 
 	// Convert datatypes.JSON to map for response
-	var groundTruthMap, predictedLabelsMap, evaluationScoresMap map[string]any
+	image = hideGold(image)
+	var groundTruthMap, draftMap, predictedLabelsMap, evaluationScoresMap map[string]any
 
 	if image.GroundTruth != nil {
 		json.Unmarshal(image.GroundTruth, &groundTruthMap)
 	}
+	if image.Draft != nil {
+		json.Unmarshal(image.Draft, &draftMap)
+	}
 	if image.PredictedLabels != nil {
 		json.Unmarshal(image.PredictedLabels, &predictedLabelsMap)
 	}
@@ -113,10 +152,15 @@ func (h *ImageHandler) UploadImage(c *gin.Context) {
 		"id":                image.ID,
 		"name":              image.Name,
 		"minio_path":        image.MinioPath,
+		"project_id":        image.ProjectID,
+		"tags":              image.Tags,
+		"split":             image.Split,
 		"image_url":         signedURL,
 		"ground_truth":      groundTruthMap,
+		"draft":             draftMap,
 		"predicted_labels":  predictedLabelsMap,
 		"evaluation_scores": evaluationScoresMap,
+		"task":              task,
 		"created_at":        image.CreatedAt,
 		"updated_at":        image.UpdatedAt,
 		"file_info": gin.H{
@@ -154,6 +198,7 @@ func (h *ImageHandler) GetImageByID(c *gin.Context) {
 	}
 
 	// Convert datatypes.JSON to map for response
+	image = hideGold(image)
 	var groundTruthMap, predictedLabelsMap, evaluationScoresMap map[string]any
 
 	if image.GroundTruth != nil {
@@ -171,6 +216,9 @@ func (h *ImageHandler) GetImageByID(c *gin.Context) {
 		"id":                image.ID,
 		"name":              image.Name,
 		"minio_path":        image.MinioPath,
+		"project_id":        image.ProjectID,
+		"tags":              image.Tags,
+		"split":             image.Split,
 		"image_url":         signedURL,
 		"ground_truth":      groundTruthMap,
 		"predicted_labels":  predictedLabelsMap,
@@ -200,6 +248,7 @@ func (h *ImageHandler) GetAllImages(c *gin.Context) {
 		}
 
 		// Convert datatypes.JSON to map for response
+		image = hideGold(image)
 		var groundTruthMap, predictedLabelsMap, evaluationScoresMap map[string]any
 
 		if image.GroundTruth != nil {
@@ -216,6 +265,9 @@ func (h *ImageHandler) GetAllImages(c *gin.Context) {
 			"id":                image.ID,
 			"name":              image.Name,
 			"minio_path":        image.MinioPath,
+			"project_id":        image.ProjectID,
+			"tags":              image.Tags,
+			"split":             image.Split,
 			"image_url":         signedURL,
 			"ground_truth":      groundTruthMap,
 			"predicted_labels":  predictedLabelsMap,
@@ -253,7 +305,7 @@ func (h *ImageHandler) UpdateImage(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, image)
+	c.JSON(http.StatusOK, hideGold(image))
 }
 
 // DeleteImage handles requests to delete an image
@@ -303,67 +355,244 @@ func (h *ImageHandler) GetImageURL(c *gin.Context) {
 	})
 }
 
-// UpdateGroundTruth handles requests to update image ground truth
-func (h *ImageHandler) UpdateGroundTruth(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+// UpdateImageMetadata handles requests to update the project, tags and split of an image
+func (h *ImageHandler) UpdateImageMetadata(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
 		return
 	}
 
 	var request struct {
-		GroundTruth map[string]any `json:"ground_truth"`
+		ProjectID string   `json:"project_id"`
+		Tags      []string `json:"tags"`
+		Split     string   `json:"split"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
 	}
 
+	metadata, err := parseImageMetadata(request.ProjectID, request.Tags, request.Split)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	image, err := h.imageUseCase.UpdateImageMetadata(c.Request.Context(), id, metadata)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		case errors.Is(err, usecase.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, hideGold(image))
+}
+
+// GetGold handles GET /api/v1/admin/images/:id/gold. Gold status is only
+// exposed on admin routes, image responses leave it out along with the gold
+// ground truth.
+func (h *ImageHandler) GetGold(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	image, err := h.imageUseCase.GetImageByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": image.ID, "gold": image.Gold})
+}
+
+// SetGold handles PUT /api/v1/admin/images/:id/gold
+func (h *ImageHandler) SetGold(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var request struct {
+		Gold bool `json:"gold"`
+	}
 	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
 		return
 	}
 
-	image, err := h.imageUseCase.UpdateGroundTruth(c.Request.Context(), id, request.GroundTruth)
+	image, err := h.imageUseCase.SetGold(c.Request.Context(), id, request.Gold)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		case errors.Is(err, usecase.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
-	// Generate signed URL for the image
-	signedURL, err := h.imageUseCase.GetImageURL(c.Request.Context(), image.MinioPath, time.Hour)
+	c.JSON(http.StatusOK, gin.H{"id": image.ID, "gold": image.Gold})
+}
+
+// DraftGroundTruth handles POST /api/v1/images/:id/draft. Without a body,
+// the pre-labeling models of the image's project are used.
+func (h *ImageHandler) DraftGroundTruth(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to generate image URL",
-			"details": err.Error(),
-		})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
 		return
 	}
 
-	// Convert datatypes.JSON to map for response
-	var groundTruthMap, predictedLabelsMap, evaluationScoresMap map[string]any
+	var request struct {
+		Models   []string `json:"models"`
+		MinVotes int      `json:"min_votes"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+			return
+		}
+	}
 
-	if image.GroundTruth != nil {
-		json.Unmarshal(image.GroundTruth, &groundTruthMap)
+	image, err := h.imageUseCase.DraftGroundTruth(c.Request.Context(), id, usecase.PrelabelRequest{
+		Models:   request.Models,
+		MinVotes: request.MinVotes,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Image or project not found"})
+		case errors.Is(err, usecase.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
 	}
-	if image.PredictedLabels != nil {
-		json.Unmarshal(image.PredictedLabels, &predictedLabelsMap)
+
+	var draftMap map[string]any
+	json.Unmarshal(image.Draft, &draftMap)
+	c.JSON(http.StatusOK, gin.H{"id": image.ID, "draft": draftMap})
+}
+
+// RenderImage handles GET /api/v1/images/:id/render?layers=gt,claude
+func (h *ImageHandler) RenderImage(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var layers []string
+	if v := c.Query("layers"); v != "" {
+		layers = strings.Split(v, ",")
+	}
+
+	png, err := h.imageUseCase.RenderImage(c.Request.Context(), id, layers)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		case errors.Is(err, usecase.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// ComparePrediction handles GET /api/v1/images/:id/compare?model=claude
+func (h *ImageHandler) ComparePrediction(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	diff, err := h.imageUseCase.ComparePrediction(c.Request.Context(), id, c.Query("model"))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		case errors.Is(err, usecase.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// CombinePredictions handles POST /api/v1/images/:id/ensemble
+func (h *ImageHandler) CombinePredictions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	prediction, err := h.imageUseCase.CombinePredictions(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		case errors.Is(err, usecase.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "model": entity.EnsembleModel, "prediction": prediction})
+}
+
+// EvaluateImage handles requests to re-score all predictions of an image
+func (h *ImageHandler) EvaluateImage(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	image, err := h.imageUseCase.EvaluateImage(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		case errors.Is(err, usecase.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
 	}
+
+	var evaluationScoresMap map[string]any
 	if image.EvaluationScores != nil {
 		json.Unmarshal(image.EvaluationScores, &evaluationScoresMap)
 	}
-
-	// Add signed URL to response
-	response := gin.H{
+	c.JSON(http.StatusOK, gin.H{
 		"id":                image.ID,
-		"name":              image.Name,
-		"minio_path":        image.MinioPath,
-		"image_url":         signedURL,
-		"ground_truth":      groundTruthMap,
-		"predicted_labels":  predictedLabelsMap,
 		"evaluation_scores": evaluationScoresMap,
-		"created_at":        image.CreatedAt,
-		"updated_at":        image.UpdatedAt,
-	}
-
-	c.JSON(http.StatusOK, response)
+	})
 }
 
 // PredictImage handles GET /api/v1/images/:id/predict
@@ -375,8 +604,22 @@ func (h *ImageHandler) PredictImage(c *gin.Context) {
 		return
 	}
 
-	// Rate limit: chỉ cho phép mỗi ảnh predict 1 lần mỗi 5 phút
+	// run_id gắn prediction vào một evaluation run, dùng model/prompt/parameters của run
+	var runID *uuid.UUID
+	if value := c.Query("run_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid run_id format"})
+			return
+		}
+		runID = &parsed
+	}
+
+	// Rate limit: chỉ cho phép mỗi ảnh predict 1 lần mỗi 5 phút (mỗi run riêng một lock)
 	lockKey := "predict-lock:" + id.String()
+	if runID != nil {
+		lockKey += ":" + runID.String()
+	}
 	ctx := c.Request.Context()
 	ttl, err := redis.RedisClient.TTL(ctx, lockKey).Result()
 	if err == nil && ttl > 0 {
@@ -402,59 +645,101 @@ func (h *ImageHandler) PredictImage(c *gin.Context) {
 		return
 	}
 
-	// Lấy thông tin ảnh từ Postgres
-	image, err := h.imageUseCase.GetImageByID(c.Request.Context(), id)
+	// prompt_version chọn prompt cho mọi model, prompt_version[model] ghi đè theo từng model
+	// force=true bỏ qua cache và luôn gọi model
+	// priority mặc định là high vì request tương tác được ưu tiên hơn batch
+	force, _ := strconv.ParseBool(c.DefaultQuery("force", "false"))
+	dispatched, err := h.predictionUseCase.PredictImage(ctx, id, usecase.PredictOptions{
+		PromptVersion:  c.Query("prompt_version"),
+		PromptVersions: c.QueryMap("prompt_version"),
+		Force:          force,
+		RunID:          runID,
+		Priority:       c.DefaultQuery("priority", worker.PriorityHigh),
+	})
 	if err != nil {
-		c.JSON(404, gin.H{"error": "Image not found"})
+		// Không giữ lock khi request không được dispatch
+		redis.RedisClient.Del(ctx, lockKey)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(404, gin.H{"error": "Image not found"})
+		case errors.Is(err, usecase.ErrInvalidInput):
+			c.JSON(400, gin.H{"error": err.Error()})
+		default:
+			c.JSON(500, gin.H{"error": "Failed to dispatch prediction", "details": err.Error()})
+		}
 		return
 	}
 
-	// Lấy file ảnh từ MinIO
-	minioClient := h.imageUseCase.(interface{ GetMinioClient() *storage.MinioClient }).GetMinioClient()
-	obj, err := minioClient.GetClient().GetObject(c.Request.Context(), minioClient.GetBucket(), image.MinioPath, minio.GetObjectOptions{})
+	c.JSON(200, gin.H{
+		"message": "Image pushed to model queues",
+		"id":      id,
+		"models":  dispatched,
+	})
+}
+
+// CancelPrediction handles POST /api/v1/images/:id/predict/cancel
+// model chỉ cancel job của một model, bỏ trống thì cancel mọi job của ảnh
+func (h *ImageHandler) CancelPrediction(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(500, gin.H{"error": "Failed to get image from MinIO"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
 		return
 	}
-	defer obj.Close()
-	imgBytes, err := io.ReadAll(obj)
+
+	jobs, err := h.predictionUseCase.CancelJobs(c.Request.Context(), usecase.JobFilter{ImageID: &id, Model: c.Query("model")})
 	if err != nil {
-		c.JSON(500, gin.H{"error": "Failed to read image data"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel jobs", "details": err.Error()})
 		return
 	}
+	c.JSON(http.StatusOK, gin.H{"id": id, "cancelled": jobs})
+}
 
-	// Encode base64
-	imgBase64 := base64.StdEncoding.EncodeToString(imgBytes)
-
-	// Tạo payload
-	payload := map[string]any{
-		"id":           image.ID.String(),
-		"image_base64": imgBase64,
+// GetPredictJobs handles GET /api/v1/predict/jobs
+// Trả về các job đang queued hoặc running, lọc theo image_id, model và run_id
+func (h *ImageHandler) GetPredictJobs(c *gin.Context) {
+	var filter usecase.JobFilter
+	if value := c.Query("image_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image_id format"})
+			return
+		}
+		filter.ImageID = &id
 	}
-	payloadJSON, _ := json.Marshal(payload)
-
-	ctx = c.Request.Context()
-	redis.RedisClient.RPush(ctx, redis.QueueGPT, payloadJSON)
-	redis.RedisClient.RPush(ctx, redis.QueueClaude, payloadJSON)
-	redis.RedisClient.RPush(ctx, redis.QueueGemini, payloadJSON)
+	if value := c.Query("run_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run_id format"})
+			return
+		}
+		filter.RunIDs = []uuid.UUID{id}
+	}
+	filter.Model = c.Query("model")
 
-	c.JSON(200, gin.H{
-		"message": "Image pushed to model queues",
-		"id":      image.ID,
-	})
+	jobs, err := h.predictionUseCase.GetJobs(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get jobs", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, jobs)
 }
 
 // PredictNotifyRequest là struct nhận notify từ worker
 // image_id: ID của ảnh, model: tên model, result: kết quả predict
-// Bạn có thể bổ sung thêm trường nếu cần
+// prompt_version: phiên bản prompt đã dùng, error: lý do thất bại nếu worker không predict được
+// run_id: evaluation run của job, nếu có; job_id: ID của job trong queue
 
 type PredictNotifyRequest struct {
-	ImageID string `json:"image_id"`
-	Model   string `json:"model"`
-	Result  string `json:"result"`
+	ImageID       string `json:"image_id"`
+	Model         string `json:"model"`
+	PromptVersion string `json:"prompt_version,omitempty"`
+	RunID         string `json:"run_id,omitempty"`
+	JobID         string `json:"job_id,omitempty"`
+	Result        string `json:"result"`
+	Error         string `json:"error,omitempty"`
 }
 
-// PredictNotify nhận notify từ worker và forward webhook tới UI
+// PredictNotify nhận notify từ worker, lưu kết quả vào predicted_labels và forward webhook tới UI
 func (h *ImageHandler) PredictNotify(c *gin.Context) {
 	var req PredictNotifyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -462,6 +747,62 @@ func (h *ImageHandler) PredictNotify(c *gin.Context) {
 		return
 	}
 
+	id, err := uuid.Parse(req.ImageID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	if req.Model == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "model is required"})
+		return
+	}
+
+	report := usecase.PredictionReport{
+		ImageID:       id,
+		Model:         req.Model,
+		PromptVersion: req.PromptVersion,
+		Result:        req.Result,
+		Error:         req.Error,
+	}
+	if req.RunID != "" {
+		runID, err := uuid.Parse(req.RunID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run_id format"})
+			return
+		}
+		report.RunID = &runID
+	}
+
+	// Job đã bị cancel hoặc timed out thì bỏ qua kết quả
+	if req.JobID != "" {
+		job, completed, err := h.predictionUseCase.CompleteJob(c.Request.Context(), req.JobID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete job", "details": err.Error()})
+			return
+		}
+		if !completed {
+			c.JSON(http.StatusOK, gin.H{"status": "discarded", "message": "Job was cancelled or timed out, result discarded", "job_id": req.JobID})
+			return
+		}
+		// Kết quả được cache theo prompt version mà job đã yêu cầu
+		if job != nil {
+			report.CacheVersion = &job.PromptVersion
+		}
+	}
+
+	if err := h.imageUseCase.SavePrediction(c.Request.Context(), report); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+			return
+		}
+		if errors.Is(err, usecase.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save prediction", "details": err.Error()})
+		return
+	}
+
 	// Gửi webhook tới UI
 	webhookURL := os.Getenv("WEBHOOK_URL")
 	if webhookURL != "" {
@@ -470,6 +811,18 @@ func (h *ImageHandler) PredictNotify(c *gin.Context) {
 			"model":    req.Model,
 			"result":   req.Result,
 		}
+		if req.PromptVersion != "" {
+			payload["prompt_version"] = req.PromptVersion
+		}
+		if req.RunID != "" {
+			payload["run_id"] = req.RunID
+		}
+		if req.JobID != "" {
+			payload["job_id"] = req.JobID
+		}
+		if req.Error != "" {
+			payload["error"] = req.Error
+		}
 		err := infrastructure.NotifyPredictResult(webhookURL, payload)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "webhook failed", "details": err.Error()})
@@ -509,3 +862,72 @@ func (h *ImageHandler) GetPredictModels(c *gin.Context) {
 		"predicted_labels": predictedLabelsMap,
 	})
 }
+
+// GetPredictCacheStats trả về số lần hit/miss của cache predict theo từng model
+func (h *ImageHandler) GetPredictCacheStats(c *gin.Context) {
+	stats, err := h.predictionUseCase.GetCacheStats(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"models": stats})
+}
+
+// GetPredictJobStats trả về số job timed out, retried và failed theo từng model
+func (h *ImageHandler) GetPredictJobStats(c *gin.Context) {
+	stats, err := h.predictionUseCase.GetJobStats(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"models": stats})
+}
+
+// ClearPredictCache xoá cache predict của một model (?model=) hoặc toàn bộ
+func (h *ImageHandler) ClearPredictCache(c *gin.Context) {
+	deleted, err := h.predictionUseCase.ClearCache(c.Request.Context(), c.Query("model"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Prediction cache cleared", "deleted": deleted})
+}
+
+// hideGold leaves the ground truth of gold images, and the evaluation
+// scores computed from it, out of image responses
+func hideGold(image *entity.Image) *entity.Image {
+	if !image.Gold {
+		return image
+	}
+	hidden := *image
+	hidden.GroundTruth = nil
+	hidden.EvaluationScores = nil
+	return &hidden
+}
+
+// parseImageMetadata validates the dataset metadata of an image
+func parseImageMetadata(projectID string, tags []string, split string) (usecase.ImageMetadata, error) {
+	metadata := usecase.ImageMetadata{Tags: tags, Split: strings.TrimSpace(split)}
+	if projectID != "" {
+		id, err := uuid.Parse(projectID)
+		if err != nil {
+			return metadata, errors.New("invalid project_id format")
+		}
+		metadata.ProjectID = &id
+	}
+	return metadata, nil
+}
+
+// splitTags parses a comma-separated list of tags
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/usecase"
+)
+
+// ModelHandler handles HTTP requests for registered models
+type ModelHandler struct {
+	modelUseCase usecase.ModelUseCase
+}
+
+// NewModelHandler creates a new model handler
+func NewModelHandler(modelUseCase usecase.ModelUseCase) *ModelHandler {
+	return &ModelHandler{
+		modelUseCase: modelUseCase,
+	}
+}
+
+// modelRequest is the body accepted when registering or updating a model
+type modelRequest struct {
+	Name        string  `json:"name"`
+	Provider    string  `json:"provider"`
+	BaseURL     string  `json:"base_url"`
+	ModelName   string  `json:"model_name"`
+	APIKey      string  `json:"api_key"`
+	APIKeyEnv   *string `json:"api_key_env"`
+	MaxTokens   int     `json:"max_tokens"`
+	Temperature float64 `json:"temperature"`
+	Enabled     *bool   `json:"enabled"`
+}
+
+func (r *modelRequest) apply(model *entity.Model) {
+	model.Name = r.Name
+	model.Provider = r.Provider
+	model.BaseURL = r.BaseURL
+	model.ModelName = r.ModelName
+	model.MaxTokens = r.MaxTokens
+	model.Temperature = r.Temperature
+	// Keep the stored key unless a new one is provided. A key replaces the
+	// environment variable and the other way around; giving both is rejected
+	// on validation.
+	if r.APIKey != "" {
+		model.APIKey = r.APIKey
+		model.APIKeyEnv = ""
+	}
+	if r.APIKeyEnv != nil {
+		model.APIKeyEnv = strings.TrimSpace(*r.APIKeyEnv)
+		if model.APIKeyEnv != "" && r.APIKey == "" {
+			model.APIKey = ""
+		}
+	}
+	if r.Enabled != nil {
+		model.Enabled = *r.Enabled
+	}
+}
+
+// CreateModel handles requests to register a model
+func (h *ModelHandler) CreateModel(c *gin.Context) {
+	var request modelRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	model := &entity.Model{Enabled: true}
+	request.apply(model)
+
+	if err := h.modelUseCase.CreateModel(c.Request.Context(), model); err != nil {
+		writeModelError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, model)
+}
+
+// GetAllModels handles requests to list registered models
+func (h *ModelHandler) GetAllModels(c *gin.Context) {
+	models, err := h.modelUseCase.GetAllModels(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models)
+}
+
+// GetModelByID handles requests to get a specific model
+func (h *ModelHandler) GetModelByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	model, err := h.modelUseCase.GetModelByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Model not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model)
+}
+
+// UpdateModel handles requests to update a registered model
+func (h *ModelHandler) UpdateModel(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var request modelRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	model, err := h.modelUseCase.GetModelByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Model not found"})
+		return
+	}
+	request.apply(model)
+
+	if err := h.modelUseCase.UpdateModel(c.Request.Context(), model); err != nil {
+		writeModelError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model)
+}
+
+// DeleteModel handles requests to remove a registered model
+func (h *ModelHandler) DeleteModel(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := h.modelUseCase.DeleteModel(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Model deleted successfully"})
+}
+
+func writeModelError(c *gin.Context, err error) {
+	if errors.Is(err, usecase.ErrInvalidInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
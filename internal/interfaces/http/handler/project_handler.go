@@ -0,0 +1,425 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	"github.com/label-platform-backend/internal/domain/usecase"
+	"gorm.io/gorm"
+)
+
+// ProjectHandler handles HTTP requests for projects
+type ProjectHandler struct {
+	projectUseCase usecase.ProjectUseCase
+}
+
+// NewProjectHandler creates a new project handler
+func NewProjectHandler(projectUseCase usecase.ProjectUseCase) *ProjectHandler {
+	return &ProjectHandler{
+		projectUseCase: projectUseCase,
+	}
+}
+
+// projectRequest is the body accepted when creating or updating a project
+type projectRequest struct {
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	PrelabelModels   []string `json:"prelabel_models"`
+	PrelabelMinVotes int      `json:"prelabel_min_votes"`
+}
+
+// CreateProject handles requests to create a project
+func (h *ProjectHandler) CreateProject(c *gin.Context) {
+	var request projectRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	project := &entity.Project{
+		Name:             request.Name,
+		Description:      request.Description,
+		PrelabelModels:   request.PrelabelModels,
+		PrelabelMinVotes: request.PrelabelMinVotes,
+	}
+	if err := h.projectUseCase.CreateProject(c.Request.Context(), project); err != nil {
+		writeProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, project)
+}
+
+// GetAllProjects handles requests to list projects
+func (h *ProjectHandler) GetAllProjects(c *gin.Context) {
+	projects, err := h.projectUseCase.GetAllProjects(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, projects)
+}
+
+// GetProjectByID handles requests to get a specific project
+func (h *ProjectHandler) GetProjectByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	project, err := h.projectUseCase.GetProjectByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// UpdateProject handles requests to update a project
+func (h *ProjectHandler) UpdateProject(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var request projectRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	project, err := h.projectUseCase.GetProjectByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+	project.Name = request.Name
+	project.Description = request.Description
+	project.PrelabelModels = request.PrelabelModels
+	project.PrelabelMinVotes = request.PrelabelMinVotes
+
+	if err := h.projectUseCase.UpdateProject(c.Request.Context(), project); err != nil {
+		writeProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// DeleteProject handles requests to delete a project
+func (h *ProjectHandler) DeleteProject(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := h.projectUseCase.DeleteProject(c.Request.Context(), id); err != nil {
+		writeProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Project deleted successfully"})
+}
+
+// GetUncertainImages handles GET /api/v1/projects/:id/uncertain
+func (h *ProjectHandler) GetUncertainImages(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit, expected an integer"})
+		return
+	}
+	filter, err := parseImageFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	images, err := h.projectUseCase.GetUncertainImages(c.Request.Context(), id, filter, limit)
+	if err != nil {
+		writeProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, images)
+}
+
+// Prelabel handles POST /api/v1/projects/:id/prelabel
+func (h *ProjectHandler) Prelabel(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	drafted, skipped, err := h.projectUseCase.Prelabel(c.Request.Context(), id)
+	if err != nil {
+		writeProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"drafted": drafted, "skipped": skipped})
+}
+
+// GetLeaderboard handles GET /api/v1/projects/:id/leaderboard
+func (h *ProjectHandler) GetLeaderboard(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	filter, err := parseImageFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := h.projectUseCase.GetLeaderboard(c.Request.Context(), id, filter)
+	if err != nil {
+		writeProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"project_id":  id,
+		"leaderboard": entries,
+	})
+}
+
+// GetConfusion handles GET /api/v1/projects/:id/confusion. With format=csv
+// the matrices are returned as a CSV table with one row per ground truth class.
+func (h *ProjectHandler) GetConfusion(c *gin.Context) {
+	id, reports, ok := h.confusionReports(c)
+	if !ok {
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		writeCSV(c, "confusion.csv", confusionCSV(reports))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"project_id": id,
+		"confusion":  reports,
+	})
+}
+
+// GetClassMetrics handles GET /api/v1/projects/:id/class-metrics. With
+// format=csv the metrics are returned as a CSV table with one row per class.
+func (h *ProjectHandler) GetClassMetrics(c *gin.Context) {
+	id, reports, ok := h.confusionReports(c)
+	if !ok {
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		writeCSV(c, "class-metrics.csv", classMetricsCSV(reports))
+		return
+	}
+
+	models := make([]gin.H, 0, len(reports))
+	for _, report := range reports {
+		models = append(models, gin.H{
+			"model":          report.Model,
+			"prompt_version": report.PromptVersion,
+			"per_class":      report.PerClass,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"project_id": id,
+		"models":     models,
+	})
+}
+
+// GetPrecisionRecall handles GET /api/v1/projects/:id/precision-recall
+func (h *ProjectHandler) GetPrecisionRecall(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	filter, err := parseImageFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reports, err := h.projectUseCase.GetPrecisionRecall(c.Request.Context(), id, c.Query("model"), filter)
+	if err != nil {
+		writeProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"project_id": id,
+		"models":     reports,
+	})
+}
+
+// GetCalibration handles GET /api/v1/projects/:id/calibration
+func (h *ProjectHandler) GetCalibration(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	filter, err := parseImageFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	bins, err := strconv.Atoi(c.DefaultQuery("bins", "10"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bins, expected an integer"})
+		return
+	}
+	maxECE, err := strconv.ParseFloat(c.DefaultQuery("max_ece", "0.1"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max_ece, expected a number"})
+		return
+	}
+
+	reports, err := h.projectUseCase.GetCalibration(c.Request.Context(), id, c.Query("model"), filter, bins, maxECE)
+	if err != nil {
+		writeProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"project_id": id,
+		"max_ece":    maxECE,
+		"models":     reports,
+	})
+}
+
+// CompareModels handles GET /api/v1/projects/:id/compare
+func (h *ProjectHandler) CompareModels(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	filter, err := parseImageFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	opts := usecase.CompareOptions{
+		A: usecase.ScoreSelector{Model: c.Query("a"), PromptVersion: c.Query("a_prompt_version")},
+		B: usecase.ScoreSelector{Model: c.Query("b"), PromptVersion: c.Query("b_prompt_version")},
+	}
+	if opts.Samples, err = strconv.Atoi(c.DefaultQuery("samples", "1000")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid samples, expected an integer"})
+		return
+	}
+	if opts.Confidence, err = strconv.ParseFloat(c.DefaultQuery("confidence", "0.95"), 64); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid confidence, expected a number"})
+		return
+	}
+	if opts.Seed, err = strconv.ParseInt(c.DefaultQuery("seed", "1"), 10, 64); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid seed, expected an integer"})
+		return
+	}
+
+	comparison, err := h.projectUseCase.CompareModels(c.Request.Context(), id, filter, opts)
+	if err != nil {
+		writeProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
+
+// confusionReports loads the confusion reports of the project in the request,
+// writing the error response when it fails
+func (h *ProjectHandler) confusionReports(c *gin.Context) (uuid.UUID, []*entity.ConfusionReport, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return id, nil, false
+	}
+
+	filter, err := parseImageFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return id, nil, false
+	}
+
+	reports, err := h.projectUseCase.GetConfusion(c.Request.Context(), id, c.Query("model"), filter)
+	if err != nil {
+		writeProjectError(c, err)
+		return id, nil, false
+	}
+	return id, reports, true
+}
+
+// parseImageFilter reads the tags, split, from and to query parameters.
+// Dates are accepted as RFC 3339 timestamps or YYYY-MM-DD; a date-only "to"
+// includes the whole day.
+func parseImageFilter(c *gin.Context) (repository.ImageFilter, error) {
+	return imageFilter(splitTags(c.Query("tags")), c.Query("split"), c.Query("from"), c.Query("to"))
+}
+
+// imageFilter builds a filter from its request fields, with the date
+// formats of parseImageFilter
+func imageFilter(tags []string, split, from, to string) (repository.ImageFilter, error) {
+	filter := repository.ImageFilter{Tags: tags, Split: split}
+
+	if from != "" {
+		t, _, err := parseFilterTime(from)
+		if err != nil {
+			return filter, errors.New("invalid from date, expected RFC 3339 or YYYY-MM-DD")
+		}
+		filter.From = &t
+	}
+	if to != "" {
+		t, dateOnly, err := parseFilterTime(to)
+		if err != nil {
+			return filter, errors.New("invalid to date, expected RFC 3339 or YYYY-MM-DD")
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		filter.To = &t
+	}
+	return filter, nil
+}
+
+func parseFilterTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+func writeProjectError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	case errors.Is(err, usecase.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
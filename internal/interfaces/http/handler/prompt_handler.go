@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/usecase"
+)
+
+// PromptHandler handles HTTP requests for prompt templates
+type PromptHandler struct {
+	promptUseCase usecase.PromptUseCase
+}
+
+// NewPromptHandler creates a new prompt handler
+func NewPromptHandler(promptUseCase usecase.PromptUseCase) *PromptHandler {
+	return &PromptHandler{
+		promptUseCase: promptUseCase,
+	}
+}
+
+// CreatePrompt handles requests to add a prompt version
+func (h *PromptHandler) CreatePrompt(c *gin.Context) {
+	var request struct {
+		Model       string `json:"model"`
+		Version     string `json:"version"`
+		Template    string `json:"template"`
+		Description string `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	prompt := &entity.PromptTemplate{
+		Model:       request.Model,
+		Version:     request.Version,
+		Template:    request.Template,
+		Description: request.Description,
+	}
+	if err := h.promptUseCase.CreatePrompt(c.Request.Context(), prompt); err != nil {
+		if errors.Is(err, usecase.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, prompt)
+}
+
+// GetAllPrompts handles requests to list prompt versions, optionally ?model=
+func (h *PromptHandler) GetAllPrompts(c *gin.Context) {
+	prompts, err := h.promptUseCase.GetAllPrompts(c.Request.Context(), c.Query("model"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, prompts)
+}
+
+// GetPromptByID handles requests to get a specific prompt version
+func (h *PromptHandler) GetPromptByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	prompt, err := h.promptUseCase.GetPromptByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Prompt not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prompt)
+}
+
+// ActivatePrompt handles requests to make a prompt version the model default
+func (h *PromptHandler) ActivatePrompt(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	prompt, err := h.promptUseCase.ActivatePrompt(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Prompt not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prompt)
+}
+
+// DeletePrompt handles requests to delete a prompt version
+func (h *PromptHandler) DeletePrompt(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := h.promptUseCase.DeletePrompt(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Prompt deleted successfully"})
+}
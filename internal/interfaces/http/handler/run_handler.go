@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/usecase"
+	"gorm.io/gorm"
+)
+
+// RunHandler handles HTTP requests for evaluation runs
+type RunHandler struct {
+	runUseCase usecase.RunUseCase
+}
+
+// NewRunHandler creates a new evaluation run handler
+func NewRunHandler(runUseCase usecase.RunUseCase) *RunHandler {
+	return &RunHandler{
+		runUseCase: runUseCase,
+	}
+}
+
+// runRequest is the body accepted when creating a run. Images are listed in
+// image_ids or selected by the filter fields.
+type runRequest struct {
+	ProjectID     *uuid.UUID     `json:"project_id"`
+	Name          string         `json:"name"`
+	Model         string         `json:"model"`
+	PromptVersion string         `json:"prompt_version"`
+	Parameters    map[string]any `json:"parameters"`
+	ImageIDs      []uuid.UUID    `json:"image_ids"`
+	Tags          []string       `json:"tags"`
+	Split         string         `json:"split"`
+	From          string         `json:"from"`
+	To            string         `json:"to"`
+}
+
+// CreateRun handles POST /api/v1/runs
+func (h *RunHandler) CreateRun(c *gin.Context) {
+	var request runRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	filter, err := imageFilter(request.Tags, request.Split, request.From, request.To)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	run, err := h.runUseCase.CreateRun(c.Request.Context(), usecase.RunRequest{
+		ProjectID:     request.ProjectID,
+		Name:          request.Name,
+		Model:         request.Model,
+		PromptVersion: request.PromptVersion,
+		Parameters:    request.Parameters,
+		Filter:        filter,
+		ImageIDs:      request.ImageIDs,
+	})
+	if err != nil {
+		writeRunError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, run)
+}
+
+// GetAllRuns handles GET /api/v1/runs, optionally filtered by project_id
+func (h *RunHandler) GetAllRuns(c *gin.Context) {
+	var projectID *uuid.UUID
+	if value := c.Query("project_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project_id format"})
+			return
+		}
+		projectID = &id
+	}
+
+	runs, err := h.runUseCase.GetAllRuns(c.Request.Context(), projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+// GetRunByID handles GET /api/v1/runs/:id
+func (h *RunHandler) GetRunByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	run, err := h.runUseCase.GetRun(c.Request.Context(), id)
+	if err != nil {
+		writeRunError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// GetRunResults handles GET /api/v1/runs/:id/results, optionally filtered by status
+func (h *RunHandler) GetRunResults(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	results, err := h.runUseCase.GetRunResults(c.Request.Context(), id, c.Query("status"))
+	if err != nil {
+		writeRunError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// DeleteRun handles DELETE /api/v1/runs/:id
+func (h *RunHandler) DeleteRun(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := h.runUseCase.DeleteRun(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Run deleted successfully"})
+}
+
+// CancelRun handles POST /api/v1/runs/:id/cancel
+func (h *RunHandler) CancelRun(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	run, err := h.runUseCase.CancelRun(c.Request.Context(), id)
+	if err != nil {
+		writeRunError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// CompareRuns handles GET /api/v1/runs/compare?a=&b=
+func (h *RunHandler) CompareRuns(c *gin.Context) {
+	a, err := uuid.Parse(c.Query("a"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run a, expected an ID"})
+		return
+	}
+	b, err := uuid.Parse(c.Query("b"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run b, expected an ID"})
+		return
+	}
+
+	samples, err := strconv.Atoi(c.DefaultQuery("samples", "1000"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid samples, expected an integer"})
+		return
+	}
+	confidence, err := strconv.ParseFloat(c.DefaultQuery("confidence", "0.95"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid confidence, expected a number"})
+		return
+	}
+	seed, err := strconv.ParseInt(c.DefaultQuery("seed", "1"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid seed, expected an integer"})
+		return
+	}
+
+	comparison, err := h.runUseCase.CompareRuns(c.Request.Context(), a, b, samples, confidence, seed)
+	if err != nil {
+		writeRunError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
+
+func writeRunError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Run or project not found"})
+	case errors.Is(err, usecase.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,469 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/label-platform-backend/internal/domain/entity"
+	"github.com/label-platform-backend/internal/domain/repository"
+	"github.com/label-platform-backend/internal/domain/usecase"
+	"gorm.io/gorm"
+)
+
+// TaskHandler handles HTTP requests for annotation tasks
+type TaskHandler struct {
+	taskUseCase usecase.TaskUseCase
+}
+
+// NewTaskHandler creates a new annotation task handler
+func NewTaskHandler(taskUseCase usecase.TaskUseCase) *TaskHandler {
+	return &TaskHandler{
+		taskUseCase: taskUseCase,
+	}
+}
+
+// assignRequest is the body accepted when creating the tasks of a project.
+// Images are listed in image_ids or selected by the filter fields.
+type assignRequest struct {
+	Annotators []string    `json:"annotators"`
+	PerImage   int         `json:"per_image"`
+	Overlap    float64     `json:"overlap"`
+	Gold       int         `json:"gold"`
+	Order      string      `json:"order"`
+	ImageIDs   []uuid.UUID `json:"image_ids"`
+	Tags       []string    `json:"tags"`
+	Split      string      `json:"split"`
+	From       string      `json:"from"`
+	To         string      `json:"to"`
+}
+
+// annotatorRequest identifies the annotator acting on a task
+type annotatorRequest struct {
+	Annotator  string         `json:"annotator"`
+	ProjectID  *uuid.UUID     `json:"project_id"`
+	Annotation map[string]any `json:"annotation"`
+}
+
+// reviewRequest is the body accepted when approving or rejecting a task
+type reviewRequest struct {
+	Reviewer   string         `json:"reviewer"`
+	Comment    string         `json:"comment"`
+	Annotation map[string]any `json:"annotation"`
+}
+
+// AssignTasks handles POST /api/v1/projects/:id/tasks
+func (h *TaskHandler) AssignTasks(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var request assignRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+	filter, err := imageFilter(request.Tags, request.Split, request.From, request.To)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := h.taskUseCase.AssignTasks(c.Request.Context(), id, usecase.AssignRequest{
+		Annotators: request.Annotators,
+		PerImage:   request.PerImage,
+		Overlap:    request.Overlap,
+		Gold:       request.Gold,
+		Order:      request.Order,
+		Filter:     filter,
+		ImageIDs:   request.ImageIDs,
+	})
+	if err != nil {
+		writeTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"project_id": id, "created": created})
+}
+
+// GetAgreement handles GET /api/v1/projects/:id/agreement
+func (h *TaskHandler) GetAgreement(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	minAgreement, err := strconv.ParseFloat(c.DefaultQuery("min_agreement", "0.7"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_agreement, expected a number"})
+		return
+	}
+
+	report, err := h.taskUseCase.GetAgreement(c.Request.Context(), id, minAgreement)
+	if err != nil {
+		writeTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetAnnotatorAccuracy handles GET /api/v1/admin/projects/:id/annotator-accuracy
+func (h *TaskHandler) GetAnnotatorAccuracy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	accuracy, err := h.taskUseCase.GetAnnotatorAccuracy(c.Request.Context(), id)
+	if err != nil {
+		writeTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"project_id": id,
+		"annotators": accuracy,
+	})
+}
+
+// GetAllTasks handles GET /api/v1/tasks, filtered by project_id, image_id,
+// assignee and status
+func (h *TaskHandler) GetAllTasks(c *gin.Context) {
+	var filter repository.TaskFilter
+	if value := c.Query("project_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project_id format"})
+			return
+		}
+		filter.ProjectID = &id
+	}
+	if value := c.Query("image_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image_id format"})
+			return
+		}
+		filter.ImageID = &id
+	}
+	if assignee, ok := c.GetQuery("assignee"); ok {
+		filter.Assignee = &assignee
+	}
+	filter.Status = c.Query("status")
+
+	tasks, err := h.taskUseCase.ListTasks(c.Request.Context(), filter)
+	if err != nil {
+		writeTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// GetTaskByID handles GET /api/v1/tasks/:id
+func (h *TaskHandler) GetTaskByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	task, err := h.taskUseCase.GetTask(c.Request.Context(), id)
+	if err != nil {
+		writeTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// NextTask handles POST /api/v1/tasks/next. It answers 204 when the
+// annotator has no task left.
+func (h *TaskHandler) NextTask(c *gin.Context) {
+	var request annotatorRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	task, err := h.taskUseCase.NextTask(c.Request.Context(), request.Annotator, request.ProjectID)
+	if err != nil {
+		writeTaskError(c, err)
+		return
+	}
+	if task == nil {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// AssignTask handles PUT /api/v1/tasks/:id/assign
+func (h *TaskHandler) AssignTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var request struct {
+		Assignee string `json:"assignee"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	task, err := h.taskUseCase.AssignTask(c.Request.Context(), id, request.Assignee)
+	if err != nil {
+		writeTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// ClaimTask handles POST /api/v1/tasks/:id/claim
+func (h *TaskHandler) ClaimTask(c *gin.Context) {
+	h.annotatorAction(c, h.taskUseCase.ClaimTask)
+}
+
+// ReleaseTask handles POST /api/v1/tasks/:id/release
+func (h *TaskHandler) ReleaseTask(c *gin.Context) {
+	h.annotatorAction(c, h.taskUseCase.ReleaseTask)
+}
+
+// SubmitTask handles POST /api/v1/tasks/:id/submit
+func (h *TaskHandler) SubmitTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var request annotatorRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	task, err := h.taskUseCase.SubmitTask(c.Request.Context(), id, request.Annotator, request.Annotation)
+	if err != nil {
+		writeTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// ProposeGroundTruth handles PUT /api/v1/images/:id/ground-truth. The
+// ground truth is submitted for review and only applied once approved.
+func (h *TaskHandler) ProposeGroundTruth(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var request struct {
+		GroundTruth map[string]any `json:"ground_truth"`
+		Annotator   string         `json:"annotator"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	task, err := h.taskUseCase.ProposeGroundTruth(c.Request.Context(), id, request.Annotator, request.GroundTruth)
+	if err != nil {
+		writeTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, task)
+}
+
+// ApproveTask handles POST /api/v1/tasks/:id/approve
+func (h *TaskHandler) ApproveTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var request reviewRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	task, err := h.taskUseCase.ApproveTask(c.Request.Context(), id, request.Reviewer, request.Comment, request.Annotation)
+	if err != nil {
+		writeTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// RejectTask handles POST /api/v1/tasks/:id/reject
+func (h *TaskHandler) RejectTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var request reviewRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	task, err := h.taskUseCase.RejectTask(c.Request.Context(), id, request.Reviewer, request.Comment)
+	if err != nil {
+		writeTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// GetTaskHistory handles GET /api/v1/tasks/:id/history
+func (h *TaskHandler) GetTaskHistory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	events, err := h.taskUseCase.GetTaskHistory(c.Request.Context(), id)
+	if err != nil {
+		writeTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// RecordSession handles POST /api/v1/tasks/:id/sessions
+func (h *TaskHandler) RecordSession(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var request struct {
+		Annotator string            `json:"annotator"`
+		Intervals []entity.Interval `json:"intervals"`
+		Created   int               `json:"created"`
+		Edited    int               `json:"edited"`
+		Deleted   int               `json:"deleted"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	session, err := h.taskUseCase.RecordSession(c.Request.Context(), id, usecase.SessionReport{
+		Annotator: request.Annotator,
+		Intervals: request.Intervals,
+		Created:   request.Created,
+		Edited:    request.Edited,
+		Deleted:   request.Deleted,
+	})
+	if err != nil {
+		writeTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, session)
+}
+
+// GetProductivity handles GET /api/v1/tasks/productivity, filtered by
+// project_id, annotator, from and to
+func (h *TaskHandler) GetProductivity(c *gin.Context) {
+	dates, err := imageFilter(nil, "", c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter := repository.ProductivityFilter{Annotator: c.Query("annotator"), From: dates.From, To: dates.To}
+	if value := c.Query("project_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project_id format"})
+			return
+		}
+		filter.ProjectID = &id
+	}
+
+	rows, err := h.taskUseCase.GetProductivity(c.Request.Context(), filter)
+	if err != nil {
+		writeTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rows)
+}
+
+// DeleteTask handles DELETE /api/v1/tasks/:id
+func (h *TaskHandler) DeleteTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := h.taskUseCase.DeleteTask(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
+}
+
+// annotatorAction runs a task action taking the annotator of the body
+func (h *TaskHandler) annotatorAction(c *gin.Context, action func(ctx context.Context, id uuid.UUID, annotator string) (*entity.AnnotationTask, error)) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var request annotatorRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	task, err := action(c.Request.Context(), id, request.Annotator)
+	if err != nil {
+		writeTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+func writeTaskError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task, image or project not found"})
+	case errors.Is(err, usecase.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
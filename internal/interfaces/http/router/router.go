@@ -1,13 +1,18 @@
 package router
 
 import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/label-platform-backend/internal/interfaces/http/handler"
 )
 
-// SetupRouter configures the HTTP router with all endpoints
-func SetupRouter(imageHandler *handler.ImageHandler) *gin.Engine {
+// SetupRouter configures the HTTP router with all endpoints. Admin routes
+// require adminToken as a bearer token and are disabled without one.
+func SetupRouter(imageHandler *handler.ImageHandler, projectHandler *handler.ProjectHandler, modelHandler *handler.ModelHandler, promptHandler *handler.PromptHandler, runHandler *handler.RunHandler, batchHandler *handler.BatchHandler, taskHandler *handler.TaskHandler, adminToken string) *gin.Engine {
 	router := gin.Default()
 
 	// Configure CORS
@@ -28,14 +33,130 @@ func SetupRouter(imageHandler *handler.ImageHandler) *gin.Engine {
 			images.GET("/:id", imageHandler.GetImageByID)
 			images.GET("/:id/url", imageHandler.GetImageURL)
 			images.PUT("/:id", imageHandler.UpdateImage)
-			images.PUT("/:id/ground-truth", imageHandler.UpdateGroundTruth)
+			images.PUT("/:id/ground-truth", taskHandler.ProposeGroundTruth)
+			images.PUT("/:id/metadata", imageHandler.UpdateImageMetadata)
+			images.POST("/:id/draft", imageHandler.DraftGroundTruth)
+			images.POST("/:id/evaluate", imageHandler.EvaluateImage)
+			images.POST("/:id/ensemble", imageHandler.CombinePredictions)
+			images.GET("/:id/compare", imageHandler.ComparePrediction)
+			images.GET("/:id/render", imageHandler.RenderImage)
 			images.DELETE("/:id", imageHandler.DeleteImage)
 			images.GET("/:id/predict", imageHandler.PredictImage)
 			images.GET("/:id/predict/model", imageHandler.GetPredictModels)
+			images.POST("/:id/predict/cancel", imageHandler.CancelPrediction)
+		}
+
+		// Project routes
+		projects := api.Group("/projects")
+		{
+			projects.POST("/", projectHandler.CreateProject)
+			projects.GET("/", projectHandler.GetAllProjects)
+			projects.GET("/:id", projectHandler.GetProjectByID)
+			projects.PUT("/:id", projectHandler.UpdateProject)
+			projects.DELETE("/:id", projectHandler.DeleteProject)
+			projects.GET("/:id/leaderboard", projectHandler.GetLeaderboard)
+			projects.GET("/:id/confusion", projectHandler.GetConfusion)
+			projects.GET("/:id/class-metrics", projectHandler.GetClassMetrics)
+			projects.GET("/:id/precision-recall", projectHandler.GetPrecisionRecall)
+			projects.GET("/:id/calibration", projectHandler.GetCalibration)
+			projects.GET("/:id/compare", projectHandler.CompareModels)
+			projects.POST("/:id/predict", batchHandler.CreateBatch)
+			projects.POST("/:id/prelabel", projectHandler.Prelabel)
+			projects.GET("/:id/uncertain", projectHandler.GetUncertainImages)
+			projects.POST("/:id/tasks", taskHandler.AssignTasks)
+			projects.GET("/:id/agreement", taskHandler.GetAgreement)
+		}
+
+		// Evaluation run routes
+		runs := api.Group("/runs")
+		{
+			runs.POST("/", runHandler.CreateRun)
+			runs.GET("/", runHandler.GetAllRuns)
+			runs.GET("/compare", runHandler.CompareRuns)
+			runs.GET("/:id", runHandler.GetRunByID)
+			runs.GET("/:id/results", runHandler.GetRunResults)
+			runs.POST("/:id/cancel", runHandler.CancelRun)
+			runs.DELETE("/:id", runHandler.DeleteRun)
+		}
+
+		// Prediction batch routes
+		batches := api.Group("/batches")
+		{
+			batches.GET("/", batchHandler.GetAllBatches)
+			batches.GET("/:id", batchHandler.GetBatchByID)
+			batches.POST("/:id/cancel", batchHandler.CancelBatch)
+			batches.POST("/:id/resume", batchHandler.ResumeBatch)
+		}
+
+		// Annotation task routes
+		tasks := api.Group("/tasks")
+		{
+			tasks.GET("/", taskHandler.GetAllTasks)
+			tasks.POST("/next", taskHandler.NextTask)
+			tasks.GET("/productivity", taskHandler.GetProductivity)
+			tasks.GET("/:id", taskHandler.GetTaskByID)
+			tasks.PUT("/:id/assign", taskHandler.AssignTask)
+			tasks.POST("/:id/claim", taskHandler.ClaimTask)
+			tasks.POST("/:id/release", taskHandler.ReleaseTask)
+			tasks.POST("/:id/submit", taskHandler.SubmitTask)
+			tasks.POST("/:id/approve", taskHandler.ApproveTask)
+			tasks.POST("/:id/reject", taskHandler.RejectTask)
+			tasks.GET("/:id/history", taskHandler.GetTaskHistory)
+			tasks.POST("/:id/sessions", taskHandler.RecordSession)
+			tasks.DELETE("/:id", taskHandler.DeleteTask)
+		}
+
+		// Registered model routes
+		models := api.Group("/models")
+		{
+			models.POST("/", modelHandler.CreateModel)
+			models.GET("/", modelHandler.GetAllModels)
+			models.GET("/:id", modelHandler.GetModelByID)
+			models.PUT("/:id", modelHandler.UpdateModel)
+			models.DELETE("/:id", modelHandler.DeleteModel)
+		}
+
+		// Prompt template routes
+		prompts := api.Group("/prompts")
+		{
+			prompts.POST("/", promptHandler.CreatePrompt)
+			prompts.GET("/", promptHandler.GetAllPrompts)
+			prompts.GET("/:id", promptHandler.GetPromptByID)
+			prompts.PUT("/:id/activate", promptHandler.ActivatePrompt)
+			prompts.DELETE("/:id", promptHandler.DeletePrompt)
+		}
+
+		// Admin routes, which tell gold images apart and must stay out of
+		// the annotators' reach
+		admin := api.Group("/admin", requireAdmin(adminToken))
+		{
+			admin.GET("/images/:id/gold", imageHandler.GetGold)
+			admin.PUT("/images/:id/gold", imageHandler.SetGold)
+			admin.GET("/projects/:id/annotator-accuracy", taskHandler.GetAnnotatorAccuracy)
 		}
 
 		api.POST("/predict/notify", imageHandler.PredictNotify)
+		api.GET("/predict/jobs", imageHandler.GetPredictJobs)
+		api.GET("/predict/jobs/stats", imageHandler.GetPredictJobStats)
+		api.GET("/predict/cache/stats", imageHandler.GetPredictCacheStats)
+		api.DELETE("/predict/cache", imageHandler.ClearPredictCache)
 	}
 
 	return router
 }
+
+// requireAdmin rejects requests without the admin token as bearer token
+func requireAdmin(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin routes are disabled, set ADMIN_TOKEN"})
+			return
+		}
+		given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid admin token"})
+			return
+		}
+		c.Next()
+	}
+}
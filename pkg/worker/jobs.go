@@ -0,0 +1,111 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Job priorities. Every priority but normal has its own list next to the
+// model queue and workers pop higher priorities first.
+const (
+	PriorityHigh   = "high"
+	PriorityNormal = "normal"
+	PriorityLow    = "low"
+)
+
+// Priorities lists the job priorities from highest to lowest
+var Priorities = []string{PriorityHigh, PriorityNormal, PriorityLow}
+
+// ValidPriority reports whether p is a known priority
+func ValidPriority(p string) bool {
+	for _, priority := range Priorities {
+		if p == priority {
+			return true
+		}
+	}
+	return false
+}
+
+// QueueName returns the list holding the jobs of a priority. Normal jobs
+// use the model queue itself.
+func QueueName(queue, priority string) string {
+	if priority == "" || priority == PriorityNormal {
+		return queue
+	}
+	return queue + ":" + priority
+}
+
+// Job statuses tracked in Redis while a job is active
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCancelled = "cancelled"
+	JobTimedOut  = "timed_out"
+	JobDone      = "done"
+)
+
+// ActiveJobsKey is the sorted set of queued and running job IDs, scored by
+// their enqueue time in milliseconds
+const ActiveJobsKey = "predict-jobs:active"
+
+// jobKeyPrefix is the prefix of the job status hashes
+const jobKeyPrefix = "predict-job:"
+
+// JobKey returns the key of the status hash of a job
+func JobKey(id string) string {
+	return jobKeyPrefix + id
+}
+
+// JobStore tracks the status of jobs so they can be cancelled
+type JobStore interface {
+	// Start marks a job running. It returns false when the job was cancelled
+	// or timed out.
+	Start(ctx context.Context, id string) (bool, error)
+	// Cancelled reports whether a job was cancelled or timed out
+	Cancelled(ctx context.Context, id string) (bool, error)
+}
+
+// startJob marks a job running unless it was cancelled or timed out. Jobs
+// without a status hash, e.g. expired ones, are started.
+var startJob = redis.NewScript(`
+local status = redis.call('HGET', KEYS[1], 'status')
+if status == 'cancelled' or status == 'timed_out' then return 0 end
+if status then redis.call('HSET', KEYS[1], 'status', 'running', 'started_at', ARGV[1]) end
+return 1
+`)
+
+// RedisJobStore reads the job statuses written by the backend
+type RedisJobStore struct {
+	client *redis.Client
+}
+
+// NewRedisJobStore creates a job store on the backend's Redis
+func NewRedisJobStore(client *redis.Client) *RedisJobStore {
+	return &RedisJobStore{client: client}
+}
+
+// Start marks a job running unless it was cancelled or timed out
+func (s *RedisJobStore) Start(ctx context.Context, id string) (bool, error) {
+	started, err := startJob.Run(ctx, s.client, []string{JobKey(id)}, strconv.FormatInt(time.Now().UnixMilli(), 10)).Int()
+	if err != nil {
+		return false, err
+	}
+	return started == 1, nil
+}
+
+// Cancelled reports whether a job was cancelled or timed out. A timed-out
+// job was given up on, so finishing it would be wasted.
+func (s *RedisJobStore) Cancelled(ctx context.Context, id string) (bool, error) {
+	status, err := s.client.HGet(ctx, JobKey(id), "status").Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return status == JobCancelled || status == JobTimedOut, nil
+}
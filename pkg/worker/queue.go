@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Queue is a source of raw job payloads
+type Queue interface {
+	// Pop blocks until a payload is available. It returns (nil, nil) when no
+	// job arrived within the queue's poll interval.
+	Pop(ctx context.Context) ([]byte, error)
+}
+
+// RedisQueue pops jobs from the Redis lists of a model queue, as pushed by
+// the backend with RPush, taking higher priorities first
+type RedisQueue struct {
+	client *redis.Client
+	names  []string
+	poll   time.Duration
+}
+
+// NewRedisQueue creates a queue reading from the priority lists of the
+// model queue name
+func NewRedisQueue(client *redis.Client, name string) *RedisQueue {
+	names := make([]string, len(Priorities))
+	for i, priority := range Priorities {
+		names[i] = QueueName(name, priority)
+	}
+	return &RedisQueue{client: client, names: names, poll: 5 * time.Second}
+}
+
+// Pop waits up to the poll interval for the next job
+func (q *RedisQueue) Pop(ctx context.Context) ([]byte, error) {
+	res, err := q.client.BLPop(ctx, q.poll, q.names...).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// res[0] is the list name, res[1] the payload
+	return []byte(res[1]), nil
+}
@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Report is the body posted to the backend's /predict/notify endpoint
+type Report struct {
+	ImageID       string `json:"image_id"`
+	JobID         string `json:"job_id,omitempty"`
+	Model         string `json:"model"`
+	PromptVersion string `json:"prompt_version,omitempty"`
+	RunID         string `json:"run_id,omitempty"`
+	Result        string `json:"result"`
+	Error         string `json:"error,omitempty"`
+}
+
+// Reporter delivers prediction reports to the backend
+type Reporter interface {
+	Report(ctx context.Context, report *Report) error
+}
+
+// HTTPReporter posts reports as JSON to the backend notify URL
+type HTTPReporter struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPReporter creates a reporter posting to url,
+// e.g. http://localhost:8080/api/v1/predict/notify
+func NewHTTPReporter(url string) *HTTPReporter {
+	return &HTTPReporter{
+		url:    url,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Report posts a single report, failing on any non-2xx response
+func (r *HTTPReporter) Report(ctx context.Context, report *Report) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("notify returned %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
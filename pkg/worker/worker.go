@@ -0,0 +1,324 @@
+// Package worker is the reference SDK for model workers. It pulls prediction
+// jobs from a model queue, decodes the screenshot, invokes a Predictor and
+// reports the result (or the failure) back to the backend.
+package worker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Job is the payload the backend pushes onto a model queue. Prompt and
+// PromptVersion are empty when the model has no stored prompt, in which case
+// the worker uses its own. RunID is set when the job belongs to an evaluation
+// run and must be reported back; Parameters are the run's model parameters,
+// e.g. temperature. JobID identifies the job in the job store.
+type Job struct {
+	ID            string         `json:"id"`
+	JobID         string         `json:"job_id,omitempty"`
+	Priority      string         `json:"priority,omitempty"`
+	ImageBase64   string         `json:"image_base64"`
+	Prompt        string         `json:"prompt,omitempty"`
+	PromptVersion string         `json:"prompt_version,omitempty"`
+	RunID         string         `json:"run_id,omitempty"`
+	Parameters    map[string]any `json:"parameters,omitempty"`
+}
+
+// Request is the input handed to a Predictor
+type Request struct {
+	ImageID       string
+	Image         []byte
+	Prompt        string
+	PromptVersion string
+	Parameters    map[string]any
+}
+
+// BBox is an element bounding box in pixels, x/y being the top-left corner
+type BBox struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Element is a single UI element predicted on a screenshot
+type Element struct {
+	Type       string  `json:"type"`
+	Text       string  `json:"text,omitempty"`
+	BBox       BBox    `json:"bbox"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Result is the output of a Predictor. When Elements is set it is reported as
+// {"elements": [...]}, otherwise Raw is reported verbatim. PromptVersion
+// overrides the job's version when the predictor used another prompt.
+type Result struct {
+	Raw           string
+	Elements      []Element
+	PromptVersion string
+}
+
+// Predictor runs a model on a single screenshot
+type Predictor interface {
+	Predict(ctx context.Context, req *Request) (*Result, error)
+}
+
+// PredictorFunc adapts a plain function to the Predictor interface
+type PredictorFunc func(ctx context.Context, req *Request) (*Result, error)
+
+// Predict calls f(ctx, req)
+func (f PredictorFunc) Predict(ctx context.Context, req *Request) (*Result, error) {
+	return f(ctx, req)
+}
+
+// Config holds the worker settings
+type Config struct {
+	// Model is the name reported back to the backend, e.g. "gpt"
+	Model string
+	// Concurrency is the number of jobs processed in parallel
+	Concurrency int
+	// PredictTimeout bounds a single Predict call
+	PredictTimeout time.Duration
+	// MaxRetries is how many times a failed predict or report is retried
+	MaxRetries int
+	// RetryBackoff is the initial delay between retries, doubled on each attempt
+	RetryBackoff time.Duration
+	// CancelPoll is how often a running job's cancellation is checked
+	CancelPoll time.Duration
+}
+
+// Worker consumes jobs from a Queue and reports results through a Reporter
+type Worker struct {
+	cfg       Config
+	queue     Queue
+	reporter  Reporter
+	predictor Predictor
+	jobs      JobStore
+}
+
+// New creates a new worker, filling in defaults for unset config values
+func New(cfg Config, queue Queue, reporter Reporter, predictor Predictor) *Worker {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.PredictTimeout <= 0 {
+		cfg.PredictTimeout = 2 * time.Minute
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = time.Second
+	}
+	if cfg.CancelPoll <= 0 {
+		cfg.CancelPoll = time.Second
+	}
+	return &Worker{
+		cfg:       cfg,
+		queue:     queue,
+		reporter:  reporter,
+		predictor: predictor,
+	}
+}
+
+// WithJobStore makes the worker honor job cancellation: cancelled jobs are
+// skipped, running ones are aborted, and neither is reported
+func (w *Worker) WithJobStore(jobs JobStore) *Worker {
+	w.jobs = jobs
+	return w
+}
+
+// Run processes jobs until ctx is cancelled
+func (w *Worker) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for i := 0; i < w.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		payload, err := w.queue.Pop(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("[worker:%s] failed to pop job: %v", w.cfg.Model, err)
+			sleep(ctx, w.cfg.RetryBackoff)
+			continue
+		}
+		if payload == nil {
+			continue
+		}
+		if err := w.Process(ctx, payload); err != nil {
+			log.Printf("[worker:%s] %v", w.cfg.Model, err)
+		}
+	}
+}
+
+// Process handles a single raw queue payload: predict, then report
+func (w *Worker) Process(ctx context.Context, payload []byte) error {
+	var job Job
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return fmt.Errorf("invalid job payload: %w", err)
+	}
+	if job.ID == "" {
+		return errors.New("invalid job payload: missing id")
+	}
+
+	report := &Report{ImageID: job.ID, JobID: job.JobID, Model: w.cfg.Model, PromptVersion: job.PromptVersion, RunID: job.RunID}
+
+	if w.jobs != nil && job.JobID != "" {
+		started, err := w.jobs.Start(ctx, job.JobID)
+		if err != nil {
+			log.Printf("[worker:%s] failed to start job %s: %v", w.cfg.Model, job.JobID, err)
+		} else if !started {
+			log.Printf("[worker:%s] skipping cancelled job %s", w.cfg.Model, job.JobID)
+			return nil
+		}
+	}
+
+	predictCtx, cancelled := w.watchCancellation(ctx, job.JobID)
+	result, err := w.predict(predictCtx, &job)
+	if cancelled() {
+		log.Printf("[worker:%s] job %s was cancelled", w.cfg.Model, job.JobID)
+		return nil
+	}
+	if err != nil {
+		report.Error = err.Error()
+	} else {
+		if result.PromptVersion != "" {
+			report.PromptVersion = result.PromptVersion
+		}
+		report.Result, err = result.Encode()
+		if err != nil {
+			report.Error = err.Error()
+		}
+	}
+
+	err = w.retry(ctx, func() error {
+		return w.reporter.Report(ctx, report)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to report job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// watchCancellation returns a context cancelled when the job is cancelled
+// in the job store, and a function stopping the watch that reports whether
+// the job was cancelled
+func (w *Worker) watchCancellation(ctx context.Context, jobID string) (context.Context, func() bool) {
+	if w.jobs == nil || jobID == "" {
+		return ctx, func() bool { return false }
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	var cancelled atomic.Bool
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(w.cfg.CancelPoll)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-watchCtx.Done():
+				return
+			case <-ticker.C:
+				if c, err := w.jobs.Cancelled(watchCtx, jobID); err == nil && c {
+					cancelled.Store(true)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	return watchCtx, func() bool {
+		cancel()
+		<-done
+		return cancelled.Load()
+	}
+}
+
+func (w *Worker) predict(ctx context.Context, job *Job) (*Result, error) {
+	image, err := base64.StdEncoding.DecodeString(job.ImageBase64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	req := &Request{
+		ImageID:       job.ID,
+		Image:         image,
+		Prompt:        job.Prompt,
+		PromptVersion: job.PromptVersion,
+		Parameters:    job.Parameters,
+	}
+
+	var result *Result
+	err = w.retry(ctx, func() error {
+		predictCtx, cancel := context.WithTimeout(ctx, w.cfg.PredictTimeout)
+		defer cancel()
+		result, err = w.predictor.Predict(predictCtx, req)
+		if err == nil && result == nil {
+			err = errors.New("predictor returned no result")
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("predict failed: %w", err)
+	}
+	return result, nil
+}
+
+// retry runs fn up to MaxRetries+1 times with exponential backoff
+func (w *Worker) retry(ctx context.Context, fn func() error) error {
+	backoff := w.cfg.RetryBackoff
+	var err error
+	for attempt := 0; attempt <= w.cfg.MaxRetries; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt == w.cfg.MaxRetries || !sleep(ctx, backoff) {
+			break
+		}
+		backoff *= 2
+	}
+	return err
+}
+
+// Encode renders the result as the string reported to the backend
+func (r *Result) Encode() (string, error) {
+	if r.Elements == nil {
+		return r.Raw, nil
+	}
+	data, err := json.Marshal(map[string]any{"elements": r.Elements})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal elements: %w", err)
+	}
+	return string(data), nil
+}
+
+// sleep waits for d or until ctx is done, returning false in the latter case
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
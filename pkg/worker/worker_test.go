@@ -0,0 +1,155 @@
+package worker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func jobPayload(t *testing.T, id string, image []byte) []byte {
+	payload, err := json.Marshal(Job{ID: id, ImageBase64: base64.StdEncoding.EncodeToString(image), Prompt: "Label it.", PromptVersion: "v2"})
+	require.NoError(t, err)
+	return payload
+}
+
+func TestWorker_ProcessReportsElements(t *testing.T) {
+	var got Report
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer srv.Close()
+
+	predictor := PredictorFunc(func(ctx context.Context, req *Request) (*Result, error) {
+		assert.Equal(t, "img-1", req.ImageID)
+		assert.Equal(t, []byte("png-bytes"), req.Image)
+		assert.Equal(t, "Label it.", req.Prompt)
+		return &Result{Elements: []Element{{Type: "button", Text: "Submit", BBox: BBox{X: 1, Y: 2, Width: 3, Height: 4}, Confidence: 0.9}}}, nil
+	})
+	w := New(Config{Model: "mock"}, nil, NewHTTPReporter(srv.URL), predictor)
+
+	require.NoError(t, w.Process(context.Background(), jobPayload(t, "img-1", []byte("png-bytes"))))
+	assert.Equal(t, "img-1", got.ImageID)
+	assert.Equal(t, "mock", got.Model)
+	assert.Equal(t, "v2", got.PromptVersion)
+	assert.Empty(t, got.Error)
+	assert.JSONEq(t, `{"elements":[{"type":"button","text":"Submit","bbox":{"x":1,"y":2,"width":3,"height":4},"confidence":0.9}]}`, got.Result)
+}
+
+func TestWorker_ProcessRetriesAndReportsFailure(t *testing.T) {
+	var calls, posts int32
+	var got Report
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first report attempt fails, the retry succeeds
+		if atomic.AddInt32(&posts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer srv.Close()
+
+	predictor := PredictorFunc(func(ctx context.Context, req *Request) (*Result, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, errors.New("model unavailable")
+	})
+	w := New(Config{Model: "mock", MaxRetries: 2, RetryBackoff: time.Millisecond}, nil, NewHTTPReporter(srv.URL), predictor)
+
+	require.NoError(t, w.Process(context.Background(), jobPayload(t, "img-2", []byte("x"))))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&posts))
+	assert.Equal(t, "img-2", got.ImageID)
+	assert.Contains(t, got.Error, "model unavailable")
+}
+
+func TestWorker_ProcessRejectsInvalidPayload(t *testing.T) {
+	w := New(Config{Model: "mock"}, nil, nil, nil)
+	assert.Error(t, w.Process(context.Background(), []byte("not json")))
+	assert.Error(t, w.Process(context.Background(), []byte(`{"image_base64":""}`)))
+}
+
+func TestWorker_ProcessPropagatesRun(t *testing.T) {
+	var got Report
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer srv.Close()
+
+	payload, err := json.Marshal(Job{ID: "img-3", RunID: "run-1", Parameters: map[string]any{"temperature": 0.2}})
+	require.NoError(t, err)
+
+	predictor := PredictorFunc(func(ctx context.Context, req *Request) (*Result, error) {
+		assert.Equal(t, map[string]any{"temperature": 0.2}, req.Parameters)
+		return &Result{Raw: "{}"}, nil
+	})
+	w := New(Config{Model: "mock"}, nil, NewHTTPReporter(srv.URL), predictor)
+
+	require.NoError(t, w.Process(context.Background(), payload))
+	assert.Equal(t, "run-1", got.RunID)
+}
+
+// fakeJobStore cancels the jobs in cancelled
+type fakeJobStore struct {
+	cancelled sync.Map
+}
+
+func (s *fakeJobStore) Start(ctx context.Context, id string) (bool, error) {
+	_, cancelled := s.cancelled.Load(id)
+	return !cancelled, nil
+}
+
+func (s *fakeJobStore) Cancelled(ctx context.Context, id string) (bool, error) {
+	_, cancelled := s.cancelled.Load(id)
+	return cancelled, nil
+}
+
+func TestWorker_ProcessSkipsCancelledJob(t *testing.T) {
+	var posts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&posts, 1)
+	}))
+	defer srv.Close()
+
+	jobs := &fakeJobStore{}
+	jobs.cancelled.Store("job-1", true)
+	predictor := PredictorFunc(func(ctx context.Context, req *Request) (*Result, error) {
+		t.Fatal("cancelled job was predicted")
+		return nil, nil
+	})
+	w := New(Config{Model: "mock"}, nil, NewHTTPReporter(srv.URL), predictor).WithJobStore(jobs)
+
+	payload, err := json.Marshal(Job{ID: "img-4", JobID: "job-1"})
+	require.NoError(t, err)
+	require.NoError(t, w.Process(context.Background(), payload))
+	assert.Zero(t, atomic.LoadInt32(&posts))
+}
+
+func TestWorker_ProcessAbortsJobCancelledWhileRunning(t *testing.T) {
+	var posts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&posts, 1)
+	}))
+	defer srv.Close()
+
+	jobs := &fakeJobStore{}
+	predictor := PredictorFunc(func(ctx context.Context, req *Request) (*Result, error) {
+		jobs.cancelled.Store("job-2", true)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	w := New(Config{Model: "mock", CancelPoll: time.Millisecond}, nil, NewHTTPReporter(srv.URL), predictor).WithJobStore(jobs)
+
+	payload, err := json.Marshal(Job{ID: "img-5", JobID: "job-2"})
+	require.NoError(t, err)
+	require.NoError(t, w.Process(context.Background(), payload))
+	assert.Zero(t, atomic.LoadInt32(&posts))
+}